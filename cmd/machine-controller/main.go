@@ -22,10 +22,12 @@ Modifications Copyright (c) 2017 SAP SE or an SAP affiliate company. All rights
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/tracing"
 	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
 
 	_ "github.com/gardener/machine-controller-manager/pkg/util/client/metrics/prometheus" // for client metric registration
@@ -43,11 +45,39 @@ func main() {
 	s := options.NewMCServer()
 	s.AddFlags(pflag.CommandLine)
 
+	pluginSPI := &spi.PluginSPIImpl{}
+	pluginSPI.ClientOptions.AddFlags(pflag.CommandLine)
+
+	retryBackoff := spi.DefaultRetryBackoff
+	pflag.CommandLine.IntVar(&retryBackoff.Steps, "ecs-retry-attempts", retryBackoff.Steps, "Maximum number of attempts of idempotent ECS calls failing with throttling or server errors, 1 disables retrying")
+	pflag.CommandLine.DurationVar(&retryBackoff.Duration, "ecs-retry-initial-backoff", retryBackoff.Duration, "Initial delay before retrying a failed ECS call, doubled with every further attempt")
+	pflag.CommandLine.DurationVar(&retryBackoff.Cap, "ecs-retry-max-backoff", retryBackoff.Cap, "Maximum delay between two attempts of a failed ECS call")
+
+	rateLimits := spi.DefaultRateLimits
+	rateLimits.AddFlags(pflag.CommandLine)
+
+	tracingOptions := tracing.DefaultOptions
+	tracingOptions.AddFlags(pflag.CommandLine)
+
 	flag.InitFlags()
 	logs.InitLogs()
 	defer logs.FlushLogs()
 
-	plugin := alicloud.NewAlicloudPlugin(&spi.PluginSPIImpl{})
+	if err := pluginSPI.ClientOptions.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingOptions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	defer func() { _ = shutdownTracing(context.Background()) }()
+
+	plugin := alicloud.NewAlicloudPlugin(pluginSPI)
+	plugin.RetryBackoff = retryBackoff
+	plugin.RateLimiters = spi.NewRateLimiters(rateLimits)
 
 	if err := app.Run(s, plugin); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
@@ -7,28 +7,49 @@ package api
 const (
 	// V1alpha1 is the constant for API version of machine controller manager
 	V1alpha1 = "mcm.gardener.cloud/v1alpha1"
+
+	// NetworkInterfaceTypePrimary is the type of the primary network interface of an instance
+	NetworkInterfaceTypePrimary = "Primary"
+	// NetworkInterfaceTypeSecondary is the type of additional network interfaces of an instance
+	NetworkInterfaceTypeSecondary = "Secondary"
+
+	// SpotStrategyNoSpot is the spot strategy of pay-as-you-go instances which are no spot instances
+	SpotStrategyNoSpot = "NoSpot"
+	// SpotStrategyWithPriceLimit is the spot strategy of spot instances with a maximum hourly price
+	SpotStrategyWithPriceLimit = "SpotWithPriceLimit"
+	// SpotStrategyAsPriceGo is the spot strategy of spot instances bidding the market price
+	SpotStrategyAsPriceGo = "SpotAsPriceGo"
 )
 
-// ProviderSpec is the spec to be used while parsing the calls.
+// ProviderSpec is the spec to be used while parsing the calls. InstanceTypes optionally lists the instance types in the
+// order they are tried if ECS has no capacity for the previous one, its first entry is the InstanceType.
 type ProviderSpec struct {
-	APIVersion              string              `json:"apiVersion,omitempty"`
-	ImageID                 string              `json:"imageID"`
-	InstanceType            string              `json:"instanceType"`
-	Region                  string              `json:"region"`
-	ZoneID                  string              `json:"zoneID,omitempty"`
-	SecurityGroupID         string              `json:"securityGroupID,omitempty"`
-	VSwitchID               string              `json:"vSwitchID"`
-	PrivateIPAddress        string              `json:"privateIPAddress,omitempty"`
-	SystemDisk              *AlicloudSystemDisk `json:"systemDisk,omitempty"`
-	DataDisks               []AlicloudDataDisk  `json:"dataDisks,omitempty"`
-	InstanceChargeType      string              `json:"instanceChargeType,omitempty"`
-	InternetChargeType      string              `json:"internetChargeType,omitempty"`
-	InternetMaxBandwidthIn  *int                `json:"internetMaxBandwidthIn,omitempty"`
-	InternetMaxBandwidthOut *int                `json:"internetMaxBandwidthOut,omitempty"`
-	SpotStrategy            string              `json:"spotStrategy,omitempty"`
-	IoOptimized             string              `json:"IoOptimized,omitempty"`
-	Tags                    map[string]string   `json:"tags,omitempty"`
-	KeyPairName             string              `json:"keyPairName"`
+	APIVersion               string                     `json:"apiVersion,omitempty"`
+	ImageID                  string                     `json:"imageID"`
+	InstanceType             string                     `json:"instanceType"`
+	InstanceTypes            []string                   `json:"instanceTypes,omitempty"`
+	Region                   string                     `json:"region"`
+	ZoneID                   string                     `json:"zoneID,omitempty"`
+	SecurityGroupID          string                     `json:"securityGroupID,omitempty"`
+	VSwitchID                string                     `json:"vSwitchID"`
+	PrivateIPAddress         string                     `json:"privateIPAddress,omitempty"`
+	IPv6AddressCount         *int                       `json:"ipv6AddressCount,omitempty"`
+	IPv6Addresses            []string                   `json:"ipv6Addresses,omitempty"`
+	SystemDisk               *AlicloudSystemDisk        `json:"systemDisk,omitempty"`
+	DataDisks                []AlicloudDataDisk         `json:"dataDisks,omitempty"`
+	NetworkInterfaces        []AlicloudNetworkInterface `json:"networkInterfaces,omitempty"`
+	InstanceChargeType       string                     `json:"instanceChargeType,omitempty"`
+	InternetChargeType       string                     `json:"internetChargeType,omitempty"`
+	InternetMaxBandwidthIn   *int                       `json:"internetMaxBandwidthIn,omitempty"`
+	InternetMaxBandwidthOut  *int                       `json:"internetMaxBandwidthOut,omitempty"`
+	SpotStrategy             string                     `json:"spotStrategy,omitempty"`
+	SpotPriceLimit           *float64                   `json:"spotPriceLimit,omitempty"`
+	SpotDuration             *int                       `json:"spotDuration,omitempty"`
+	SpotInterruptionBehavior string                     `json:"spotInterruptionBehavior,omitempty"`
+	SpotFallback             *AlicloudSpotFallback      `json:"spotFallback,omitempty"`
+	IoOptimized              string                     `json:"IoOptimized,omitempty"`
+	Tags                     map[string]string          `json:"tags,omitempty"`
+	KeyPairName              string                     `json:"keyPairName"`
 }
 
 // AlicloudDataDisk describes DataDisk for Alicloud.
@@ -41,6 +62,29 @@ type AlicloudDataDisk struct {
 	Size               int    `json:"size,omitempty"`
 }
 
+// AlicloudNetworkInterface describes a network interface of an instance for Alicloud. The ECS network interface is named
+// after the machine and the name, an entry of type Primary configures the primary network interface instead of the
+// vSwitchID, securityGroupID, privateIPAddress and IPv6 addresses of the ProviderSpec. QueueNumber defaults to the one
+// of the instance type. IPv6 addresses are either assigned explicitly or as many as IPv6AddressCount are chosen from
+// the IPv6 CIDR block of the vSwitch.
+type AlicloudNetworkInterface struct {
+	Name             string   `json:"name"`
+	Type             string   `json:"type,omitempty"`
+	VSwitchID        string   `json:"vSwitchID"`
+	SecurityGroupIDs []string `json:"securityGroupIDs,omitempty"`
+	Description      string   `json:"description,omitempty"`
+	QueueNumber      *int     `json:"queueNumber,omitempty"`
+	IPv6AddressCount *int     `json:"ipv6AddressCount,omitempty"`
+	IPv6Addresses    []string `json:"ipv6Addresses,omitempty"`
+}
+
+// AlicloudSpotFallback describes the fallback to pay-as-you-go instances if ECS has no spot instances of the instance
+// type in stock. MaxPercentage limits the share of the instances of the MachineClass which may fall back, all of them
+// may fall back if it is not set. Instances which fell back are tagged with the spot strategy they were requested with.
+type AlicloudSpotFallback struct {
+	MaxPercentage *int `json:"maxPercentage,omitempty"`
+}
+
 // AlicloudSystemDisk describes SystemDisk for Alicloud.
 type AlicloudSystemDisk struct {
 	Category string `json:"category"`
@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package install registers all versions of the Alicloud ProviderSpec API into a scheme.
+package install
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis/v1alpha1"
+)
+
+var (
+	schemeBuilder = runtime.NewSchemeBuilder(
+		v1alpha1.AddToScheme,
+	)
+
+	// AddToScheme adds all versions of the ProviderSpec API to the given scheme.
+	AddToScheme = schemeBuilder.AddToScheme
+)
+
+// Install installs all versions of the ProviderSpec API into the given scheme.
+func Install(scheme *runtime.Scheme) {
+	utilruntime.Must(AddToScheme(scheme))
+}
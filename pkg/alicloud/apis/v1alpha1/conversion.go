@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	api "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis"
+)
+
+// RegisterConversions adds conversion functions to the given scheme.
+func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddConversionFunc((*ProviderSpec)(nil), (*api.ProviderSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ProviderSpec_To_api_ProviderSpec(a.(*ProviderSpec), b.(*api.ProviderSpec), scope)
+	}); err != nil {
+		return err
+	}
+	return s.AddConversionFunc((*api.ProviderSpec)(nil), (*ProviderSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_api_ProviderSpec_To_v1alpha1_ProviderSpec(a.(*api.ProviderSpec), b.(*ProviderSpec), scope)
+	})
+}
+
+// Convert_v1alpha1_ProviderSpec_To_api_ProviderSpec converts the versioned ProviderSpec into the internal one.
+func Convert_v1alpha1_ProviderSpec_To_api_ProviderSpec(in *ProviderSpec, out *api.ProviderSpec, _ conversion.Scope) error {
+	out.APIVersion = SchemeGroupVersion.String()
+	out.ImageID = in.ImageID
+	out.InstanceType = in.InstanceType
+	out.InstanceTypes = in.InstanceTypes
+	out.Region = in.Region
+	out.ZoneID = in.ZoneID
+	out.SecurityGroupID = in.SecurityGroupID
+	out.VSwitchID = in.VSwitchID
+	out.PrivateIPAddress = in.PrivateIPAddress
+	out.IPv6AddressCount = in.IPv6AddressCount
+	out.IPv6Addresses = in.IPv6Addresses
+	if in.SystemDisk != nil {
+		out.SystemDisk = &api.AlicloudSystemDisk{
+			Category: in.SystemDisk.Category,
+			Size:     in.SystemDisk.Size,
+		}
+	} else {
+		out.SystemDisk = nil
+	}
+	if in.DataDisks != nil {
+		out.DataDisks = make([]api.AlicloudDataDisk, len(in.DataDisks))
+		for i, disk := range in.DataDisks {
+			out.DataDisks[i] = api.AlicloudDataDisk{
+				Name:               disk.Name,
+				Category:           disk.Category,
+				Description:        disk.Description,
+				Encrypted:          disk.Encrypted,
+				DeleteWithInstance: disk.DeleteWithInstance,
+				Size:               disk.Size,
+			}
+		}
+	} else {
+		out.DataDisks = nil
+	}
+	if in.NetworkInterfaces != nil {
+		out.NetworkInterfaces = make([]api.AlicloudNetworkInterface, len(in.NetworkInterfaces))
+		for i, networkInterface := range in.NetworkInterfaces {
+			out.NetworkInterfaces[i] = api.AlicloudNetworkInterface{
+				Name:             networkInterface.Name,
+				Type:             networkInterface.Type,
+				VSwitchID:        networkInterface.VSwitchID,
+				SecurityGroupIDs: networkInterface.SecurityGroupIDs,
+				Description:      networkInterface.Description,
+				QueueNumber:      networkInterface.QueueNumber,
+				IPv6AddressCount: networkInterface.IPv6AddressCount,
+				IPv6Addresses:    networkInterface.IPv6Addresses,
+			}
+		}
+	} else {
+		out.NetworkInterfaces = nil
+	}
+	out.InstanceChargeType = in.InstanceChargeType
+	out.InternetChargeType = in.InternetChargeType
+	out.InternetMaxBandwidthIn = in.InternetMaxBandwidthIn
+	out.InternetMaxBandwidthOut = in.InternetMaxBandwidthOut
+	out.SpotStrategy = in.SpotStrategy
+	out.SpotPriceLimit = in.SpotPriceLimit
+	out.SpotDuration = in.SpotDuration
+	out.SpotInterruptionBehavior = in.SpotInterruptionBehavior
+	if in.SpotFallback != nil {
+		out.SpotFallback = &api.AlicloudSpotFallback{
+			MaxPercentage: in.SpotFallback.MaxPercentage,
+		}
+	} else {
+		out.SpotFallback = nil
+	}
+	out.IoOptimized = in.IoOptimized
+	out.Tags = in.Tags
+	out.KeyPairName = in.KeyPairName
+	return nil
+}
+
+// Convert_api_ProviderSpec_To_v1alpha1_ProviderSpec converts the internal ProviderSpec into the versioned one.
+func Convert_api_ProviderSpec_To_v1alpha1_ProviderSpec(in *api.ProviderSpec, out *ProviderSpec, _ conversion.Scope) error {
+	out.APIVersion = SchemeGroupVersion.String()
+	out.Kind = "ProviderSpec"
+	out.ImageID = in.ImageID
+	out.InstanceType = in.InstanceType
+	out.InstanceTypes = in.InstanceTypes
+	out.Region = in.Region
+	out.ZoneID = in.ZoneID
+	out.SecurityGroupID = in.SecurityGroupID
+	out.VSwitchID = in.VSwitchID
+	out.PrivateIPAddress = in.PrivateIPAddress
+	out.IPv6AddressCount = in.IPv6AddressCount
+	out.IPv6Addresses = in.IPv6Addresses
+	if in.SystemDisk != nil {
+		out.SystemDisk = &AlicloudSystemDisk{
+			Category: in.SystemDisk.Category,
+			Size:     in.SystemDisk.Size,
+		}
+	} else {
+		out.SystemDisk = nil
+	}
+	if in.DataDisks != nil {
+		out.DataDisks = make([]AlicloudDataDisk, len(in.DataDisks))
+		for i, disk := range in.DataDisks {
+			out.DataDisks[i] = AlicloudDataDisk{
+				Name:               disk.Name,
+				Category:           disk.Category,
+				Description:        disk.Description,
+				Encrypted:          disk.Encrypted,
+				DeleteWithInstance: disk.DeleteWithInstance,
+				Size:               disk.Size,
+			}
+		}
+	} else {
+		out.DataDisks = nil
+	}
+	if in.NetworkInterfaces != nil {
+		out.NetworkInterfaces = make([]AlicloudNetworkInterface, len(in.NetworkInterfaces))
+		for i, networkInterface := range in.NetworkInterfaces {
+			out.NetworkInterfaces[i] = AlicloudNetworkInterface{
+				Name:             networkInterface.Name,
+				Type:             networkInterface.Type,
+				VSwitchID:        networkInterface.VSwitchID,
+				SecurityGroupIDs: networkInterface.SecurityGroupIDs,
+				Description:      networkInterface.Description,
+				QueueNumber:      networkInterface.QueueNumber,
+				IPv6AddressCount: networkInterface.IPv6AddressCount,
+				IPv6Addresses:    networkInterface.IPv6Addresses,
+			}
+		}
+	} else {
+		out.NetworkInterfaces = nil
+	}
+	out.InstanceChargeType = in.InstanceChargeType
+	out.InternetChargeType = in.InternetChargeType
+	out.InternetMaxBandwidthIn = in.InternetMaxBandwidthIn
+	out.InternetMaxBandwidthOut = in.InternetMaxBandwidthOut
+	out.SpotStrategy = in.SpotStrategy
+	out.SpotPriceLimit = in.SpotPriceLimit
+	out.SpotDuration = in.SpotDuration
+	out.SpotInterruptionBehavior = in.SpotInterruptionBehavior
+	if in.SpotFallback != nil {
+		out.SpotFallback = &AlicloudSpotFallback{
+			MaxPercentage: in.SpotFallback.MaxPercentage,
+		}
+	} else {
+		out.SpotFallback = nil
+	}
+	out.IoOptimized = in.IoOptimized
+	out.Tags = in.Tags
+	out.KeyPairName = in.KeyPairName
+	return nil
+}
@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// The deepcopy functions are maintained by hand instead of by deepcopy-gen, fields added to the types must be copied
+// here as well.
+
+package v1alpha1
+
+import (
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto copies the receiver, writing into out. in must be non-nil.
+func (in *AlicloudDataDisk) DeepCopyInto(out *AlicloudDataDisk) {
+	*out = *in
+	if in.DeleteWithInstance != nil {
+		in, out := &in.DeleteWithInstance, &out.DeleteWithInstance
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy copies the receiver, creating a new AlicloudDataDisk.
+func (in *AlicloudDataDisk) DeepCopy() *AlicloudDataDisk {
+	if in == nil {
+		return nil
+	}
+	out := new(AlicloudDataDisk)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the receiver, writing into out. in must be non-nil.
+func (in *AlicloudNetworkInterface) DeepCopyInto(out *AlicloudNetworkInterface) {
+	*out = *in
+	if in.SecurityGroupIDs != nil {
+		in, out := &in.SecurityGroupIDs, &out.SecurityGroupIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.QueueNumber != nil {
+		in, out := &in.QueueNumber, &out.QueueNumber
+		*out = new(int)
+		**out = **in
+	}
+	if in.IPv6AddressCount != nil {
+		in, out := &in.IPv6AddressCount, &out.IPv6AddressCount
+		*out = new(int)
+		**out = **in
+	}
+	if in.IPv6Addresses != nil {
+		in, out := &in.IPv6Addresses, &out.IPv6Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy copies the receiver, creating a new AlicloudNetworkInterface.
+func (in *AlicloudNetworkInterface) DeepCopy() *AlicloudNetworkInterface {
+	if in == nil {
+		return nil
+	}
+	out := new(AlicloudNetworkInterface)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the receiver, writing into out. in must be non-nil.
+func (in *AlicloudSpotFallback) DeepCopyInto(out *AlicloudSpotFallback) {
+	*out = *in
+	if in.MaxPercentage != nil {
+		in, out := &in.MaxPercentage, &out.MaxPercentage
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy copies the receiver, creating a new AlicloudSpotFallback.
+func (in *AlicloudSpotFallback) DeepCopy() *AlicloudSpotFallback {
+	if in == nil {
+		return nil
+	}
+	out := new(AlicloudSpotFallback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the receiver, writing into out. in must be non-nil.
+func (in *AlicloudSystemDisk) DeepCopyInto(out *AlicloudSystemDisk) {
+	*out = *in
+	return
+}
+
+// DeepCopy copies the receiver, creating a new AlicloudSystemDisk.
+func (in *AlicloudSystemDisk) DeepCopy() *AlicloudSystemDisk {
+	if in == nil {
+		return nil
+	}
+	out := new(AlicloudSystemDisk)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the receiver, writing into out. in must be non-nil.
+func (in *ProviderSpec) DeepCopyInto(out *ProviderSpec) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.InstanceTypes != nil {
+		in, out := &in.InstanceTypes, &out.InstanceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPv6AddressCount != nil {
+		in, out := &in.IPv6AddressCount, &out.IPv6AddressCount
+		*out = new(int)
+		**out = **in
+	}
+	if in.IPv6Addresses != nil {
+		in, out := &in.IPv6Addresses, &out.IPv6Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SystemDisk != nil {
+		in, out := &in.SystemDisk, &out.SystemDisk
+		*out = new(AlicloudSystemDisk)
+		**out = **in
+	}
+	if in.DataDisks != nil {
+		in, out := &in.DataDisks, &out.DataDisks
+		*out = make([]AlicloudDataDisk, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NetworkInterfaces != nil {
+		in, out := &in.NetworkInterfaces, &out.NetworkInterfaces
+		*out = make([]AlicloudNetworkInterface, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InternetMaxBandwidthIn != nil {
+		in, out := &in.InternetMaxBandwidthIn, &out.InternetMaxBandwidthIn
+		*out = new(int)
+		**out = **in
+	}
+	if in.InternetMaxBandwidthOut != nil {
+		in, out := &in.InternetMaxBandwidthOut, &out.InternetMaxBandwidthOut
+		*out = new(int)
+		**out = **in
+	}
+	if in.SpotPriceLimit != nil {
+		in, out := &in.SpotPriceLimit, &out.SpotPriceLimit
+		*out = new(float64)
+		**out = **in
+	}
+	if in.SpotDuration != nil {
+		in, out := &in.SpotDuration, &out.SpotDuration
+		*out = new(int)
+		**out = **in
+	}
+	if in.SpotFallback != nil {
+		in, out := &in.SpotFallback, &out.SpotFallback
+		*out = new(AlicloudSpotFallback)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy copies the receiver, creating a new ProviderSpec.
+func (in *ProviderSpec) DeepCopy() *ProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject copies the receiver, creating a new runtime.Object.
+func (in *ProviderSpec) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// DefaultInstanceChargeType is the billing method used if none is configured
+	DefaultInstanceChargeType = "PostPaid"
+	// DefaultInternetChargeType is the billing method for public bandwidth used if none is configured
+	DefaultInternetChargeType = "PayByTraffic"
+	// DefaultSpotStrategy is the spot strategy used if none is configured
+	DefaultSpotStrategy = "NoSpot"
+	// DefaultIoOptimized is the I/O optimization setting used if none is configured
+	DefaultIoOptimized = "optimized"
+	// DefaultDiskCategory is the category of system and data disks used if none is configured
+	DefaultDiskCategory = "cloud_efficiency"
+	// DefaultNetworkInterfaceType is the type of network interfaces used if none is configured
+	DefaultNetworkInterfaceType = "Secondary"
+
+	// DiskEphemeralSSD is the legacy category name of local ephemeral SSD data disks. Such disks are always
+	// released together with the instance and must not carry the deleteWithInstance flag.
+	DiskEphemeralSSD = "DiskEphemeralSSD"
+)
+
+// addDefaultingFuncs registers the defaulting functions, which are maintained by hand instead of by defaulter-gen.
+func addDefaultingFuncs(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&ProviderSpec{}, func(obj interface{}) { SetObjectDefaults_ProviderSpec(obj.(*ProviderSpec)) })
+	return nil
+}
+
+// SetObjectDefaults_ProviderSpec sets the defaults of the ProviderSpec and all its nested objects.
+func SetObjectDefaults_ProviderSpec(in *ProviderSpec) {
+	SetDefaults_ProviderSpec(in)
+	if in.SystemDisk != nil {
+		SetDefaults_AlicloudSystemDisk(in.SystemDisk)
+	}
+	for i := range in.DataDisks {
+		SetDefaults_AlicloudDataDisk(&in.DataDisks[i])
+	}
+	for i := range in.NetworkInterfaces {
+		SetDefaults_AlicloudNetworkInterface(&in.NetworkInterfaces[i])
+	}
+}
+
+// SetDefaults_ProviderSpec sets default values for ProviderSpec objects.
+func SetDefaults_ProviderSpec(obj *ProviderSpec) {
+	if obj.InstanceType == "" && len(obj.InstanceTypes) > 0 {
+		obj.InstanceType = obj.InstanceTypes[0]
+	}
+	if obj.InstanceChargeType == "" {
+		obj.InstanceChargeType = DefaultInstanceChargeType
+	}
+	if obj.InternetChargeType == "" {
+		obj.InternetChargeType = DefaultInternetChargeType
+	}
+	if obj.SpotStrategy == "" {
+		obj.SpotStrategy = DefaultSpotStrategy
+	}
+	if obj.IoOptimized == "" {
+		obj.IoOptimized = DefaultIoOptimized
+	}
+}
+
+// SetDefaults_AlicloudSystemDisk sets default values for AlicloudSystemDisk objects.
+func SetDefaults_AlicloudSystemDisk(obj *AlicloudSystemDisk) {
+	if obj.Category == "" {
+		obj.Category = DefaultDiskCategory
+	}
+}
+
+// SetDefaults_AlicloudDataDisk sets default values for AlicloudDataDisk objects.
+func SetDefaults_AlicloudDataDisk(obj *AlicloudDataDisk) {
+	if obj.Category == "" {
+		obj.Category = DefaultDiskCategory
+	}
+	if obj.DeleteWithInstance == nil && obj.Category != DiskEphemeralSSD {
+		obj.DeleteWithInstance = ptr.To(true)
+	}
+}
+
+// SetDefaults_AlicloudNetworkInterface sets default values for AlicloudNetworkInterface objects.
+func SetDefaults_AlicloudNetworkInterface(obj *AlicloudNetworkInterface) {
+	if obj.Type == "" {
+		obj.Type = DefaultNetworkInterfaceType
+	}
+}
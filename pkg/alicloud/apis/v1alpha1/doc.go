@@ -0,0 +1,6 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package v1alpha1 contains the v1alpha1 version of the Alicloud ProviderSpec.
+package v1alpha1
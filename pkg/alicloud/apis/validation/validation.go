@@ -6,12 +6,416 @@
 package validation
 
 import (
-	api "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	api "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
+)
+
+const (
+	// tagClusterPrefix is the prefix of the tag key identifying the cluster an instance belongs to
+	tagClusterPrefix = "kubernetes.io/cluster/"
+	// tagRolePrefix is the prefix of the tag key identifying the role of an instance in the cluster
+	tagRolePrefix = "kubernetes.io/role/"
+	// tagReservedPrefix is the prefix of the tag keys the driver uses to record the ownership of an instance
+	tagReservedPrefix = "mcm.gardener.cloud/"
+
+	// diskEphemeralSSD is the legacy category name of local ephemeral SSD data disks
+	diskEphemeralSSD = "DiskEphemeralSSD"
+)
+
+var (
+	validInstanceChargeTypes   = sets.New("PrePaid", "PostPaid")
+	validInternetChargeTypes   = sets.New("PayByBandwidth", "PayByTraffic")
+	validSpotStrategies        = sets.New(api.SpotStrategyNoSpot, api.SpotStrategyWithPriceLimit, api.SpotStrategyAsPriceGo)
+	validSpotInterruptions     = sets.New("Terminate", "Stop")
+	validIoOptimized           = sets.New("none", "optimized")
+	validSystemDiskTypes       = sets.New("cloud", "cloud_efficiency", "cloud_ssd", "cloud_essd", "cloud_auto", "cloud_essd_entry")
+	validDataDiskTypes         = validSystemDiskTypes.Clone().Insert("ephemeral_ssd", diskEphemeralSSD)
+	validNetworkInterfaceTypes = sets.New(api.NetworkInterfaceTypePrimary, api.NetworkInterfaceTypeSecondary)
+
+	// systemDiskSizeRange is the allowed size range (GiB) of the system disk
+	systemDiskSizeRange = sizeRange{min: 20, max: 2048}
+	// dataDiskSizeRanges are the allowed size ranges (GiB) of data disks per category, defaultDataDiskSizeRange applies to all others
+	dataDiskSizeRanges = map[string]sizeRange{
+		"cloud":          {min: 5, max: 2000},
+		"ephemeral_ssd":  {min: 5, max: 800},
+		diskEphemeralSSD: {min: 5, max: 800},
+	}
+	defaultDataDiskSizeRange = sizeRange{min: 20, max: 32768}
+
+	// bandwidthInRange is the allowed range (Mbit/s) of the maximum inbound public bandwidth
+	bandwidthInRange = sizeRange{min: 1, max: 100}
+	// bandwidthOutRange is the allowed range (Mbit/s) of the maximum outbound public bandwidth
+	bandwidthOutRange = sizeRange{min: 0, max: 100}
+	// ipv6AddressCountRange is the allowed number of IPv6 addresses of a network interface
+	ipv6AddressCountRange = sizeRange{min: 1, max: 10}
+	// spotDurationRange is the allowed protection period (hours) of spot instances, which ECS accepts from 0 (no
+	// protection period) to 6
+	spotDurationRange = sizeRange{min: 0, max: 6}
+	// spotFallbackPercentageRange is the allowed share (%) of instances which may fall back to pay-as-you-go instances
+	spotFallbackPercentageRange = sizeRange{min: 1, max: 100}
 )
 
+type sizeRange struct {
+	min, max int
+}
+
+func (r sizeRange) contains(value int) bool {
+	return value >= r.min && value <= r.max
+}
+
+func (r sizeRange) String() string {
+	return fmt.Sprintf("between %d and %d", r.min, r.max)
+}
+
 // ValidateProviderSpecNSecret validates provider spec and secret to check if all fields are present and valid
-func ValidateProviderSpecNSecret(_ *api.ProviderSpec, _ *corev1.Secret) []error {
-	// Code for validation of providerSpec goes here
+func ValidateProviderSpecNSecret(spec *api.ProviderSpec, secret *corev1.Secret) []error {
+	allErrs := validateProviderSpec(spec, field.NewPath("providerSpec"))
+	allErrs = append(allErrs, validateSecret(secret, field.NewPath("secretRef"))...)
+
+	errs := make([]error, 0, len(allErrs))
+	for _, err := range allErrs {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// ValidateUserData validates that the secret contains the user data of the instances. Only launching an instance
+// needs the user data, so that the other driver methods still work with a secret which lacks it.
+func ValidateUserData(secret *corev1.Secret) []error {
+	if secret == nil || len(secret.Data[spi.AlicloudUserData]) == 0 {
+		return []error{field.Required(field.NewPath("secretRef", "data").Key(spi.AlicloudUserData), "userData is required")}
+	}
 	return nil
 }
+
+func validateProviderSpec(spec *api.ProviderSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec == nil {
+		return append(allErrs, field.Required(fldPath, "providerSpec is required"))
+	}
+
+	if spec.ImageID == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("imageID"), "imageID is required"))
+	}
+	if spec.InstanceType == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("instanceType"), "instanceType is required"))
+	}
+	allErrs = append(allErrs, validateInstanceTypes(spec.InstanceType, spec.InstanceTypes, fldPath.Child("instanceTypes"))...)
+	if spec.Region == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("region"), "region is required"))
+	}
+	if hasPrimaryNetworkInterface(spec.NetworkInterfaces) {
+		// the primary network interface is configured by its entry in networkInterfaces
+		for _, child := range []struct {
+			name  string
+			isSet bool
+		}{
+			{"vSwitchID", spec.VSwitchID != ""},
+			{"securityGroupID", spec.SecurityGroupID != ""},
+			{"privateIPAddress", spec.PrivateIPAddress != ""},
+			{"ipv6AddressCount", spec.IPv6AddressCount != nil},
+			{"ipv6Addresses", len(spec.IPv6Addresses) > 0},
+		} {
+			if child.isSet {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child(child.name), fmt.Sprintf("must not be set if the network interface of type %q is configured", api.NetworkInterfaceTypePrimary)))
+			}
+		}
+	} else if spec.VSwitchID == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("vSwitchID"), "vSwitchID is required"))
+	}
+
+	allErrs = append(allErrs, validateEnum(spec.InstanceChargeType, validInstanceChargeTypes, fldPath.Child("instanceChargeType"))...)
+	allErrs = append(allErrs, validateEnum(spec.InternetChargeType, validInternetChargeTypes, fldPath.Child("internetChargeType"))...)
+	allErrs = append(allErrs, validateEnum(spec.SpotStrategy, validSpotStrategies, fldPath.Child("spotStrategy"))...)
+	allErrs = append(allErrs, validateEnum(spec.IoOptimized, validIoOptimized, fldPath.Child("IoOptimized"))...)
+
+	if spec.InternetMaxBandwidthIn != nil && !bandwidthInRange.contains(*spec.InternetMaxBandwidthIn) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("internetMaxBandwidthIn"), *spec.InternetMaxBandwidthIn, "must be "+bandwidthInRange.String()))
+	}
+	if spec.InternetMaxBandwidthOut != nil && !bandwidthOutRange.contains(*spec.InternetMaxBandwidthOut) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("internetMaxBandwidthOut"), *spec.InternetMaxBandwidthOut, "must be "+bandwidthOutRange.String()))
+	}
+
+	allErrs = append(allErrs, validateSpot(spec, fldPath)...)
+	allErrs = append(allErrs, validateIPv6Addresses(spec.IPv6AddressCount, spec.IPv6Addresses, fldPath)...)
+	allErrs = append(allErrs, validateSystemDisk(spec.SystemDisk, fldPath.Child("systemDisk"))...)
+	allErrs = append(allErrs, validateDataDisks(spec.DataDisks, fldPath.Child("dataDisks"))...)
+	allErrs = append(allErrs, validateNetworkInterfaces(spec.NetworkInterfaces, fldPath.Child("networkInterfaces"))...)
+	allErrs = append(allErrs, validateTags(spec.Tags, fldPath.Child("tags"))...)
+
+	return allErrs
+}
+
+func validateEnum(value string, allowed sets.Set[string], fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if value != "" && !allowed.Has(value) {
+		allErrs = append(allErrs, field.NotSupported(fldPath, value, sets.List(allowed)))
+	}
+	return allErrs
+}
+
+// validateSpot validates that the spot instance settings are consistent with the spot strategy
+func validateSpot(spec *api.ProviderSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	isSpot := spec.SpotStrategy == api.SpotStrategyWithPriceLimit || spec.SpotStrategy == api.SpotStrategyAsPriceGo
+	if isSpot && spec.InstanceChargeType == "PrePaid" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("spotStrategy"), "spot instances must not be subscription (PrePaid) instances"))
+	}
+
+	if spec.SpotStrategy == api.SpotStrategyWithPriceLimit {
+		if spec.SpotPriceLimit == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("spotPriceLimit"), fmt.Sprintf("spotPriceLimit is required for spot strategy %q", api.SpotStrategyWithPriceLimit)))
+		} else if *spec.SpotPriceLimit <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("spotPriceLimit"), *spec.SpotPriceLimit, "must be greater than 0"))
+		}
+	} else if spec.SpotPriceLimit != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("spotPriceLimit"), fmt.Sprintf("must only be set for spot strategy %q", api.SpotStrategyWithPriceLimit)))
+	}
+
+	if !isSpot {
+		if spec.SpotDuration != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("spotDuration"), "must only be set for spot instances"))
+		}
+		if spec.SpotInterruptionBehavior != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("spotInterruptionBehavior"), "must only be set for spot instances"))
+		}
+		if spec.SpotFallback != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("spotFallback"), "must only be set for spot instances"))
+		}
+		return allErrs
+	}
+
+	if spec.SpotDuration != nil && !spotDurationRange.contains(*spec.SpotDuration) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("spotDuration"), *spec.SpotDuration, "must be "+spotDurationRange.String()))
+	}
+	allErrs = append(allErrs, validateEnum(spec.SpotInterruptionBehavior, validSpotInterruptions, fldPath.Child("spotInterruptionBehavior"))...)
+	if spec.SpotFallback != nil && spec.SpotFallback.MaxPercentage != nil && !spotFallbackPercentageRange.contains(*spec.SpotFallback.MaxPercentage) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("spotFallback", "maxPercentage"), *spec.SpotFallback.MaxPercentage, "must be "+spotFallbackPercentageRange.String()))
+	}
+
+	return allErrs
+}
+
+// validateInstanceTypes validates the instance types tried in order, the first of which has to be the instance type
+func validateInstanceTypes(instanceType string, instanceTypes []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	seen := sets.New[string]()
+
+	for i, entry := range instanceTypes {
+		idxPath := fldPath.Index(i)
+
+		switch {
+		case entry == "":
+			allErrs = append(allErrs, field.Required(idxPath, "instance type is required"))
+		case seen.Has(entry):
+			allErrs = append(allErrs, field.Duplicate(idxPath, entry))
+		case i == 0 && instanceType != "" && entry != instanceType:
+			allErrs = append(allErrs, field.Invalid(idxPath, entry, fmt.Sprintf("must be the instanceType %q", instanceType)))
+		}
+		seen.Insert(entry)
+	}
+
+	return allErrs
+}
+
+func validateSystemDisk(disk *api.AlicloudSystemDisk, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if disk == nil {
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateEnum(disk.Category, validSystemDiskTypes, fldPath.Child("category"))...)
+	if disk.Size != 0 && !systemDiskSizeRange.contains(disk.Size) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("size"), disk.Size, "must be "+systemDiskSizeRange.String()))
+	}
+
+	return allErrs
+}
+
+func validateDataDisks(disks []api.AlicloudDataDisk, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := sets.New[string]()
+
+	for i, disk := range disks {
+		idxPath := fldPath.Index(i)
+
+		if disk.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), "data disk name is required"))
+		} else if names.Has(disk.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), disk.Name))
+		}
+		names.Insert(disk.Name)
+
+		allErrs = append(allErrs, validateEnum(disk.Category, validDataDiskTypes, idxPath.Child("category"))...)
+
+		sizeRange, ok := dataDiskSizeRanges[disk.Category]
+		if !ok {
+			sizeRange = defaultDataDiskSizeRange
+		}
+		if !sizeRange.contains(disk.Size) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("size"), disk.Size, "must be "+sizeRange.String()))
+		}
+	}
+
+	return allErrs
+}
+
+func validateNetworkInterfaces(networkInterfaces []api.AlicloudNetworkInterface, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := sets.New[string]()
+
+	for i, networkInterface := range networkInterfaces {
+		idxPath := fldPath.Index(i)
+
+		if networkInterface.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), "network interface name is required"))
+		} else if names.Has(networkInterface.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), networkInterface.Name))
+		}
+		names.Insert(networkInterface.Name)
+
+		allErrs = append(allErrs, validateEnum(networkInterface.Type, validNetworkInterfaceTypes, idxPath.Child("type"))...)
+		if networkInterface.Type == api.NetworkInterfaceTypePrimary && i != 0 {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("type"), networkInterface.Type, "only the first network interface can be the primary one"))
+		}
+		if networkInterface.VSwitchID == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("vSwitchID"), "vSwitchID is required"))
+		}
+		if len(networkInterface.SecurityGroupIDs) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("securityGroupIDs"), "at least one security group is required"))
+		}
+		if networkInterface.QueueNumber != nil && *networkInterface.QueueNumber < 1 {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("queueNumber"), *networkInterface.QueueNumber, "must be greater than 0"))
+		}
+		allErrs = append(allErrs, validateIPv6Addresses(networkInterface.IPv6AddressCount, networkInterface.IPv6Addresses, idxPath)...)
+	}
+
+	return allErrs
+}
+
+// validateIPv6Addresses validates the IPv6 addresses of a network interface, which are either assigned explicitly or
+// chosen by ECS. Whether the vSwitch has an IPv6 CIDR block is checked by CreateMachine, as it requires a VPC call.
+func validateIPv6Addresses(count *int, addresses []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if count != nil {
+		if !ipv6AddressCountRange.contains(*count) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ipv6AddressCount"), *count, "must be "+ipv6AddressCountRange.String()))
+		}
+		if len(addresses) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("ipv6Addresses"), "must not be set together with ipv6AddressCount"))
+		}
+	}
+	if len(addresses) > ipv6AddressCountRange.max {
+		allErrs = append(allErrs, field.TooMany(fldPath.Child("ipv6Addresses"), len(addresses), ipv6AddressCountRange.max))
+	}
+
+	seen := sets.New[string]()
+	for i, address := range addresses {
+		ip := net.ParseIP(address)
+		if ip == nil || ip.To4() != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ipv6Addresses").Index(i), address, "must be an IPv6 address"))
+			continue
+		}
+		if seen.Has(ip.String()) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("ipv6Addresses").Index(i), address))
+		}
+		seen.Insert(ip.String())
+	}
+
+	return allErrs
+}
+
+// hasPrimaryNetworkInterface returns true if the primary network interface is configured in the given network interfaces.
+func hasPrimaryNetworkInterface(networkInterfaces []api.AlicloudNetworkInterface) bool {
+	return len(networkInterfaces) > 0 && networkInterfaces[0].Type == api.NetworkInterfaceTypePrimary
+}
+
+func validateTags(tags map[string]string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	hasCluster, hasRole := false, false
+
+	for key := range tags {
+		if strings.HasPrefix(key, tagReservedPrefix) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Key(key), fmt.Sprintf("tag keys prefixed by %q are reserved", tagReservedPrefix)))
+		} else if strings.HasPrefix(key, tagClusterPrefix) {
+			hasCluster = true
+		} else if strings.HasPrefix(key, tagRolePrefix) {
+			hasRole = true
+		}
+	}
+
+	if !hasCluster {
+		allErrs = append(allErrs, field.Required(fldPath, fmt.Sprintf("tag with key prefixed by %q is required", tagClusterPrefix)))
+	}
+	if !hasRole {
+		allErrs = append(allErrs, field.Required(fldPath, fmt.Sprintf("tag with key prefixed by %q is required", tagRolePrefix)))
+	}
+
+	return allErrs
+}
+
+func validateSecret(secret *corev1.Secret, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if secret == nil {
+		return append(allErrs, field.Required(fldPath, "secret is required"))
+	}
+
+	dataPath := fldPath.Child("data")
+	if hasAnyKey(secret.Data, spi.AlicloudOIDCProviderARN) {
+		// the RAM role is assumed with the OIDC token, no access key is needed
+		if !hasAnyKey(secret.Data, spi.AlicloudRoleARN) {
+			allErrs = append(allErrs, field.Required(dataPath.Key(spi.AlicloudRoleARN), fmt.Sprintf("%q is required if %q is set", spi.AlicloudRoleARN, spi.AlicloudOIDCProviderARN)))
+		}
+	} else {
+		if !hasAnyKey(secret.Data, spi.AlicloudAccessKeyID, spi.AlicloudAlternativeAccessKeyID) {
+			allErrs = append(allErrs, field.Required(dataPath.Key(spi.AlicloudAccessKeyID), fmt.Sprintf("either %q or %q is required", spi.AlicloudAccessKeyID, spi.AlicloudAlternativeAccessKeyID)))
+		}
+		if !hasAnyKey(secret.Data, spi.AlicloudAccessKeySecret, spi.AlicloudAlternativeAccessKeySecret) {
+			allErrs = append(allErrs, field.Required(dataPath.Key(spi.AlicloudAccessKeySecret), fmt.Sprintf("either %q or %q is required", spi.AlicloudAccessKeySecret, spi.AlicloudAlternativeAccessKeySecret)))
+		}
+	}
+	if endpointType, ok := secret.Data[spi.AlicloudEndpointType]; ok {
+		allErrs = append(allErrs, validateEnum(strings.TrimSpace(string(endpointType)), spi.ValidEndpointTypes, dataPath.Key(spi.AlicloudEndpointType))...)
+	}
+	if duration, ok := secret.Data[spi.AlicloudRoleSessionDuration]; ok {
+		allErrs = append(allErrs, validateRoleSessionDuration(string(duration), dataPath.Key(spi.AlicloudRoleSessionDuration))...)
+	}
+
+	return allErrs
+}
+
+func validateRoleSessionDuration(value string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	duration, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, value, "must be a duration, e.g. 1h"))
+	}
+	if duration < spi.MinRoleSessionDuration || duration > spi.MaxRoleSessionDuration {
+		allErrs = append(allErrs, field.Invalid(fldPath, value, fmt.Sprintf("must be between %s and %s", spi.MinRoleSessionDuration, spi.MaxRoleSessionDuration)))
+	}
+	return allErrs
+}
+
+// hasAnyKey returns true if the data map contains a non-blank value for at least one of the given keys.
+func hasAnyKey(data map[string][]byte, keys ...string) bool {
+	for _, key := range keys {
+		if len(strings.TrimSpace(string(data[key]))) > 0 {
+			return true
+		}
+	}
+	return false
+}
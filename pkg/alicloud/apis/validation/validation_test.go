@@ -0,0 +1,296 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
+)
+
+var _ = Describe("Validation", func() {
+	var (
+		providerSpec *api.ProviderSpec
+		secret       *corev1.Secret
+	)
+
+	BeforeEach(func() {
+		providerSpec = &api.ProviderSpec{
+			ImageID:                 "m-uf6jf6utod2nfs9x21iwse",
+			InstanceType:            "ecs.g6.large",
+			Region:                  "cn-shanghai",
+			ZoneID:                  "cn-shanghai-e",
+			SecurityGroupID:         "sg-uf69t4txlz6r18ybzxbx",
+			VSwitchID:               "vsw-uf6s1fjxxks65rk1tkrpm",
+			InstanceChargeType:      "PostPaid",
+			InternetChargeType:      "PayByTraffic",
+			InternetMaxBandwidthIn:  ptr.To(5),
+			InternetMaxBandwidthOut: ptr.To(5),
+			SpotStrategy:            "NoSpot",
+			KeyPairName:             "shoot-ssh-publickey",
+			Tags: map[string]string{
+				"kubernetes.io/cluster/shoot--mcm":     "1",
+				"kubernetes.io/role/worker/shoot--mcm": "1",
+			},
+			SystemDisk: &api.AlicloudSystemDisk{
+				Category: "cloud_efficiency",
+				Size:     50,
+			},
+			DataDisks: []api.AlicloudDataDisk{
+				{
+					Name:     "disk-1",
+					Category: "cloud_essd",
+					Size:     100,
+				},
+			},
+		}
+		secret = &corev1.Secret{
+			Data: map[string][]byte{
+				spi.AlicloudAccessKeyID:     []byte("access-key-id"),
+				spi.AlicloudAccessKeySecret: []byte("access-key-secret"),
+				spi.AlicloudUserData:        []byte("user-data"),
+			},
+		}
+	})
+
+	It("should accept a valid provider spec and secret", func() {
+		Expect(ValidateProviderSpecNSecret(providerSpec, secret)).To(BeEmpty())
+	})
+
+	It("should accept the alternative credential keys", func() {
+		secret.Data = map[string][]byte{
+			spi.AlicloudAlternativeAccessKeyID:     []byte("access-key-id"),
+			spi.AlicloudAlternativeAccessKeySecret: []byte("access-key-secret"),
+			spi.AlicloudUserData:                   []byte("user-data"),
+		}
+		Expect(ValidateProviderSpecNSecret(providerSpec, secret)).To(BeEmpty())
+	})
+
+	It("should accept a RAM role to assume", func() {
+		secret.Data[spi.AlicloudRoleARN] = []byte("acs:ram::123456789:role/machine-controller-manager")
+		secret.Data[spi.AlicloudRoleSessionDuration] = []byte("1h")
+		Expect(ValidateProviderSpecNSecret(providerSpec, secret)).To(BeEmpty())
+	})
+
+	It("should accept a RAM role to assume with an OIDC token instead of an access key", func() {
+		secret.Data = map[string][]byte{
+			spi.AlicloudRoleARN:         []byte("acs:ram::123456789:role/machine-controller-manager"),
+			spi.AlicloudOIDCProviderARN: []byte("acs:ram::123456789:oidc-provider/ack-rrsa-c123"),
+			spi.AlicloudUserData:        []byte("user-data"),
+		}
+		Expect(ValidateProviderSpecNSecret(providerSpec, secret)).To(BeEmpty())
+	})
+
+	It("should accept network interfaces", func() {
+		providerSpec.NetworkInterfaces = []api.AlicloudNetworkInterface{
+			{Name: "storage", Type: api.NetworkInterfaceTypeSecondary, VSwitchID: "vsw-storage", SecurityGroupIDs: []string{"sg-storage"}, QueueNumber: ptr.To(4)},
+		}
+		Expect(ValidateProviderSpecNSecret(providerSpec, secret)).To(BeEmpty())
+
+		providerSpec.VSwitchID, providerSpec.SecurityGroupID = "", ""
+		providerSpec.NetworkInterfaces = append([]api.AlicloudNetworkInterface{
+			{Name: "primary", Type: api.NetworkInterfaceTypePrimary, VSwitchID: "vsw-primary", SecurityGroupIDs: []string{"sg-1", "sg-2"}},
+		}, providerSpec.NetworkInterfaces...)
+		Expect(ValidateProviderSpecNSecret(providerSpec, secret)).To(BeEmpty())
+	})
+
+	It("should accept IPv6 addresses", func() {
+		providerSpec.IPv6AddressCount = ptr.To(1)
+		providerSpec.NetworkInterfaces = []api.AlicloudNetworkInterface{
+			{Name: "storage", Type: api.NetworkInterfaceTypeSecondary, VSwitchID: "vsw-storage", SecurityGroupIDs: []string{"sg-storage"}, IPv6Addresses: []string{"2408:4005:39c:8300::20", "2408:4005:39c:8300::21"}},
+		}
+		Expect(ValidateProviderSpecNSecret(providerSpec, secret)).To(BeEmpty())
+
+		providerSpec.VSwitchID, providerSpec.SecurityGroupID, providerSpec.IPv6AddressCount = "", "", nil
+		providerSpec.NetworkInterfaces = append([]api.AlicloudNetworkInterface{
+			{Name: "primary", Type: api.NetworkInterfaceTypePrimary, VSwitchID: "vsw-primary", SecurityGroupIDs: []string{"sg-primary"}, IPv6Addresses: []string{"2408:4005:39c:8300::10", "2408:4005:39c:8300::11"}},
+		}, providerSpec.NetworkInterfaces...)
+		Expect(ValidateProviderSpecNSecret(providerSpec, secret)).To(BeEmpty())
+	})
+
+	It("should accept spot instances with a price limit", func() {
+		providerSpec.SpotStrategy = api.SpotStrategyWithPriceLimit
+		providerSpec.SpotPriceLimit = ptr.To(0.98)
+		providerSpec.SpotDuration = ptr.To(6)
+		providerSpec.SpotInterruptionBehavior = "Stop"
+		Expect(ValidateProviderSpecNSecret(providerSpec, secret)).To(BeEmpty())
+	})
+
+	It("should accept a fallback of spot instances to pay-as-you-go instances", func() {
+		providerSpec.SpotStrategy = api.SpotStrategyAsPriceGo
+		providerSpec.SpotFallback = &api.AlicloudSpotFallback{MaxPercentage: ptr.To(20)}
+		Expect(ValidateProviderSpecNSecret(providerSpec, secret)).To(BeEmpty())
+	})
+
+	It("should accept instance types tried in order", func() {
+		providerSpec.InstanceTypes = []string{"ecs.g6.large", "ecs.g7.large", "ecs.c6.xlarge"}
+		Expect(ValidateProviderSpecNSecret(providerSpec, secret)).To(BeEmpty())
+	})
+
+	It("should reject a missing provider spec and secret", func() {
+		errs := ValidateProviderSpecNSecret(nil, nil)
+		Expect(errs).To(HaveLen(2))
+		Expect(errs[0].Error()).To(ContainSubstring("providerSpec: Required value"))
+		Expect(errs[1].Error()).To(ContainSubstring("secretRef: Required value"))
+	})
+
+	It("should require the user data only when asked to", func() {
+		delete(secret.Data, spi.AlicloudUserData)
+		Expect(ValidateProviderSpecNSecret(providerSpec, secret)).To(BeEmpty())
+
+		errs := ValidateUserData(secret)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Error()).To(HavePrefix("secretRef.data[userData]:"))
+	})
+
+	DescribeTable("invalid provider spec",
+		func(mutate func(*api.ProviderSpec), expectedFields ...string) {
+			mutate(providerSpec)
+			errs := ValidateProviderSpecNSecret(providerSpec, secret)
+			Expect(errs).To(HaveLen(len(expectedFields)))
+			for i, field := range expectedFields {
+				Expect(errs[i].Error()).To(HavePrefix(field + ":"))
+			}
+		},
+		Entry("missing required fields", func(spec *api.ProviderSpec) {
+			spec.ImageID, spec.InstanceType, spec.Region, spec.VSwitchID = "", "", "", ""
+		}, "providerSpec.imageID", "providerSpec.instanceType", "providerSpec.region", "providerSpec.vSwitchID"),
+		Entry("invalid instance types", func(spec *api.ProviderSpec) {
+			spec.InstanceTypes = []string{"ecs.g7.large", "", "ecs.g7.large"}
+		}, "providerSpec.instanceTypes[0]", "providerSpec.instanceTypes[1]", "providerSpec.instanceTypes[2]"),
+		Entry("unsupported charge types", func(spec *api.ProviderSpec) {
+			spec.InstanceChargeType = "Prepaid"
+			spec.InternetChargeType = "PayByData"
+		}, "providerSpec.instanceChargeType", "providerSpec.internetChargeType"),
+		Entry("unsupported spot strategy", func(spec *api.ProviderSpec) {
+			spec.SpotStrategy = "SpotAsPriceStop"
+		}, "providerSpec.spotStrategy"),
+		Entry("spot settings of instances which are no spot instances", func(spec *api.ProviderSpec) {
+			spec.SpotPriceLimit = ptr.To(0.98)
+			spec.SpotDuration = ptr.To(1)
+			spec.SpotInterruptionBehavior = "Terminate"
+			spec.SpotFallback = &api.AlicloudSpotFallback{}
+		}, "providerSpec.spotPriceLimit", "providerSpec.spotDuration", "providerSpec.spotInterruptionBehavior", "providerSpec.spotFallback"),
+		Entry("spot instances with a price limit without a price limit", func(spec *api.ProviderSpec) {
+			spec.SpotStrategy = api.SpotStrategyWithPriceLimit
+		}, "providerSpec.spotPriceLimit"),
+		Entry("invalid spot settings", func(spec *api.ProviderSpec) {
+			spec.SpotStrategy = api.SpotStrategyWithPriceLimit
+			spec.SpotPriceLimit = ptr.To(-1.0)
+			spec.SpotDuration = ptr.To(7)
+			spec.SpotInterruptionBehavior = "Hibernate"
+			spec.SpotFallback = &api.AlicloudSpotFallback{MaxPercentage: ptr.To(0)}
+		}, "providerSpec.spotPriceLimit", "providerSpec.spotDuration", "providerSpec.spotInterruptionBehavior", "providerSpec.spotFallback.maxPercentage"),
+		Entry("spot instances bidding the market price with a price limit", func(spec *api.ProviderSpec) {
+			spec.SpotStrategy = api.SpotStrategyAsPriceGo
+			spec.SpotPriceLimit = ptr.To(0.98)
+		}, "providerSpec.spotPriceLimit"),
+		Entry("subscription spot instances", func(spec *api.ProviderSpec) {
+			spec.SpotStrategy = api.SpotStrategyAsPriceGo
+			spec.InstanceChargeType = "PrePaid"
+		}, "providerSpec.spotStrategy"),
+		Entry("unsupported IoOptimized value", func(spec *api.ProviderSpec) {
+			spec.IoOptimized = "true"
+		}, "providerSpec.IoOptimized"),
+		Entry("bandwidth out of range", func(spec *api.ProviderSpec) {
+			spec.InternetMaxBandwidthIn = ptr.To(0)
+			spec.InternetMaxBandwidthOut = ptr.To(101)
+		}, "providerSpec.internetMaxBandwidthIn", "providerSpec.internetMaxBandwidthOut"),
+		Entry("invalid system disk", func(spec *api.ProviderSpec) {
+			spec.SystemDisk = &api.AlicloudSystemDisk{Category: "ephemeral_ssd", Size: 10}
+		}, "providerSpec.systemDisk.category", "providerSpec.systemDisk.size"),
+		Entry("invalid data disks", func(spec *api.ProviderSpec) {
+			spec.DataDisks = []api.AlicloudDataDisk{
+				{Name: "disk-1", Category: "cloud", Size: 2001},
+				{Name: "disk-1", Category: "cloud_essd", Size: 40},
+				{Category: "cloud_hdd", Size: 40},
+			}
+		}, "providerSpec.dataDisks[0].size", "providerSpec.dataDisks[1].name", "providerSpec.dataDisks[2].name", "providerSpec.dataDisks[2].category"),
+		Entry("invalid network interfaces", func(spec *api.ProviderSpec) {
+			spec.NetworkInterfaces = []api.AlicloudNetworkInterface{
+				{Name: "storage", Type: api.NetworkInterfaceTypeSecondary, VSwitchID: "vsw-storage", SecurityGroupIDs: []string{"sg-storage"}, QueueNumber: ptr.To(0)},
+				{Name: "storage", Type: api.NetworkInterfaceTypePrimary, VSwitchID: "vsw-storage", SecurityGroupIDs: []string{"sg-storage"}},
+				{Type: "Trunk"},
+			}
+		}, "providerSpec.networkInterfaces[0].queueNumber", "providerSpec.networkInterfaces[1].name", "providerSpec.networkInterfaces[1].type",
+			"providerSpec.networkInterfaces[2].name", "providerSpec.networkInterfaces[2].type", "providerSpec.networkInterfaces[2].vSwitchID", "providerSpec.networkInterfaces[2].securityGroupIDs"),
+		Entry("primary network interface configured twice", func(spec *api.ProviderSpec) {
+			spec.PrivateIPAddress = "10.250.0.10"
+			spec.NetworkInterfaces = []api.AlicloudNetworkInterface{
+				{Name: "primary", Type: api.NetworkInterfaceTypePrimary, VSwitchID: "vsw-primary", SecurityGroupIDs: []string{"sg-primary"}},
+			}
+		}, "providerSpec.vSwitchID", "providerSpec.securityGroupID", "providerSpec.privateIPAddress"),
+		Entry("invalid IPv6 addresses", func(spec *api.ProviderSpec) {
+			spec.IPv6AddressCount = ptr.To(11)
+			spec.IPv6Addresses = []string{"2408:4005:39c:8300::10", "10.250.0.10", "2408:4005:39c:8300:0::10"}
+		}, "providerSpec.ipv6AddressCount", "providerSpec.ipv6Addresses", "providerSpec.ipv6Addresses[1]", "providerSpec.ipv6Addresses[2]"),
+		Entry("invalid IPv6 addresses of a network interface", func(spec *api.ProviderSpec) {
+			spec.VSwitchID, spec.SecurityGroupID = "", ""
+			spec.NetworkInterfaces = []api.AlicloudNetworkInterface{
+				{Name: "primary", Type: api.NetworkInterfaceTypePrimary, VSwitchID: "vsw-primary", SecurityGroupIDs: []string{"sg-primary"}, IPv6AddressCount: ptr.To(0)},
+			}
+		}, "providerSpec.networkInterfaces[0].ipv6AddressCount"),
+		Entry("invalid IPv6 addresses of a secondary network interface", func(spec *api.ProviderSpec) {
+			spec.NetworkInterfaces = []api.AlicloudNetworkInterface{
+				{Name: "storage", Type: api.NetworkInterfaceTypeSecondary, VSwitchID: "vsw-storage", SecurityGroupIDs: []string{"sg-storage"}, IPv6AddressCount: ptr.To(1), IPv6Addresses: []string{"2408:4005:39c:8300::10"}},
+			}
+		}, "providerSpec.networkInterfaces[0].ipv6Addresses"),
+		Entry("primary network interface with IPv6 addresses configured twice", func(spec *api.ProviderSpec) {
+			spec.VSwitchID, spec.SecurityGroupID = "", ""
+			spec.IPv6AddressCount = ptr.To(1)
+			spec.NetworkInterfaces = []api.AlicloudNetworkInterface{
+				{Name: "primary", Type: api.NetworkInterfaceTypePrimary, VSwitchID: "vsw-primary", SecurityGroupIDs: []string{"sg-primary"}, IPv6AddressCount: ptr.To(1)},
+			}
+		}, "providerSpec.ipv6AddressCount"),
+		Entry("missing mandatory tags", func(spec *api.ProviderSpec) {
+			spec.Tags = map[string]string{"foo": "bar"}
+		}, "providerSpec.tags", "providerSpec.tags"),
+		Entry("mandatory tags not prefixed by the cluster and role prefixes", func(spec *api.ProviderSpec) {
+			spec.Tags = map[string]string{"foo/kubernetes.io/cluster/shoot--mcm": "1", "foo/kubernetes.io/role/node": "1"}
+		}, "providerSpec.tags", "providerSpec.tags"),
+		Entry("reserved tags", func(spec *api.ProviderSpec) {
+			spec.Tags["mcm.gardener.cloud/machine-class"] = "foo"
+		}, "providerSpec.tags[mcm.gardener.cloud/machine-class]"),
+	)
+
+	DescribeTable("invalid secret",
+		func(data map[string][]byte, expectedFields ...string) {
+			secret.Data = data
+			errs := ValidateProviderSpecNSecret(providerSpec, secret)
+			Expect(errs).To(HaveLen(len(expectedFields)))
+			for i, field := range expectedFields {
+				Expect(errs[i].Error()).To(HavePrefix(field + ":"))
+			}
+		},
+		Entry("empty secret", nil,
+			"secretRef.data[alicloudAccessKeyID]", "secretRef.data[alicloudAccessKeySecret]"),
+		Entry("blank credentials", map[string][]byte{
+			spi.AlicloudAccessKeyID:     []byte(" "),
+			spi.AlicloudAccessKeySecret: []byte("access-key-secret"),
+			spi.AlicloudUserData:        []byte("user-data"),
+		}, "secretRef.data[alicloudAccessKeyID]"),
+		Entry("invalid role session duration", map[string][]byte{
+			spi.AlicloudAccessKeyID:         []byte("access-key-id"),
+			spi.AlicloudAccessKeySecret:     []byte("access-key-secret"),
+			spi.AlicloudRoleARN:             []byte("acs:ram::123456789:role/machine-controller-manager"),
+			spi.AlicloudRoleSessionDuration: []byte("5m"),
+			spi.AlicloudUserData:            []byte("user-data"),
+		}, "secretRef.data[alicloudRoleSessionDuration]"),
+		Entry("unsupported endpoint type", map[string][]byte{
+			spi.AlicloudAccessKeyID:     []byte("access-key-id"),
+			spi.AlicloudAccessKeySecret: []byte("access-key-secret"),
+			spi.AlicloudEndpointType:    []byte("internal"),
+			spi.AlicloudUserData:        []byte("user-data"),
+		}, "secretRef.data[alicloudEndpointType]"),
+		Entry("OIDC provider without role", map[string][]byte{
+			spi.AlicloudOIDCProviderARN: []byte("acs:ram::123456789:oidc-provider/ack-rrsa-c123"),
+			spi.AlicloudUserData:        []byte("user-data"),
+		}, "secretRef.data[alicloudRoleARN]"),
+	)
+})
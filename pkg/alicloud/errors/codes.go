@@ -34,3 +34,65 @@ const (
 	// ResourceNotAvailable : Resource you requested is not available in this region or zone.
 	ResourceNotAvailable = "ResourceNotAvailable"
 )
+
+// constants for alicloud API error codes of transient failures which map to MCM `Unavailable` code
+const (
+	// Throttling : The request was denied due to request throttling. Also the prefix of more specific throttling codes.
+	Throttling = "Throttling"
+	// ThrottlingUser : Request was denied due to user flow control.
+	ThrottlingUser = "Throttling.User"
+	// ServiceUnavailable : The request has failed due to a temporary failure of the server.
+	ServiceUnavailable = "ServiceUnavailable"
+	// IncorrectInstanceStatus : The current status of the resource does not support this operation.
+	IncorrectInstanceStatus = "IncorrectInstanceStatus"
+	// OperationConflict : The operation may conflicts with other operations on the resource.
+	OperationConflict = "OperationConflict"
+)
+
+// constants for alicloud API error codes of authentication failures which map to MCM `Unauthenticated` code
+const (
+	// InvalidAccessKeyIDNotFound : Specified access key is not found.
+	InvalidAccessKeyIDNotFound = "InvalidAccessKeyId.NotFound"
+	// InvalidAccessKeyIDInactive : Specified access key is disabled.
+	InvalidAccessKeyIDInactive = "InvalidAccessKeyId.Inactive"
+	// SignatureDoesNotMatch : Specified signature is not matched with our calculation, e.g. due to a wrong access key secret.
+	SignatureDoesNotMatch = "SignatureDoesNotMatch"
+	// InvalidSecurityTokenExpired : Specified SecurityToken is expired.
+	InvalidSecurityTokenExpired = "InvalidSecurityToken.Expired"
+	// InvalidSecurityTokenMalformed : Specified SecurityToken is malformed.
+	InvalidSecurityTokenMalformed = "InvalidSecurityToken.Malformed"
+)
+
+// constants for alicloud API error codes of authorization failures which map to MCM `PermissionDenied` code
+const (
+	// Forbidden : User not authorized to operate on the specified resource.
+	Forbidden = "Forbidden"
+	// ForbiddenRAM : User not authorized to operate on the specified resource, or this API doesn't support RAM.
+	ForbiddenRAM = "Forbidden.RAM"
+)
+
+// constants for alicloud API error codes of missing resources which map to MCM `NotFound` code
+const (
+	// InvalidInstanceIDNotFound : The specified InstanceId does not exist.
+	InvalidInstanceIDNotFound = "InvalidInstanceId.NotFound"
+	// InvalidDiskIDNotFound : The specified disk does not exist.
+	InvalidDiskIDNotFound = "InvalidDiskId.NotFound"
+)
+
+// constants for alicloud API error codes of invalid requests which map to MCM `InvalidArgument` code
+const (
+	// InvalidParameter : The specified parameter is invalid. Also the prefix of more specific codes for single parameters.
+	InvalidParameter = "InvalidParameter"
+	// MissingParameter : A required parameter is missing. Also the prefix of more specific codes for single parameters.
+	MissingParameter = "MissingParameter"
+	// InvalidVSwitchIDNotFound : The specified VSwitchId does not exist.
+	InvalidVSwitchIDNotFound = "InvalidVSwitchId.NotFound"
+	// InvalidVSwitchIDIPv6NotTurnOn : The specified VSwitch has no IPv6 CIDR block, so no IPv6 addresses can be assigned.
+	InvalidVSwitchIDIPv6NotTurnOn = "InvalidVSwitchId.Ipv6NotTurnOn"
+	// InvalidSecurityGroupIDNotFound : The specified SecurityGroupId does not exist.
+	InvalidSecurityGroupIDNotFound = "InvalidSecurityGroupId.NotFound"
+	// InvalidKeyPairNameNotFound : The specified KeyPairName does not exist.
+	InvalidKeyPairNameNotFound = "InvalidKeyPairName.NotFound"
+	// InvalidInstanceTypeValueNotSupported : The specified InstanceType does not exist or you are not authorized to use it.
+	InvalidInstanceTypeValueNotSupported = "InvalidInstanceType.ValueNotSupported"
+)
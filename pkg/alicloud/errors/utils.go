@@ -6,16 +6,91 @@
 package errors
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
 	"github.com/alibabacloud-go/tea/tea"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
 )
 
+// ErrClientRateLimited is returned for ECS calls which are not allowed by the client-side rate limiter before the
+// context of the driver method is done.
+var ErrClientRateLimited = errors.New("client-side rate limit of ECS calls exceeded")
+
+// mcmErrorCodes maps Alicloud API error codes to the MCM error codes of all driver methods
+var mcmErrorCodes = map[string]codes.Code{
+	Throttling:              codes.Unavailable,
+	ThrottlingUser:          codes.Unavailable,
+	ServiceUnavailable:      codes.Unavailable,
+	IncorrectInstanceStatus: codes.Unavailable,
+	OperationConflict:       codes.Unavailable,
+
+	InvalidAccessKeyIDNotFound:    codes.Unauthenticated,
+	InvalidAccessKeyIDInactive:    codes.Unauthenticated,
+	SignatureDoesNotMatch:         codes.Unauthenticated,
+	InvalidSecurityTokenExpired:   codes.Unauthenticated,
+	InvalidSecurityTokenMalformed: codes.Unauthenticated,
+
+	Forbidden:    codes.PermissionDenied,
+	ForbiddenRAM: codes.PermissionDenied,
+
+	InvalidInstanceIDNotFound: codes.NotFound,
+	InvalidDiskIDNotFound:     codes.NotFound,
+
+	InvalidParameter:                     codes.InvalidArgument,
+	MissingParameter:                     codes.InvalidArgument,
+	InvalidVSwitchIDNotFound:             codes.InvalidArgument,
+	InvalidVSwitchIDIPv6NotTurnOn:        codes.InvalidArgument,
+	InvalidSecurityGroupIDNotFound:       codes.InvalidArgument,
+	InvalidKeyPairNameNotFound:           codes.InvalidArgument,
+	InvalidInstanceTypeValueNotSupported: codes.InvalidArgument,
+}
+
+// mcmErrorCodePrefixes maps prefixes of Alicloud API error codes to MCM error codes, e.g. `Throttling.Api` or
+// `InvalidParameter.Conflict`, which are not listed in mcmErrorCodes
+var mcmErrorCodePrefixes = map[string]codes.Code{
+	Throttling:       codes.Unavailable,
+	InvalidParameter: codes.InvalidArgument,
+	MissingParameter: codes.InvalidArgument,
+}
+
+// GetMCMErrorCode takes the error returned from an Alicloud API and returns the corresponding MCM error code.
+// Network failures and client-side rate limiting are considered transient, all unknown errors are mapped to Internal.
+func GetMCMErrorCode(err error) codes.Code {
+	if errors.Is(err, ErrClientRateLimited) {
+		return codes.Unavailable
+	}
+
+	var aliErr *tea.SDKError
+	if errors.As(err, &aliErr) && aliErr.Code != nil {
+		if code, ok := mcmErrorCodes[*aliErr.Code]; ok {
+			return code
+		}
+		if prefix, _, found := strings.Cut(*aliErr.Code, "."); found {
+			if code, ok := mcmErrorCodePrefixes[prefix]; ok {
+				return code
+			}
+		}
+		return codes.Internal
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
 // GetMCMErrorCodeForCreateMachine takes the error returned from the EC2API during the CreateMachine call and returns the corresponding MCM error code.
 func GetMCMErrorCodeForCreateMachine(err error) codes.Code {
 	var aliErr *tea.SDKError
 	ok := errors.As(err, &aliErr)
-	if ok {
+	if ok && aliErr.Code != nil {
 		switch *aliErr.Code {
 		case QuotaExceededDiskCapacity,
 			QuotaExceededElasticQuota,
@@ -31,9 +106,94 @@ func GetMCMErrorCodeForCreateMachine(err error) codes.Code {
 			InvalidZoneIDNotSupportShareEncryptedImage,
 			ResourceNotAvailable:
 			return codes.ResourceExhausted
-		default:
-			return codes.Internal
 		}
 	}
-	return codes.Internal
+	return GetMCMErrorCode(err)
+}
+
+// GetErrorMessage returns a single line message for the error returned from an Alicloud API. Errors of the Alicloud
+// API are described by their error code, message and the ID of the request, which Alibaba Cloud support asks for.
+func GetErrorMessage(err error) string {
+	var aliErr *tea.SDKError
+	if !errors.As(err, &aliErr) {
+		return err.Error()
+	}
+
+	data := getErrorData(aliErr)
+	if data.Message == "" {
+		data.Message = tea.StringValue(aliErr.Message)
+	}
+
+	message := fmt.Sprintf("%s: %s", tea.StringValue(aliErr.Code), data.Message)
+	if data.RequestID != "" {
+		message = fmt.Sprintf("%s (request ID: %s)", message, data.RequestID)
+	}
+	return message
+}
+
+// GetRequestID returns the ID of the request, which failed with the given error returned from an Alicloud API, or an
+// empty string if the error does not carry one.
+func GetRequestID(err error) string {
+	var aliErr *tea.SDKError
+	if !errors.As(err, &aliErr) {
+		return ""
+	}
+	return getErrorData(aliErr).RequestID
+}
+
+// errorData is the body of an error response of an Alicloud API
+type errorData struct {
+	Message   string `json:"Message"`
+	RequestID string `json:"RequestId"`
+}
+
+func getErrorData(aliErr *tea.SDKError) errorData {
+	var data errorData
+	if aliErr.Data != nil {
+		_ = json.Unmarshal([]byte(*aliErr.Data), &data)
+	}
+	return data
+}
+
+// ToMCMError converts the error returned from an Alicloud API into an MCM status error. Its code is determined by
+// GetMCMErrorCode, its message describes the failed action and the Alicloud error.
+func ToMCMError(err error, action string) error {
+	return status.Error(GetMCMErrorCode(err), fmt.Sprintf("%s: %s", action, GetErrorMessage(err)))
+}
+
+// HasErrorCode returns true if the given error has been returned by an Alicloud API with one of the given error codes.
+func HasErrorCode(err error, errorCodes ...string) bool {
+	var aliErr *tea.SDKError
+	if !errors.As(err, &aliErr) || aliErr.Code == nil {
+		return false
+	}
+
+	for _, errorCode := range errorCodes {
+		if *aliErr.Code == errorCode {
+			return true
+		}
+	}
+	return false
+}
+
+// IsInstanceTypeUnavailable returns true if the given error returned from RunInstances reports that the instance type
+// is sold out or not offered in the zone, i.e. if ECS may have capacity for another instance type.
+func IsInstanceTypeUnavailable(err error) bool {
+	return HasErrorCode(err, OperationDeniedNoStock, InvalidInstanceTypeZoneNotSupported, InvalidResourceTypeNotSupported, ResourceNotAvailable)
+}
+
+// IsTransient returns true if the given error returned from an Alicloud API is caused by request throttling, a server
+// side failure or a network failure, i.e. if it is worth retrying the request.
+func IsTransient(err error) bool {
+	var aliErr *tea.SDKError
+	if errors.As(err, &aliErr) {
+		if aliErr.StatusCode != nil && *aliErr.StatusCode >= http.StatusInternalServerError {
+			return true
+		}
+		code := tea.StringValue(aliErr.Code)
+		return code == ServiceUnavailable || code == Throttling || strings.HasPrefix(code, Throttling+".")
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
 }
@@ -5,10 +5,13 @@
 package errors
 
 import (
+	"fmt"
 	"github.com/alibabacloud-go/tea/tea"
+	"net"
 	"testing"
 
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
 	. "github.com/onsi/gomega"
 )
 
@@ -48,3 +51,127 @@ func TestCreateMachineErrorToMCMErrorCode(t *testing.T) {
 		}))).To(Equal(entry.expectedCode))
 	}
 }
+
+func TestHasErrorCode(t *testing.T) {
+	g := NewWithT(t)
+	err := tea.NewSDKError(map[string]any{
+		"statusCode": 403,
+		"code":       IncorrectInstanceStatus,
+		"message":    "The current status of the resource does not support this operation.",
+	})
+
+	g.Expect(HasErrorCode(err, InvalidInstanceIDNotFound, IncorrectInstanceStatus)).To(BeTrue())
+	g.Expect(HasErrorCode(fmt.Errorf("wrapped: %w", err), IncorrectInstanceStatus)).To(BeTrue())
+	g.Expect(HasErrorCode(err, InvalidInstanceIDNotFound)).To(BeFalse())
+	g.Expect(HasErrorCode(fmt.Errorf("no sdk error"), IncorrectInstanceStatus)).To(BeFalse())
+}
+
+func TestErrorToMCMErrorCode(t *testing.T) {
+	table := []input{
+		{inputAliErrorCode: ThrottlingUser, expectedCode: codes.Unavailable},
+		{inputAliErrorCode: "Throttling.Api", expectedCode: codes.Unavailable},
+		{inputAliErrorCode: ServiceUnavailable, expectedCode: codes.Unavailable},
+		{inputAliErrorCode: IncorrectInstanceStatus, expectedCode: codes.Unavailable},
+		{inputAliErrorCode: InvalidAccessKeyIDNotFound, expectedCode: codes.Unauthenticated},
+		{inputAliErrorCode: SignatureDoesNotMatch, expectedCode: codes.Unauthenticated},
+		{inputAliErrorCode: ForbiddenRAM, expectedCode: codes.PermissionDenied},
+		{inputAliErrorCode: InvalidInstanceIDNotFound, expectedCode: codes.NotFound},
+		{inputAliErrorCode: InvalidParameter, expectedCode: codes.InvalidArgument},
+		{inputAliErrorCode: "InvalidParameter.Conflict", expectedCode: codes.InvalidArgument},
+		{inputAliErrorCode: "MissingParameter.RegionId", expectedCode: codes.InvalidArgument},
+		{inputAliErrorCode: InvalidVSwitchIDNotFound, expectedCode: codes.InvalidArgument},
+		{inputAliErrorCode: InvalidVSwitchIDIPv6NotTurnOn, expectedCode: codes.InvalidArgument},
+		{inputAliErrorCode: "InternalError", expectedCode: codes.Internal},
+		// stock errors are only worth retrying elsewhere when creating a machine
+		{inputAliErrorCode: OperationDeniedNoStock, expectedCode: codes.Internal},
+	}
+	g := NewWithT(t)
+	for _, entry := range table {
+		g.Expect(GetMCMErrorCode(tea.NewSDKError(map[string]any{
+			"statusCode": 400,
+			"code":       entry.inputAliErrorCode,
+			"message":    "some error happened on the server side",
+		}))).To(Equal(entry.expectedCode), entry.inputAliErrorCode)
+	}
+
+	g.Expect(GetMCMErrorCodeForCreateMachine(tea.NewSDKError(map[string]any{
+		"code":    ThrottlingUser,
+		"message": "Request was denied due to user flow control.",
+	}))).To(Equal(codes.Unavailable))
+	g.Expect(GetMCMErrorCode(&net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")})).To(Equal(codes.Unavailable))
+	g.Expect(GetMCMErrorCode(fmt.Errorf("%w: rate: Wait(n=1) would exceed context deadline", ErrClientRateLimited))).To(Equal(codes.Unavailable))
+	g.Expect(GetMCMErrorCode(fmt.Errorf("invalid response"))).To(Equal(codes.Internal))
+}
+
+func TestToMCMError(t *testing.T) {
+	g := NewWithT(t)
+	err := ToMCMError(tea.NewSDKError(map[string]any{
+		"code":    ForbiddenRAM,
+		"message": "code: 403, User not authorized to operate on the specified resource. request id: 6D2F0B1A-5E3C-4B7A-9F21-3C8E4D5A6B7C",
+		"data": map[string]any{
+			"Code":      ForbiddenRAM,
+			"Message":   "User not authorized to operate on the specified resource.",
+			"RequestId": "6D2F0B1A-5E3C-4B7A-9F21-3C8E4D5A6B7C",
+		},
+	}), `failed to delete ECS instance "i-1"`)
+
+	mcmErr, ok := status.FromError(err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(mcmErr.Code()).To(Equal(codes.PermissionDenied))
+	g.Expect(mcmErr.Message()).To(Equal(`failed to delete ECS instance "i-1": Forbidden.RAM: User not authorized to operate on the specified resource. (request ID: 6D2F0B1A-5E3C-4B7A-9F21-3C8E4D5A6B7C)`))
+
+	g.Expect(GetErrorMessage(fmt.Errorf("invalid response"))).To(Equal("invalid response"))
+}
+
+func TestGetRequestID(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(GetRequestID(tea.NewSDKError(map[string]any{
+		"code":    ThrottlingUser,
+		"message": "Request was denied due to user flow control.",
+		"data":    map[string]any{"RequestId": "6D2F0B1A-5E3C-4B7A-9F21-3C8E4D5A6B7C"},
+	}))).To(Equal("6D2F0B1A-5E3C-4B7A-9F21-3C8E4D5A6B7C"))
+	g.Expect(GetRequestID(tea.NewSDKError(map[string]any{
+		"code":    ThrottlingUser,
+		"message": "Request was denied due to user flow control.",
+	}))).To(BeEmpty())
+	g.Expect(GetRequestID(fmt.Errorf("invalid response"))).To(BeEmpty())
+}
+
+func TestIsTransient(t *testing.T) {
+	g := NewWithT(t)
+	newSDKError := func(statusCode int, code string) error {
+		return tea.NewSDKError(map[string]any{
+			"code":    code,
+			"message": "some error happened on the server side",
+			"data":    map[string]any{"statusCode": statusCode},
+		})
+	}
+
+	g.Expect(IsTransient(newSDKError(400, ThrottlingUser))).To(BeTrue())
+	g.Expect(IsTransient(newSDKError(400, "Throttling.Api"))).To(BeTrue())
+	g.Expect(IsTransient(newSDKError(503, ServiceUnavailable))).To(BeTrue())
+	g.Expect(IsTransient(newSDKError(500, "InternalError"))).To(BeTrue())
+	g.Expect(IsTransient(&net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")})).To(BeTrue())
+	g.Expect(IsTransient(newSDKError(403, ForbiddenRAM))).To(BeFalse())
+	g.Expect(IsTransient(newSDKError(404, InvalidInstanceIDNotFound))).To(BeFalse())
+	g.Expect(IsTransient(fmt.Errorf("invalid response"))).To(BeFalse())
+}
+
+func TestIsInstanceTypeUnavailable(t *testing.T) {
+	g := NewWithT(t)
+	newSDKError := func(code string) error {
+		return tea.NewSDKError(map[string]any{
+			"code":    code,
+			"message": "the resource is not available",
+			"data":    map[string]any{"statusCode": 403},
+		})
+	}
+
+	g.Expect(IsInstanceTypeUnavailable(newSDKError(OperationDeniedNoStock))).To(BeTrue())
+	g.Expect(IsInstanceTypeUnavailable(newSDKError(InvalidInstanceTypeZoneNotSupported))).To(BeTrue())
+	g.Expect(IsInstanceTypeUnavailable(newSDKError(InvalidResourceTypeNotSupported))).To(BeTrue())
+	g.Expect(IsInstanceTypeUnavailable(newSDKError(ResourceNotAvailable))).To(BeTrue())
+	g.Expect(IsInstanceTypeUnavailable(newSDKError(QuotaExceededElasticQuota))).To(BeFalse())
+	g.Expect(IsInstanceTypeUnavailable(newSDKError(ZoneNotOnSale))).To(BeFalse())
+	g.Expect(IsInstanceTypeUnavailable(fmt.Errorf("invalid response"))).To(BeFalse())
+}
@@ -8,12 +8,16 @@ package alicloud
 import (
 	"context"
 	"fmt"
+	"time"
 
 	maperror "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/errors"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/metrics"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/tracing"
 	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/driver"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 )
 
@@ -52,10 +56,13 @@ import (
 // It is optionally expected by the safety controller to use an identification mechanisms to map the VM Created by a providerSpec.
 // These could be done using tag(s)/resource-groups etc.
 // This logic is used by safety controller to delete orphan VMs which are not backed by any machine CRD
-func (plugin *MachinePlugin) CreateMachine(_ context.Context, req *driver.CreateMachineRequest) (*driver.CreateMachineResponse, error) {
+func (plugin *MachinePlugin) CreateMachine(ctx context.Context, req *driver.CreateMachineRequest) (_ *driver.CreateMachineResponse, err error) {
 	// Log messages to track request
 	klog.V(2).Infof("Machine creation request has been received for %q", req.Machine.Name)
 	defer klog.V(2).Infof("Machine creation request has been processed for %q", req.Machine.Name)
+	defer metrics.ObserveDriverRequest("CreateMachine", time.Now(), &err)
+	ctx, span := tracing.Start(ctx, "CreateMachine", tracing.MachineNameKey.String(req.Machine.Name))
+	defer tracing.EndDriverSpan(span, &err)
 
 	// Check if provider in the MachineClass is the provider we support
 	if req.MachineClass.Provider != ProviderAlicloud {
@@ -63,24 +70,67 @@ func (plugin *MachinePlugin) CreateMachine(_ context.Context, req *driver.Create
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	providerSpec, err := decodeProviderSpec(req.MachineClass)
+	providerSpec, err := decodeProviderSpecAndSecret(req.MachineClass, req.Secret)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, err
+	}
+	if err := validateUserData(req.MachineClass, req.Secret); err != nil {
+		return nil, err
 	}
 
-	client, err := plugin.SPI.NewECSClient(req.Secret, providerSpec.Region)
+	client, err := plugin.newECSClient(ctx, req.Secret, providerSpec.Region)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	request, err := plugin.SPI.NewRunInstancesRequest(providerSpec, req.Machine.Name, req.Secret.Data[spi.AlicloudUserData])
+	// A previous CreateMachine call may have launched the instance without MCM receiving the response (e.g. due to a
+	// timeout). Adopt such an instance instead of launching another one for the same machine.
+	instance, err := plugin.getExistingInstance(client, req.Machine.Name, req.MachineClass.Name, providerSpec)
+	if err != nil {
+		return nil, err
+	}
+	if instance != nil {
+		klog.V(2).Infof("ECS instance %q already exists for machine %q, adopting it", *instance.InstanceId, req.Machine.Name)
+		return &driver.CreateMachineResponse{
+			ProviderID:     encodeProviderID(providerSpec.Region, *instance.InstanceId),
+			NodeName:       instanceIDToName(*instance.InstanceId),
+			LastKnownState: withIPv6Addresses(fmt.Sprintf("ECS instance %s adopted for machine %s", *instance.InstanceId, req.Machine.Name), instance),
+		}, nil
+	}
+
+	// ECS rejects IPv6 addresses of vSwitches without IPv6 CIDR block without naming the network interface
+	if err := plugin.validateIPv6VSwitches(ctx, req.Secret, providerSpec, req.MachineClass.Name); err != nil {
+		return nil, err
+	}
+
+	// the ownership tags allow ListMachines to tell apart the instances of MachineClasses sharing cluster and role
+	providerSpec.Tags = withOwnershipTags(providerSpec.Tags, req.MachineClass.Name, req.Machine.Name)
+
+	userData := req.Secret.Data[spi.AlicloudUserData]
+	clientToken := newClientToken(req.Machine, req.MachineClass)
+	request, err := plugin.SPI.NewRunInstancesRequest(providerSpec, req.Machine.Name, clientToken, userData)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	response, err := client.RunInstances(request)
+	response, instanceType, err := runInstanceOfAnyType(client, request, providerSpec.InstanceTypes, req.Machine.Name)
+	fellBackToOnDemand := false
+	if err != nil && plugin.shouldFallBackToOnDemand(client, providerSpec, req.MachineClass.Name, err) {
+		klog.V(2).Infof("No spot instance in stock for machine %q, falling back to a pay-as-you-go instance", req.Machine.Name)
+		onDemandSpec := onDemandProviderSpec(providerSpec)
+		request, err = plugin.SPI.NewRunInstancesRequest(onDemandSpec, req.Machine.Name, fallbackClientToken(clientToken, onDemandSpec.SpotStrategy), userData)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		response, instanceType, err = runInstanceOfAnyType(client, request, providerSpec.InstanceTypes, req.Machine.Name)
+		fellBackToOnDemand = true
+	}
 	if err != nil {
-		return nil, status.Error(maperror.GetMCMErrorCodeForCreateMachine(err), err.Error())
+		errMessage := fmt.Sprintf("failed to run ECS instance for machine %q: %s", req.Machine.Name, maperror.GetErrorMessage(err))
+		if len(providerSpec.InstanceTypes) > 1 {
+			errMessage = fmt.Sprintf("failed to run ECS instance of instance type %s for machine %q: %s", instanceType, req.Machine.Name, maperror.GetErrorMessage(err))
+		}
+		return nil, status.Error(maperror.GetMCMErrorCodeForCreateMachine(err), errMessage)
 	}
 
 	instanceID, err := GetInstanceIDFromRunInstancesResponse(response)
@@ -91,16 +141,128 @@ func (plugin *MachinePlugin) CreateMachine(_ context.Context, req *driver.Create
 
 	klog.V(2).Infof("ECS instance %q created for machine %q", *instanceID, req.Machine.Name)
 
+	lastKnownState := fmt.Sprintf("ECS instance %s created for machine %s", *instanceID, req.Machine.Name)
+	if len(providerSpec.InstanceTypes) > 1 {
+		lastKnownState += " with instance type " + instanceType
+	}
+	if fellBackToOnDemand {
+		lastKnownState += " as pay-as-you-go instance, as no spot instance was in stock"
+	}
+	if requestsIPv6Addresses(providerSpec) {
+		// RunInstances does not return the assigned addresses, they are reported on a best effort basis
+		lastKnownState = plugin.withAssignedIPv6Addresses(client, lastKnownState, *instanceID, providerSpec)
+	}
+
 	return &driver.CreateMachineResponse{
 		ProviderID:     encodeProviderID(providerSpec.Region, *instanceID),
 		NodeName:       instanceIDToName(*instanceID),
-		LastKnownState: fmt.Sprintf("ECS instance %s created for machine %s", *instanceID, req.Machine.Name),
+		LastKnownState: lastKnownState,
 	}, nil
 }
 
-// InitializeMachine handles VM initialization for Alibaba Cloud VM's. Currently, un-implemented.
-func (plugin *MachinePlugin) InitializeMachine(_ context.Context, _ *driver.InitializeMachineRequest) (*driver.InitializeMachineResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "Alibaba Cloud Provider does not yet implement InitializeMachine")
+// InitializeMachine handles VM initialization for Alibaba Cloud VM's.
+//
+// REQUEST PARAMETERS (driver.InitializeMachineRequest)
+// Machine               *v1alpha1.Machine        Machine object whose VM is to be initialized
+// MachineClass          *v1alpha1.MachineClass   MachineClass backing the machine object
+// Secret                *corev1.Secret           Kubernetes secret that contains any sensitive data/credentials
+//
+// RESPONSE PARAMETERS (driver.InitializeMachineResponse)
+// ProviderID            string                   Unique identification of the VM at the cloud provider.
+// NodeName              string                   Name of the node-object that the VM registers with Kubernetes.
+// Addresses             []corev1.NodeAddress     Addresses to reach the VM.
+//
+// It waits for the ECS instance created by CreateMachine to reach the Running state and for its primary network
+// interface to have a private IP, adds tags which are missing on the instance and assigns the IPv6 addresses of the
+// secondary network interfaces. The instance is only polled briefly, Uninitialized is returned if it is not ready yet,
+// so that MCM requeues the initialization instead of blocking a worker. The IPv6 addresses of all network interfaces
+// are returned as addresses of the VM, which MCM records in the status of the machine.
+func (plugin *MachinePlugin) InitializeMachine(ctx context.Context, req *driver.InitializeMachineRequest) (_ *driver.InitializeMachineResponse, err error) {
+	// Log messages to track request
+	klog.V(2).Infof("Machine initialization request has been received for %q", req.Machine.Name)
+	defer klog.V(2).Infof("Machine initialization request has been processed for %q", req.Machine.Name)
+	defer metrics.ObserveDriverRequest("InitializeMachine", time.Now(), &err)
+	ctx, span := tracing.Start(ctx, "InitializeMachine", tracing.MachineNameKey.String(req.Machine.Name))
+	defer tracing.EndDriverSpan(span, &err)
+
+	// Check if provider in the MachineClass is the provider we support
+	if req.MachineClass.Provider != ProviderAlicloud {
+		err := fmt.Errorf("requested for Provider '%s', we only support '%s'", req.MachineClass.Provider, ProviderAlicloud)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	providerSpec, err := decodeProviderSpecAndSecret(req.MachineClass, req.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := plugin.newECSClient(ctx, req.Secret, providerSpec.Region)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	instance, err := plugin.waitForInstanceRunning(ctx, client, req.Machine, providerSpec)
+	if err != nil {
+		return nil, err
+	}
+	instanceID := *instance.InstanceId
+
+	privateIP := GetInstancePrivateIP(instance)
+	if privateIP == "" {
+		errMessage := fmt.Sprintf("primary network interface of ECS instance %q has no private IP address yet", instanceID)
+		return nil, status.Error(codes.Uninitialized, errMessage)
+	}
+
+	tags := withOwnershipTags(providerSpec.Tags, req.MachineClass.Name, req.Machine.Name)
+	if missingTags := GetMissingInstanceTags(instance, tags); len(missingTags) > 0 {
+		tagResourcesRequest, err := plugin.SPI.NewTagResourcesRequest(instanceID, providerSpec.Region, missingTags)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if _, err := client.TagResources(tagResourcesRequest); err != nil {
+			return nil, maperror.ToMCMError(err, fmt.Sprintf("failed to tag ECS instance %q", instanceID))
+		}
+		klog.V(3).Infof("Added %d missing tag(s) to ECS instance %q", len(missingTags), instanceID)
+	}
+
+	// ECS tags only the primary network interface on launch, DeleteMachine looks up leaked secondary network
+	// interfaces by their cluster tags
+	if networkInterfaceIDs := GetSecondaryNetworkInterfaceIDs(instance); len(networkInterfaceIDs) > 0 {
+		tagNetworkInterfacesRequest, err := plugin.SPI.NewTagNetworkInterfacesRequest(networkInterfaceIDs, providerSpec.Region, providerSpec.Tags)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if _, err := client.TagResources(tagNetworkInterfacesRequest); err != nil {
+			return nil, maperror.ToMCMError(err, fmt.Sprintf("failed to tag network interfaces %v of ECS instance %q", networkInterfaceIDs, instanceID))
+		}
+	}
+
+	// ECS assigns IPv6 addresses at launch to the primary network interface only
+	assignedIPv6Addresses, err := plugin.assignSecondaryIPv6Addresses(client, req.Machine.Name, providerSpec, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, hook := range plugin.InitializationHooks {
+		if err := hook(ctx, req, providerSpec, instance); err != nil {
+			return nil, maperror.ToMCMError(err, fmt.Sprintf("initialization hook %d failed for ECS instance %q", i, instanceID))
+		}
+	}
+
+	klog.V(2).Infof("ECS instance %q initialized for machine %q", instanceID, req.Machine.Name)
+
+	nodeName := instanceIDToName(instanceID)
+	addresses := []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: privateIP}}
+	for _, ipv6Address := range append(GetInstanceIPv6Addresses(instance, ""), assignedIPv6Addresses...) {
+		addresses = append(addresses, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: ipv6Address})
+	}
+	addresses = append(addresses, corev1.NodeAddress{Type: corev1.NodeHostName, Address: nodeName})
+
+	return &driver.InitializeMachineResponse{
+		ProviderID: encodeProviderID(providerSpec.Region, instanceID),
+		NodeName:   nodeName,
+		Addresses:  addresses,
+	}, nil
 }
 
 // DeleteMachine handles a machine deletion request
@@ -114,10 +276,13 @@ func (plugin *MachinePlugin) InitializeMachine(_ context.Context, _ *driver.Init
 // LastKnownState        bytes(blob)              (Optional) Last known state of VM during the current operation.
 //
 //	Could be helpful to continue operations in future requests.
-func (plugin *MachinePlugin) DeleteMachine(_ context.Context, req *driver.DeleteMachineRequest) (*driver.DeleteMachineResponse, error) {
+func (plugin *MachinePlugin) DeleteMachine(ctx context.Context, req *driver.DeleteMachineRequest) (_ *driver.DeleteMachineResponse, err error) {
 	// Log messages to track delete request
 	klog.V(2).Infof("Machine deletion request has been received for %q", req.Machine.Name)
 	defer klog.V(2).Infof("Machine deletion request has been processed for %q", req.Machine.Name)
+	defer metrics.ObserveDriverRequest("DeleteMachine", time.Now(), &err)
+	ctx, span := tracing.Start(ctx, "DeleteMachine", tracing.MachineNameKey.String(req.Machine.Name))
+	defer tracing.EndDriverSpan(span, &err)
 
 	// Check if provider in the MachineClass is the provider we support
 	if req.MachineClass.Provider != ProviderAlicloud {
@@ -125,90 +290,71 @@ func (plugin *MachinePlugin) DeleteMachine(_ context.Context, req *driver.Delete
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	providerSpec, err := decodeProviderSpec(req.MachineClass)
+	providerSpec, err := decodeProviderSpecAndSecret(req.MachineClass, req.Secret)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, err
 	}
 
-	client, err := plugin.SPI.NewECSClient(req.Secret, providerSpec.Region)
+	client, err := plugin.newECSClient(ctx, req.Secret, providerSpec.Region)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	lastKnownState := ""
+	var (
+		lastKnownState      string
+		machineName         string
+		instanceID          string
+		networkInterfaceIDs []string
+	)
 
 	if req.Machine.Spec.ProviderID != "" {
-		instanceID := decodeProviderID(req.Machine.Spec.ProviderID)
-		describeInstanceRequest, err := plugin.SPI.NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags)
-		if err != nil {
-			return nil, status.Error(codes.Internal, err.Error())
-		}
-
-		instances, err := plugin.GetAllInstances(client, describeInstanceRequest)
-		if err != nil {
-			klog.Errorf("error while fetching instance details for instanceID %q: %v", instanceID, err)
-			return nil, status.Error(codes.Internal, err.Error())
-		}
-		klog.V(3).Infof("Total %d instance(s) found for instanceID %q", len(instances), instanceID)
-		if len(instances) == 0 {
-			// No running instance exists with the given machineID
-			errMessage := fmt.Sprintf("ECS instance not found backing this machine object with Provider ID: %v", req.Machine.Spec.ProviderID)
-			klog.Error(errMessage)
+		instanceID = decodeProviderID(req.Machine.Spec.ProviderID)
+	} else {
+		klog.V(2).Infof("No provider ID set for machine %q. Checking if backing ECS instance is present.", req.Machine.Name)
+		machineName = req.Machine.Name
+	}
 
-			return nil, status.Error(codes.NotFound, errMessage)
-		}
+	describeInstanceRequest, err := plugin.SPI.NewDescribeInstancesRequest(machineName, instanceID, providerSpec.Region, providerSpec.Tags)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
 
-		if *instances[0].Status != "Running" && *instances[0].Status != "Stopped" {
-			return nil, status.Error(codes.Unavailable, "ECS instance not in running/stopped state")
-		}
+	instances, err := plugin.GetAllInstances(client, describeInstanceRequest)
+	if err != nil {
+		klog.Errorf("error while fetching instance details for machine object %q: %v", req.Machine.Name, err)
+		return nil, maperror.ToMCMError(err, fmt.Sprintf("failed to fetch ECS instances of machine %q", req.Machine.Name))
+	}
+	klog.V(3).Infof("Total %d instance(s) found for machine %q", len(instances), req.Machine.Name)
 
-		deleteInstanceRequest, err := plugin.SPI.NewDeleteInstanceRequest(instanceID, true)
-		if err != nil {
-			return nil, status.Error(codes.Internal, err.Error())
-		}
-		_, err = client.DeleteInstance(deleteInstanceRequest)
-		if err != nil {
-			return nil, status.Error(codes.Internal, err.Error())
-		}
-		lastKnownState = fmt.Sprintf("ECS instance %s deleted for machine %s", instanceID, req.Machine.Name)
-	} else {
-		klog.V(2).Infof("No provider ID set for machine %q. Checking if backing ECS instance is present.", req.Machine.Name)
-		describeInstanceRequest, err := plugin.SPI.NewDescribeInstancesRequest(req.Machine.Name, "", providerSpec.Region, providerSpec.Tags)
-		if err != nil {
-			return nil, status.Error(codes.Internal, err.Error())
+	if len(instances) > 0 {
+		instanceIDs := make([]string, 0, len(instances))
+		for _, instance := range instances {
+			instanceIDs = append(instanceIDs, *instance.InstanceId)
+			// secondary network interfaces are detached but not necessarily released together with the instance
+			networkInterfaceIDs = append(networkInterfaceIDs, GetSecondaryNetworkInterfaceIDs(instance)...)
 		}
 
-		instances, err := plugin.GetAllInstances(client, describeInstanceRequest)
-		if err != nil {
-			klog.Errorf("error while fetching instance details for machine object %q: %v", req.Machine.Name, err)
-			return nil, status.Error(codes.Internal, err.Error())
-		}
-		klog.V(3).Infof("Total %d instance(s) found for machine %q", len(instances), req.Machine.Name)
-		if len(instances) == 0 {
-			// No running instance exists with the given machineName
-			klog.V(2).Infof("No backing ECS instance found. Termination successful for machine object %q", req.Machine.Name)
-			return &driver.DeleteMachineResponse{}, nil
+		if err := plugin.releaseInstances(ctx, client, req.Machine.Name, providerSpec, instances); err != nil {
+			return &driver.DeleteMachineResponse{
+				LastKnownState: fmt.Sprintf("Deletion of ECS instance(s) %v for machine %s in progress", instanceIDs, req.Machine.Name),
+			}, err
 		}
 
-		var deletedInstances = make([]string, 0, len(instances))
-		for _, instance := range instances {
-			deleteInstanceRequest, err := plugin.SPI.NewDeleteInstanceRequest(*instance.InstanceId, true)
-			if err != nil {
-				return nil, status.Error(codes.Internal, err.Error())
-			}
-			_, err = client.DeleteInstance(deleteInstanceRequest)
-			if err != nil {
-				return nil, status.Error(codes.Internal, err.Error())
-			}
-			klog.V(3).Infof("ECS instance %q deleted for machine %q", *instance.InstanceId, *instance.InstanceName)
-			deletedInstances = append(deletedInstances, *instance.InstanceId)
+		if instanceID != "" {
+			lastKnownState = fmt.Sprintf("ECS instance %s deleted for machine %s", instanceID, req.Machine.Name)
+		} else {
+			lastKnownState = fmt.Sprintf("ECS instance(s) %v deleted for machine %s", instanceIDs, req.Machine.Name)
 		}
-		lastKnownState = fmt.Sprintf("ECS instance(s) %v deleted for machine %s", deletedInstances, req.Machine.Name)
+	} else {
+		// The instance has already been released, possibly by an earlier attempt which left data disks behind
+		klog.V(2).Infof("No backing ECS instance found for machine object %q", req.Machine.Name)
 	}
 
+	// the last known state is reported even if the cleanup fails, so that the next attempt knows what happened
+	deleted, err := plugin.deleteLeakedResources(ctx, client, req.Machine.Name, providerSpec, networkInterfaceIDs)
 	return &driver.DeleteMachineResponse{
-		LastKnownState: lastKnownState,
-	}, nil
+		LastKnownState: withLeakedResources(lastKnownState, deleted),
+	}, err
 }
 
 // GetMachineStatus handles a machine get status request
@@ -230,10 +376,13 @@ func (plugin *MachinePlugin) DeleteMachine(_ context.Context, req *driver.Delete
 //	This could be different from req.MachineName as well
 //
 // The request should return a NOT_FOUND (5) status error code if the machine is not existing
-func (plugin *MachinePlugin) GetMachineStatus(_ context.Context, req *driver.GetMachineStatusRequest) (*driver.GetMachineStatusResponse, error) {
+func (plugin *MachinePlugin) GetMachineStatus(ctx context.Context, req *driver.GetMachineStatusRequest) (_ *driver.GetMachineStatusResponse, err error) {
 	// Log messages to track start and end of request
 	klog.V(2).Infof("Get request has been received for %q", req.Machine.Name)
 	defer klog.V(2).Infof("Machine get request has been processed successfully for %q", req.Machine.Name)
+	defer metrics.ObserveDriverRequest("GetMachineStatus", time.Now(), &err)
+	ctx, span := tracing.Start(ctx, "GetMachineStatus", tracing.MachineNameKey.String(req.Machine.Name))
+	defer tracing.EndDriverSpan(span, &err)
 
 	// Check if provider in the MachineClass is the provider we support
 	if req.MachineClass.Provider != ProviderAlicloud {
@@ -242,17 +391,25 @@ func (plugin *MachinePlugin) GetMachineStatus(_ context.Context, req *driver.Get
 	}
 
 	klog.V(2).Infof("Machine name found with %q", req.Machine.Name)
-	providerSpec, err := decodeProviderSpec(req.MachineClass)
+	providerSpec, err := decodeProviderSpecAndSecret(req.MachineClass, req.Secret)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, err
 	}
 
-	client, err := plugin.SPI.NewECSClient(req.Secret, providerSpec.Region)
+	client, err := plugin.newECSClient(ctx, req.Secret, providerSpec.Region)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	request, err := plugin.SPI.NewDescribeInstancesRequest(req.Machine.Name, "", providerSpec.Region, providerSpec.Tags)
+	// the instance ID is preferred over the instance name, which can be changed
+	var machineName, instanceID string
+	if req.Machine.Spec.ProviderID != "" {
+		instanceID = decodeProviderID(req.Machine.Spec.ProviderID)
+	} else {
+		machineName = req.Machine.Name
+	}
+
+	request, err := plugin.SPI.NewDescribeInstancesRequest(machineName, instanceID, providerSpec.Region, providerSpec.Tags)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -260,7 +417,20 @@ func (plugin *MachinePlugin) GetMachineStatus(_ context.Context, req *driver.Get
 	instances, err := plugin.GetAllInstances(client, request)
 	if err != nil {
 		klog.Errorf("error while fetching instance details for machines: %v", err)
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, maperror.ToMCMError(err, fmt.Sprintf("failed to fetch ECS instance of machine %q", req.Machine.Name))
+	}
+	if len(instances) == 0 && instanceID != "" {
+		// the ProviderID may not match the instance anymore, e.g. if it was set by a failed earlier attempt
+		klog.V(2).Infof("No ECS instance %q found for machine %q, looking it up by name", instanceID, req.Machine.Name)
+		request, err = plugin.SPI.NewDescribeInstancesRequest(req.Machine.Name, "", providerSpec.Region, providerSpec.Tags)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		instances, err = plugin.GetAllInstances(client, request)
+		if err != nil {
+			klog.Errorf("error while fetching instance details for machines: %v", err)
+			return nil, maperror.ToMCMError(err, fmt.Sprintf("failed to fetch ECS instance of machine %q", req.Machine.Name))
+		}
 	}
 	klog.V(3).Infof("Total %d instance(s) found for listing machines for machine class %q", len(instances), req.MachineClass.Name)
 	if len(instances) == 0 {
@@ -278,11 +448,19 @@ func (plugin *MachinePlugin) GetMachineStatus(_ context.Context, req *driver.Get
 		return nil, status.Error(codes.OutOfRange, errMessage)
 	}
 
-	klog.V(3).Infof("Machine get request has been processed successfully for %q", req.Machine.Name)
-	return &driver.GetMachineStatusResponse{
+	response := &driver.GetMachineStatusResponse{
 		NodeName:   instanceIDToName(*instances[0].InstanceId),
 		ProviderID: encodeProviderID(providerSpec.Region, *instances[0].InstanceId),
-	}, nil
+	}
+
+	if err := instanceStatusError(instances[0], req.Machine.Name); err != nil {
+		klog.V(2).Infof("Machine %q is not healthy: %v", req.Machine.Name, err)
+		return response, err
+	}
+	logUnhealthyInstance(instances[0], req.Machine.Name)
+
+	klog.V(3).Infof("Machine get request has been processed successfully for %q", req.Machine.Name)
+	return response, nil
 }
 
 // ListMachines lists all the machines possibly created by a providerSpec
@@ -298,10 +476,13 @@ func (plugin *MachinePlugin) GetMachineStatus(_ context.Context, req *driver.Get
 // MachineList           map<string,string>  A map containing the keys as the MachineID and value as the MachineName
 //
 //	for all machines which were possibly created by this ProviderSpec
-func (plugin *MachinePlugin) ListMachines(_ context.Context, req *driver.ListMachinesRequest) (*driver.ListMachinesResponse, error) {
+func (plugin *MachinePlugin) ListMachines(ctx context.Context, req *driver.ListMachinesRequest) (_ *driver.ListMachinesResponse, err error) {
 	// Log messages to track start and end of request
 	klog.V(2).Infof("List machines request has been received for %q", req.MachineClass.Name)
 	defer klog.V(2).Infof("List machines request has been received for %q", req.MachineClass.Name)
+	defer metrics.ObserveDriverRequest("ListMachines", time.Now(), &err)
+	ctx, span := tracing.Start(ctx, "ListMachines")
+	defer tracing.EndDriverSpan(span, &err)
 
 	// Check if provider in the MachineClass is the provider we support
 	if req.MachineClass.Provider != ProviderAlicloud {
@@ -309,12 +490,12 @@ func (plugin *MachinePlugin) ListMachines(_ context.Context, req *driver.ListMac
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	providerSpec, err := decodeProviderSpec(req.MachineClass)
+	providerSpec, err := decodeProviderSpecAndSecret(req.MachineClass, req.Secret)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, err
 	}
 
-	client, err := plugin.SPI.NewECSClient(req.Secret, providerSpec.Region)
+	client, err := plugin.newECSClient(ctx, req.Secret, providerSpec.Region)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -327,12 +508,23 @@ func (plugin *MachinePlugin) ListMachines(_ context.Context, req *driver.ListMac
 	instances, err := plugin.GetAllInstances(client, request)
 	if err != nil {
 		klog.Errorf("error while fetching instance details for machines: %v", err)
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, maperror.ToMCMError(err, fmt.Sprintf("failed to fetch ECS instances of machine class %q", req.MachineClass.Name))
 	}
 	klog.V(3).Infof("Total %d instance(s) found for listing machines for machine class %q", len(instances), req.MachineClass.Name)
 	listOfMachines := make(map[string]string)
 	for _, instance := range instances {
+		instanceTags := GetInstanceTags(instance)
+		// instances created before the ownership tags were introduced carry no MachineClass tag and are listed as well
+		if machineClassName, ok := instanceTags[TagMachineClassName]; ok && machineClassName != ownershipTagValue(req.MachineClass.Name) {
+			klog.V(4).Infof("Skipping ECS instance %q owned by machine class %q", *instance.InstanceId, machineClassName)
+			continue
+		}
+
+		// the machine name tag may be truncated if it has the maximum length, the instance name holds the name in full
 		machineName := *instance.InstanceName
+		if name := instanceTags[TagMachineName]; name != "" && len(name) < maxTagValueLength {
+			machineName = name
+		}
 		listOfMachines[encodeProviderID(providerSpec.Region, *instance.InstanceId)] = machineName
 	}
 
@@ -348,10 +540,13 @@ func (plugin *MachinePlugin) ListMachines(_ context.Context, req *driver.ListMac
 //
 // RESPONSE PARAMETERS (driver.GetVolumeIDsResponse)
 // VolumeIDs             []string                             VolumeIDs is a repeated list of VolumeIDs.
-func (plugin *MachinePlugin) GetVolumeIDs(_ context.Context, req *driver.GetVolumeIDsRequest) (*driver.GetVolumeIDsResponse, error) {
+func (plugin *MachinePlugin) GetVolumeIDs(ctx context.Context, req *driver.GetVolumeIDsRequest) (_ *driver.GetVolumeIDsResponse, err error) {
 	// Log messages to track start and end of request
 	klog.V(2).Infof("GetVolumeIDs request has been received for %q", req.PVSpecs)
 	defer klog.V(2).Infof("GetVolumeIDs request has been processed successfully for %q", req.PVSpecs)
+	defer metrics.ObserveDriverRequest("GetVolumeIDs", time.Now(), &err)
+	_, span := tracing.Start(ctx, "GetVolumeIDs")
+	defer tracing.EndDriverSpan(span, &err)
 
 	var volumeIDs []string
 	for i := range req.PVSpecs {
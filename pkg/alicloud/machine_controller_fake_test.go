@@ -0,0 +1,372 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package alicloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
+	"github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/driver"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis"
+	maperror "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/errors"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/fake"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
+)
+
+var _ = Describe("Machine Controller with the fake ECS", func() {
+	var (
+		ctx          = context.Background()
+		fakeECS      *fake.ECS
+		plugin       *MachinePlugin
+		providerSpec *api.ProviderSpec
+		machineClass *v1alpha1.MachineClass
+		secret       = &corev1.Secret{
+			Data: map[string][]byte{
+				spi.AlicloudAccessKeyID:     []byte("access-key-id"),
+				spi.AlicloudAccessKeySecret: []byte("access-key-secret"),
+				spi.AlicloudUserData:        []byte("user-data"),
+			},
+		}
+
+		newMachine = func(name string) *v1alpha1.Machine {
+			return &v1alpha1.Machine{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shoot--mcm", UID: types.UID(name + "-uid")}}
+		}
+		createMachine = func(machine *v1alpha1.Machine) *driver.CreateMachineResponse {
+			response, err := plugin.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+			Expect(err).NotTo(HaveOccurred())
+			machine.Spec.ProviderID = response.ProviderID
+			return response
+		}
+		// withProviderSpec mutates the provider spec and encodes it into the machine class
+		withProviderSpec = func(mutate func(providerSpec *api.ProviderSpec)) {
+			mutate(providerSpec)
+			providerSpecRaw, err := json.Marshal(providerSpec)
+			Expect(err).NotTo(HaveOccurred())
+			machineClass.ProviderSpec.Raw = providerSpecRaw
+		}
+	)
+
+	BeforeEach(func() {
+		fakeECS = fake.NewECS()
+		plugin = NewAlicloudPlugin(fakeECS)
+		plugin.RetryBackoff = wait.Backoff{Duration: time.Millisecond, Steps: 3}
+		plugin.RateLimiters = nil
+
+		providerSpec = &api.ProviderSpec{
+			APIVersion:         api.V1alpha1,
+			ImageID:            "m-uf6jf6utod2nfs9x21iwse",
+			InstanceType:       "ecs.g6.large",
+			Region:             "cn-shanghai",
+			ZoneID:             "cn-shanghai-e",
+			SecurityGroupID:    "sg-uf69t4txlz6r18ybzxbx",
+			VSwitchID:          "vsw-uf6s1fjxxks65rk1tkrpm",
+			InstanceChargeType: "PostPaid",
+			SpotStrategy:       "NoSpot",
+			Tags: map[string]string{
+				"kubernetes.io/cluster/shoot--mcm":     "1",
+				"kubernetes.io/role/worker/shoot--mcm": "1",
+			},
+			SystemDisk: &api.AlicloudSystemDisk{Category: "cloud_essd", Size: 50},
+			DataDisks: []api.AlicloudDataDisk{
+				{Name: "kubelet", Category: "cloud_essd", Size: 100, DeleteWithInstance: ptr.To(true)},
+			},
+		}
+		machineClass = &v1alpha1.MachineClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "machine-class"},
+			Provider:   ProviderAlicloud,
+		}
+		withProviderSpec(func(*api.ProviderSpec) {})
+
+		oldPollInterval := instancePollInterval
+		instancePollInterval = time.Millisecond
+		DeferCleanup(func() {
+			instancePollInterval = oldPollInterval
+		})
+	})
+
+	It("should create, initialize, list and delete a machine", func() {
+		fakeECS.Transitions = 2
+		machine := newMachine("machine-0")
+
+		createResponse := createMachine(machine)
+		Expect(fakeECS.Instances()).To(HaveLen(1))
+		instance := fakeECS.Instances()[0]
+		Expect(createResponse.ProviderID).To(Equal("cn-shanghai." + *instance.InstanceId))
+		Expect(GetInstanceTags(instance)).To(HaveKeyWithValue(TagMachineName, "machine-0"))
+		Expect(fakeECS.Disks()).To(HaveLen(2))
+
+		_, err := plugin.GetMachineStatus(ctx, &driver.GetMachineStatusRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+		expectStatusCode(err, codes.Uninitialized)
+
+		initializeResponse, err := plugin.InitializeMachine(ctx, &driver.InitializeMachineRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(initializeResponse.Addresses).To(ContainElement(corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: GetInstancePrivateIP(instance)}))
+
+		statusResponse, err := plugin.GetMachineStatus(ctx, &driver.GetMachineStatusRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statusResponse.ProviderID).To(Equal(createResponse.ProviderID))
+
+		listResponse, err := plugin.ListMachines(ctx, &driver.ListMachinesRequest{MachineClass: machineClass, Secret: secret})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(listResponse.MachineList).To(Equal(map[string]string{createResponse.ProviderID: "machine-0"}))
+
+		_, err = plugin.DeleteMachine(ctx, &driver.DeleteMachineRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeECS.Instances()).To(BeEmpty())
+		Expect(fakeECS.Disks()).To(BeEmpty())
+		Expect(fakeECS.NetworkInterfaces()).To(BeEmpty())
+
+		_, err = plugin.GetMachineStatus(ctx, &driver.GetMachineStatusRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+		expectStatusCode(err, codes.NotFound)
+	})
+
+	It("should not launch a second instance if CreateMachine is retried", func() {
+		machine := newMachine("machine-0")
+		first := createMachine(machine)
+
+		machine.Spec.ProviderID = ""
+		Expect(createMachine(machine).ProviderID).To(Equal(first.ProviderID))
+		Expect(fakeECS.Instances()).To(HaveLen(1))
+		Expect(fakeECS.Calls("RunInstances")).To(Equal(1))
+	})
+
+	It("should list machines across several pages", func() {
+		for i := range 25 {
+			createMachine(newMachine(fmt.Sprintf("machine-%d", i)))
+		}
+
+		listResponse, err := plugin.ListMachines(ctx, &driver.ListMachinesRequest{MachineClass: machineClass, Secret: secret})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(listResponse.MachineList).To(HaveLen(25))
+		Expect(fakeECS.Calls("DescribeInstances")).To(Equal(25 + 3))
+	})
+
+	It("should truncate the ownership tag of long machine class names and list their machines", func() {
+		machineClass.Name = strings.Repeat("machine-class-", 15)
+		createMachine(newMachine("machine-0"))
+
+		instanceTags := GetInstanceTags(fakeECS.Instances()[0])
+		Expect(instanceTags[TagMachineClassName]).To(HaveLen(maxTagValueLength))
+		Expect(instanceTags[TagMachineName]).To(Equal("machine-0"))
+
+		listResponse, err := plugin.ListMachines(ctx, &driver.ListMachinesRequest{MachineClass: machineClass, Secret: secret})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(listResponse.MachineList).To(ConsistOf("machine-0"))
+	})
+
+	It("should retry throttled calls and map the error codes of failed calls", func() {
+		fakeECS.FailNext("RunInstances",
+			fake.NewError(http.StatusTooManyRequests, maperror.Throttling, "Request was denied due to request throttling."),
+			fake.NewError(http.StatusForbidden, maperror.OperationDeniedNoStock, "The requested resource is sold out in the specified zone."),
+		)
+
+		_, err := plugin.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: newMachine("machine-0"), MachineClass: machineClass, Secret: secret})
+		expectStatusCode(err, codes.ResourceExhausted)
+		Expect(err.Error()).To(ContainSubstring("fake-error-request-id"))
+		Expect(fakeECS.Calls("RunInstances")).To(Equal(2))
+		Expect(fakeECS.Instances()).To(BeEmpty())
+	})
+
+	It("should fall back to pay-as-you-go instances if no spot instances are in stock", func() {
+		withProviderSpec(func(spec *api.ProviderSpec) {
+			spec.SpotStrategy = api.SpotStrategyAsPriceGo
+			spec.SpotFallback = &api.AlicloudSpotFallback{MaxPercentage: ptr.To(50)}
+		})
+		noStockErr := fake.NewError(http.StatusForbidden, maperror.OperationDeniedNoStock, "The requested resource is sold out in the specified zone.")
+
+		createMachine(newMachine("machine-0"))
+		fakeECS.FailNext("RunInstances", noStockErr)
+		createResponse := createMachine(newMachine("machine-1"))
+		Expect(createResponse.LastKnownState).To(HaveSuffix("as pay-as-you-go instance, as no spot instance was in stock"))
+		Expect(fakeECS.Calls("RunInstances")).To(Equal(3))
+
+		instances := fakeECS.Instances()
+		Expect(instances).To(HaveLen(2))
+		Expect(*instances[0].SpotStrategy).To(Equal("SpotAsPriceGo"))
+		Expect(GetInstanceTags(instances[0])).NotTo(HaveKey(TagSpotFallback))
+		Expect(*instances[1].SpotStrategy).To(Equal("NoSpot"))
+		Expect(GetInstanceTags(instances[1])).To(HaveKeyWithValue(TagSpotFallback, "SpotAsPriceGo"))
+
+		// another pay-as-you-go instance would exceed the share of 50%
+		fakeECS.FailNext("RunInstances", noStockErr)
+		_, err := plugin.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: newMachine("machine-2"), MachineClass: machineClass, Secret: secret})
+		expectStatusCode(err, codes.ResourceExhausted)
+		Expect(fakeECS.Instances()).To(HaveLen(2))
+	})
+
+	It("should try the instance types in order if ECS has no capacity for them", func() {
+		withProviderSpec(func(spec *api.ProviderSpec) {
+			spec.InstanceTypes = []string{"ecs.g6.large", "ecs.g7.large", "ecs.c6.xlarge"}
+		})
+		fakeECS.FailNext("RunInstances",
+			fake.NewError(http.StatusForbidden, maperror.OperationDeniedNoStock, "The requested resource is sold out in the specified zone."),
+			fake.NewError(http.StatusForbidden, maperror.InvalidInstanceTypeZoneNotSupported, "The specified zone does not support this instancetype."),
+		)
+
+		createResponse := createMachine(newMachine("machine-0"))
+		Expect(createResponse.LastKnownState).To(HaveSuffix("with instance type ecs.c6.xlarge"))
+		Expect(fakeECS.Calls("RunInstances")).To(Equal(3))
+		Expect(fakeECS.Instances()).To(ConsistOf(HaveField("InstanceType", HaveValue(Equal("ecs.c6.xlarge")))))
+
+		// other errors are not worth trying another instance type
+		fakeECS.FailNext("RunInstances", fake.NewError(http.StatusForbidden, maperror.QuotaExceededElasticQuota, "The number of vCPUs assigned to the ECS instances has exceeded the quota in the zone."))
+		_, err := plugin.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: newMachine("machine-1"), MachineClass: machineClass, Secret: secret})
+		expectStatusCode(err, codes.ResourceExhausted)
+		Expect(err.Error()).To(ContainSubstring("failed to run ECS instance of instance type ecs.g6.large"))
+		Expect(fakeECS.Calls("RunInstances")).To(Equal(4))
+	})
+
+	It("should create additional network interfaces and delete those which are not released with the instance", func() {
+		withProviderSpec(func(spec *api.ProviderSpec) {
+			spec.VSwitchID, spec.SecurityGroupID = "", ""
+			spec.NetworkInterfaces = []api.AlicloudNetworkInterface{
+				{Name: "primary", Type: api.NetworkInterfaceTypePrimary, VSwitchID: "vsw-primary", SecurityGroupIDs: []string{"sg-primary"}},
+				{Name: "storage", Type: api.NetworkInterfaceTypeSecondary, VSwitchID: "vsw-storage", SecurityGroupIDs: []string{"sg-storage-1", "sg-storage-2"}},
+			}
+		})
+
+		machine := newMachine("machine-0")
+		createMachine(machine)
+		Expect(fakeECS.Instances()[0].VpcAttributes.VSwitchId).To(Equal(ptr.To("vsw-primary")))
+		networkInterfaces := fakeECS.NetworkInterfaces()
+		Expect(networkInterfaces).To(HaveLen(2))
+		var storageNetworkInterfaceID string
+		for _, networkInterface := range networkInterfaces {
+			switch *networkInterface.NetworkInterfaceName {
+			case "machine-0-primary-eni":
+				Expect(*networkInterface.VSwitchId).To(Equal("vsw-primary"))
+				Expect(networkInterface.SecurityGroupIds.SecurityGroupId).To(Equal([]*string{ptr.To("sg-primary")}))
+			case "machine-0-storage-eni":
+				storageNetworkInterfaceID = *networkInterface.NetworkInterfaceId
+				Expect(*networkInterface.VSwitchId).To(Equal("vsw-storage"))
+				Expect(networkInterface.SecurityGroupIds.SecurityGroupId).To(Equal([]*string{ptr.To("sg-storage-1"), ptr.To("sg-storage-2")}))
+			}
+		}
+		Expect(storageNetworkInterfaceID).NotTo(BeEmpty())
+
+		// the initialization tags the secondary network interface with the cluster tags, by which it is looked up
+		_, err := plugin.InitializeMachine(ctx, &driver.InitializeMachineRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+		Expect(err).NotTo(HaveOccurred())
+
+		// the instance has been released by an earlier attempt, which left the secondary network interface behind
+		Expect(fakeECS.UpdateNetworkInterface(storageNetworkInterfaceID, func(networkInterface *ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSet) {
+			networkInterface.DeleteOnRelease = ptr.To(false)
+		})).To(Succeed())
+		_, err = fakeECS.DeleteInstance(&ecs.DeleteInstanceRequest{InstanceId: ptr.To(decodeProviderID(machine.Spec.ProviderID)), Force: ptr.To(true)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeECS.NetworkInterfaces()).To(HaveLen(1))
+
+		deleteResponse, err := plugin.DeleteMachine(ctx, &driver.DeleteMachineRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleteResponse.LastKnownState).To(ContainSubstring(storageNetworkInterfaceID))
+		Expect(fakeECS.NetworkInterfaces()).To(BeEmpty())
+	})
+
+	It("should assign IPv6 addresses and report them", func() {
+		withProviderSpec(func(spec *api.ProviderSpec) {
+			spec.IPv6AddressCount = ptr.To(1)
+			spec.NetworkInterfaces = []api.AlicloudNetworkInterface{
+				{Name: "storage", Type: api.NetworkInterfaceTypeSecondary, VSwitchID: "vsw-storage", SecurityGroupIDs: []string{"sg-storage"}, IPv6Addresses: []string{"2408:4005:39c:8300::10"}},
+			}
+		})
+		fakeECS.AddVSwitch("vsw-uf6s1fjxxks65rk1tkrpm", "cn-shanghai-e", "10.250.0.0/16", "2408:4005:39c:8200::/64")
+		fakeECS.AddVSwitch("vsw-storage", "cn-shanghai-e", "10.251.0.0/16", "2408:4005:39c:8300::/64")
+
+		machine := newMachine("machine-0")
+		createResponse := createMachine(machine)
+		instance := fakeECS.Instances()[0]
+		primaryIPv6Addresses := GetInstanceIPv6Addresses(instance, networkInterfaceTypePrimary)
+		Expect(primaryIPv6Addresses).To(HaveLen(1))
+		Expect(GetInstanceIPv6Addresses(instance, networkInterfaceTypeSecondary)).To(BeEmpty())
+		Expect(createResponse.LastKnownState).To(HaveSuffix(fmt.Sprintf("with IPv6 address(es) [%s]", primaryIPv6Addresses[0])))
+
+		// the IPv6 addresses of the secondary network interface are assigned once, when the instance is initialized
+		for range 2 {
+			initializeResponse, err := plugin.InitializeMachine(ctx, &driver.InitializeMachineRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(initializeResponse.Addresses).To(Equal([]corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: GetInstancePrivateIP(instance)},
+				{Type: corev1.NodeInternalIP, Address: primaryIPv6Addresses[0]},
+				{Type: corev1.NodeInternalIP, Address: "2408:4005:39c:8300::10"},
+				{Type: corev1.NodeHostName, Address: createResponse.NodeName},
+			}))
+		}
+		Expect(fakeECS.Calls("AssignIpv6Addresses")).To(Equal(1))
+		Expect(GetInstanceIPv6Addresses(fakeECS.Instances()[0], networkInterfaceTypeSecondary)).To(Equal([]string{"2408:4005:39c:8300::10"}))
+
+		// an adopted instance reports the IPv6 addresses of all its network interfaces
+		createResponse = createMachine(machine)
+		Expect(createResponse.LastKnownState).To(HaveSuffix(fmt.Sprintf("with IPv6 address(es) [%s 2408:4005:39c:8300::10]", primaryIPv6Addresses[0])))
+	})
+
+	It("should assign the IPv6 addresses of the entry of the primary network interface", func() {
+		withProviderSpec(func(spec *api.ProviderSpec) {
+			spec.VSwitchID, spec.SecurityGroupID = "", ""
+			spec.NetworkInterfaces = []api.AlicloudNetworkInterface{
+				{Name: "primary", Type: api.NetworkInterfaceTypePrimary, VSwitchID: "vsw-primary", SecurityGroupIDs: []string{"sg-primary"}, IPv6Addresses: []string{"2408:4005:39c:8300::10"}},
+				{Name: "storage", Type: api.NetworkInterfaceTypeSecondary, VSwitchID: "vsw-storage", SecurityGroupIDs: []string{"sg-storage"}},
+			}
+		})
+		fakeECS.AddVSwitch("vsw-primary", "cn-shanghai-e", "10.251.0.0/16", "2408:4005:39c:8300::/64")
+
+		createResponse := createMachine(newMachine("machine-0"))
+		instance := fakeECS.Instances()[0]
+		Expect(GetInstanceIPv6Addresses(instance, networkInterfaceTypePrimary)).To(Equal([]string{"2408:4005:39c:8300::10"}))
+		Expect(GetInstanceIPv6Addresses(instance, "")).To(Equal([]string{"2408:4005:39c:8300::10"}))
+		Expect(createResponse.LastKnownState).To(HaveSuffix("with IPv6 address(es) [2408:4005:39c:8300::10]"))
+	})
+
+	It("should reject IPv6 addresses of a vSwitch without IPv6 CIDR block before launching the instance", func() {
+		withProviderSpec(func(spec *api.ProviderSpec) {
+			spec.VSwitchID, spec.SecurityGroupID = "", ""
+			spec.NetworkInterfaces = []api.AlicloudNetworkInterface{
+				{Name: "primary", Type: api.NetworkInterfaceTypePrimary, VSwitchID: "vsw-primary", SecurityGroupIDs: []string{"sg-primary"}, IPv6AddressCount: ptr.To(1)},
+			}
+		})
+		fakeECS.AddVSwitch("vsw-primary", "cn-shanghai-e", "10.251.0.0/16", "")
+
+		_, err := plugin.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: newMachine("machine-0"), MachineClass: machineClass, Secret: secret})
+		expectStatusCode(err, codes.InvalidArgument)
+		Expect(err).To(MatchError(ContainSubstring(`providerSpec.networkInterfaces[0].vSwitchID: Invalid value: "vsw-primary": vSwitch has no IPv6 CIDR block`)))
+		Expect(fakeECS.Calls("DescribeVSwitches")).To(Equal(1))
+		Expect(fakeECS.Calls("RunInstances")).To(BeZero())
+		Expect(fakeECS.Instances()).To(BeEmpty())
+	})
+
+	It("should report spot instances which are recycled as unavailable", func() {
+		withProviderSpec(func(spec *api.ProviderSpec) {
+			spec.SpotStrategy = api.SpotStrategyWithPriceLimit
+			spec.SpotPriceLimit = ptr.To(0.5)
+			spec.SpotInterruptionBehavior = "Stop"
+		})
+
+		machine := newMachine("machine-0")
+		createMachine(machine)
+		Expect(fakeECS.UpdateInstance(decodeProviderID(machine.Spec.ProviderID), func(instance *ecs.DescribeInstancesResponseBodyInstancesInstance) {
+			instance.OperationLocks = &ecs.DescribeInstancesResponseBodyInstancesInstanceOperationLocks{
+				LockReason: []*ecs.DescribeInstancesResponseBodyInstancesInstanceOperationLocksLockReason{{LockReason: ptr.To("Recycling")}},
+			}
+		})).To(Succeed())
+
+		_, err := plugin.GetMachineStatus(ctx, &driver.GetMachineStatusRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+		expectStatusCode(err, codes.Unavailable)
+		Expect(err.Error()).To(ContainSubstring("is reclaimed by ECS (spot strategy SpotWithPriceLimit, interruption behaviour Stop)"))
+	})
+})
@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package alicloud
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/driver"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/replay"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
+)
+
+// The cassettes in testdata/replay are replayed by default. If RECORD_ECS_CASSETTES is true, the calls are made to
+// the ECS API with the credentials of ALIBABA_CLOUD_ACCESS_KEY_ID and ALIBABA_CLOUD_ACCESS_KEY_SECRET instead and the
+// cassettes are overwritten with the recorded calls. ALIBABA_CLOUD_ECS_ENDPOINT optionally overrides the ECS endpoint,
+// e.g. http://localhost:8080. The provider spec of a cassette has to reference existing resources of the account
+// before it is recorded.
+//
+// The cassette machine_lifecycle.json was recorded against the fake.Server of pkg/fake with ALIBABA_CLOUD_ECS_ENDPOINT,
+// not against the real ECS API, as no Alibaba Cloud account was at hand. It pins the requests the driver sends, but its
+// responses are only as faithful as the fake and it has to be recorded again against ECS to test the driver with
+// responses of the real API.
+const (
+	recordCassettesEnv = "RECORD_ECS_CASSETTES"
+	accessKeyIDEnv     = "ALIBABA_CLOUD_ACCESS_KEY_ID"
+	accessKeySecretEnv = "ALIBABA_CLOUD_ACCESS_KEY_SECRET"
+	ecsEndpointEnv     = "ALIBABA_CLOUD_ECS_ENDPOINT"
+)
+
+var _ = Describe("Machine Controller with recorded ECS calls", func() {
+	var (
+		ctx          = context.Background()
+		recording    = os.Getenv(recordCassettesEnv) == "true"
+		plugin       *MachinePlugin
+		machineClass *v1alpha1.MachineClass
+		secret       *corev1.Secret
+		machine      *v1alpha1.Machine
+
+		// useCassette makes the plugin replay or record the calls of the cassette with the given name
+		useCassette = func(name string) {
+			path := filepath.Join("testdata", "replay", name+".json")
+			cassette, err := replay.Load(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			providerSpecRaw, err := json.Marshal(cassette.ProviderSpec)
+			Expect(err).NotTo(HaveOccurred())
+			machineClass = &v1alpha1.MachineClass{
+				ObjectMeta:   metav1.ObjectMeta{Name: "machine-class"},
+				Provider:     ProviderAlicloud,
+				ProviderSpec: runtime.RawExtension{Raw: providerSpecRaw},
+			}
+			secret = &corev1.Secret{Data: map[string][]byte{
+				spi.AlicloudAccessKeyID:     []byte("access-key-id"),
+				spi.AlicloudAccessKeySecret: []byte("access-key-secret"),
+				spi.AlicloudUserData:        []byte("user-data"),
+			}}
+
+			if !recording {
+				replayer := replay.NewReplayer(cassette)
+				plugin = NewAlicloudPlugin(replayer)
+				plugin.RetryBackoff = wait.Backoff{Duration: time.Millisecond, Steps: 3}
+				oldPollInterval := instancePollInterval
+				instancePollInterval = time.Millisecond
+				DeferCleanup(func() {
+					instancePollInterval = oldPollInterval
+					Expect(replayer.Verify()).To(Succeed())
+				})
+				return
+			}
+
+			secret.Data[spi.AlicloudAccessKeyID] = []byte(os.Getenv(accessKeyIDEnv))
+			secret.Data[spi.AlicloudAccessKeySecret] = []byte(os.Getenv(accessKeySecretEnv))
+			pluginSPI := &spi.PluginSPIImpl{}
+			if endpoint := os.Getenv(ecsEndpointEnv); endpoint != "" {
+				protocol, host, found := strings.Cut(endpoint, "://")
+				if !found {
+					protocol, host = "https", endpoint
+				}
+				pluginSPI.ClientOptions = spi.ClientOptions{Endpoint: host, Protocol: protocol}
+			}
+			cassette.Interactions = nil
+			plugin = NewAlicloudPlugin(replay.NewRecorder(pluginSPI, cassette))
+			DeferCleanup(func() {
+				Expect(cassette.Save(path)).To(Succeed())
+			})
+		}
+	)
+
+	BeforeEach(func() {
+		machine = &v1alpha1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine-0", Namespace: "shoot--mcm", UID: "machine-0-uid"}}
+	})
+
+	It("should create, initialize, list and delete a machine", func() {
+		useCassette("machine_lifecycle")
+
+		createResponse, err := plugin.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+		Expect(err).NotTo(HaveOccurred())
+		machine.Spec.ProviderID = createResponse.ProviderID
+
+		// MCM retries the initialization as long as the instance is not running
+		for {
+			_, err = plugin.InitializeMachine(ctx, &driver.InitializeMachineRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+			if s, _ := status.FromError(err); err == nil || s.Code() != codes.Uninitialized {
+				break
+			}
+		}
+		Expect(err).NotTo(HaveOccurred())
+
+		statusResponse, err := plugin.GetMachineStatus(ctx, &driver.GetMachineStatusRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statusResponse.ProviderID).To(Equal(createResponse.ProviderID))
+		Expect(statusResponse.NodeName).To(Equal(createResponse.NodeName))
+
+		listResponse, err := plugin.ListMachines(ctx, &driver.ListMachinesRequest{MachineClass: machineClass, Secret: secret})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(listResponse.MachineList).To(HaveKeyWithValue(createResponse.ProviderID, machine.Name))
+
+		_, err = plugin.DeleteMachine(ctx, &driver.DeleteMachineRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = plugin.GetMachineStatus(ctx, &driver.GetMachineStatusRequest{Machine: machine, MachineClass: machineClass, Secret: secret})
+		expectStatusCode(err, codes.NotFound)
+	})
+})
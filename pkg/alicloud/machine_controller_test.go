@@ -9,18 +9,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/alibabacloud-go/tea/tea"
 
 	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/driver"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
 
 	api "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis"
 	mockclient "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/mock/client"
@@ -51,6 +56,7 @@ var _ = Describe("Machine Controller", func() {
 			InternetMaxBandwidthIn:  &internetMaxBandwidthIn,
 			InternetMaxBandwidthOut: &internetMaxBandwidthOut,
 			SpotStrategy:            "NoSpot",
+			IoOptimized:             "optimized",
 			KeyPairName:             "shoot-ssh-publickey",
 			Tags: map[string]string{
 				"kubernetes.io/cluster/shoot--mcm":     "1",
@@ -81,7 +87,13 @@ var _ = Describe("Machine Controller", func() {
 			},
 		}
 
-		providerSecret = &corev1.Secret{}
+		providerSecret = &corev1.Secret{
+			Data: map[string][]byte{
+				spi.AlicloudAccessKeyID:     []byte("mock-access-key-id"),
+				spi.AlicloudAccessKeySecret: []byte("mock-access-key-secret"),
+				spi.AlicloudUserData:        []byte("mock-user-data"),
+			},
+		}
 
 		runInstancesRequest = &ecs.RunInstancesRequest{}
 		runInstanceResponse = &ecs.RunInstancesResponse{
@@ -119,11 +131,42 @@ var _ = Describe("Machine Controller", func() {
 			},
 		}
 
+		describeInstanceResponseWithStatus = func(instanceStatus string) *ecs.DescribeInstancesResponse {
+			return &ecs.DescribeInstancesResponse{
+				Body: &ecs.DescribeInstancesResponseBody{
+					TotalCount: tea.Int32(1),
+					Instances: &ecs.DescribeInstancesResponseBodyInstances{
+						Instance: []*ecs.DescribeInstancesResponseBodyInstancesInstance{
+							{
+								Status:       tea.String(instanceStatus),
+								InstanceId:   tea.String(instanceID),
+								InstanceName: tea.String(machineName),
+							},
+						},
+					},
+				},
+			}
+		}
+
+		ownedTags = map[string]string{
+			"kubernetes.io/cluster/shoot--mcm":     "1",
+			"kubernetes.io/role/worker/shoot--mcm": "1",
+			TagMachineClassName:                    machineClassName,
+			TagMachineName:                         machineName,
+		}
+
+		emptyDescribeInstanceResponse = &ecs.DescribeInstancesResponse{
+			Body: &ecs.DescribeInstancesResponseBody{
+				TotalCount: tea.Int32(0),
+				Instances:  &ecs.DescribeInstancesResponseBodyInstances{},
+			},
+		}
+
 		ctx               = context.Background()
 		ctrl              *gomock.Controller
 		mockPluginSPI     *mockspi.MockPluginSPI
 		mockECSClient     *mockclient.MockECSClient
-		mockMachinePlugin driver.Driver
+		mockMachinePlugin *MachinePlugin
 	)
 
 	BeforeEach(func() {
@@ -131,11 +174,18 @@ var _ = Describe("Machine Controller", func() {
 		mockPluginSPI = mockspi.NewMockPluginSPI(ctrl)
 		mockECSClient = mockclient.NewMockECSClient(ctrl)
 		mockMachinePlugin = NewAlicloudPlugin(mockPluginSPI)
+		mockMachinePlugin.RetryBackoff = wait.Backoff{Duration: time.Millisecond, Steps: 2}
 
 		describeInstanceRequest = &ecs.DescribeInstancesRequest{
 			InstanceIds: tea.String("[\"" + instanceID + "\"]"),
 			RegionId:    tea.String(providerSpec.Region),
 		}
+
+		oldPollInterval, oldReleaseTimeout := instancePollInterval, instanceReleaseTimeout
+		instancePollInterval, instanceReleaseTimeout = time.Millisecond, 20*time.Millisecond
+		DeferCleanup(func() {
+			instancePollInterval, instanceReleaseTimeout = oldPollInterval, oldReleaseTimeout
+		})
 	})
 
 	AfterEach(func() {
@@ -154,11 +204,18 @@ var _ = Describe("Machine Controller", func() {
 				NodeName:       nodeName,
 				LastKnownState: "ECS instance i-mockinstanceid created for machine mock-machine-name",
 			}
+			ownedProviderSpec = func() *api.ProviderSpec {
+				spec := *providerSpec
+				spec.Tags = ownedTags
+				return &spec
+			}()
 		)
 
 		gomock.InOrder(
 			mockPluginSPI.EXPECT().NewECSClient(createMachineRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
-			mockPluginSPI.EXPECT().NewRunInstancesRequest(providerSpec, createMachineRequest.Machine.Name, createMachineRequest.Secret.Data[spi.AlicloudUserData]).Return(runInstancesRequest, nil),
+			mockPluginSPI.EXPECT().NewDescribeInstancesRequest(createMachineRequest.Machine.Name, "", providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+			mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(emptyDescribeInstanceResponse, nil),
+			mockPluginSPI.EXPECT().NewRunInstancesRequest(ownedProviderSpec, createMachineRequest.Machine.Name, newClientToken(machine, machineClass), createMachineRequest.Secret.Data[spi.AlicloudUserData]).Return(runInstancesRequest, nil),
 			mockECSClient.EXPECT().RunInstances(runInstancesRequest).Return(runInstanceResponse, nil),
 		)
 
@@ -167,6 +224,295 @@ var _ = Describe("Machine Controller", func() {
 		Expect(response).To(Equal(createMachineResponse))
 	})
 
+	It("should adopt an already existing instance instead of creating another one", func() {
+		createMachineRequest := &driver.CreateMachineRequest{
+			Machine:      machine,
+			MachineClass: machineClass,
+			Secret:       providerSecret,
+		}
+
+		gomock.InOrder(
+			mockPluginSPI.EXPECT().NewECSClient(createMachineRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+			mockPluginSPI.EXPECT().NewDescribeInstancesRequest(createMachineRequest.Machine.Name, "", providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+			mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponse, nil),
+		)
+
+		response, err := mockMachinePlugin.CreateMachine(ctx, createMachineRequest)
+		Expect(err).To(BeNil())
+		Expect(response).To(Equal(&driver.CreateMachineResponse{
+			ProviderID:     providerID,
+			NodeName:       nodeName,
+			LastKnownState: "ECS instance i-mockinstanceid adopted for machine mock-machine-name",
+		}))
+	})
+
+	It("should not adopt instances which are being released or owned by another machine class", func() {
+		var (
+			createMachineRequest = &driver.CreateMachineRequest{
+				Machine:      machine,
+				MachineClass: machineClass,
+				Secret:       providerSecret,
+			}
+			ownedProviderSpec = func() *api.ProviderSpec {
+				spec := *providerSpec
+				spec.Tags = ownedTags
+				return &spec
+			}()
+			existingInstancesResponse = &ecs.DescribeInstancesResponse{
+				Body: &ecs.DescribeInstancesResponseBody{
+					TotalCount: tea.Int32(2),
+					Instances: &ecs.DescribeInstancesResponseBodyInstances{
+						Instance: []*ecs.DescribeInstancesResponseBodyInstancesInstance{
+							{Status: tea.String("Stopping"), InstanceId: tea.String("i-stopping"), InstanceName: tea.String(machineName)},
+							{
+								Status:       tea.String("Running"),
+								InstanceId:   tea.String("i-other-class"),
+								InstanceName: tea.String(machineName),
+								Tags: &ecs.DescribeInstancesResponseBodyInstancesInstanceTags{
+									Tag: []*ecs.DescribeInstancesResponseBodyInstancesInstanceTagsTag{
+										{TagKey: tea.String(TagMachineClassName), TagValue: tea.String("other-machine-class")},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+		)
+
+		gomock.InOrder(
+			mockPluginSPI.EXPECT().NewECSClient(createMachineRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+			mockPluginSPI.EXPECT().NewDescribeInstancesRequest(createMachineRequest.Machine.Name, "", providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+			mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(existingInstancesResponse, nil),
+			mockPluginSPI.EXPECT().NewRunInstancesRequest(ownedProviderSpec, createMachineRequest.Machine.Name, newClientToken(machine, machineClass), createMachineRequest.Secret.Data[spi.AlicloudUserData]).Return(runInstancesRequest, nil),
+			mockECSClient.EXPECT().RunInstances(runInstancesRequest).Return(runInstanceResponse, nil),
+		)
+
+		response, err := mockMachinePlugin.CreateMachine(ctx, createMachineRequest)
+		Expect(err).To(BeNil())
+		Expect(response.ProviderID).To(Equal(providerID))
+	})
+
+	It("should fail to create machine with an invalid provider spec", func() {
+		invalidProviderSpec := *providerSpec
+		invalidProviderSpec.VSwitchID = ""
+		invalidProviderSpec.SpotStrategy = "SpotAsPriceStop"
+		invalidProviderSpecRaw, _ := json.Marshal(invalidProviderSpec)
+
+		invalidMachineClass := machineClass.DeepCopy()
+		invalidMachineClass.ProviderSpec.Raw = invalidProviderSpecRaw
+
+		response, err := mockMachinePlugin.CreateMachine(ctx, &driver.CreateMachineRequest{
+			Machine:      machine,
+			MachineClass: invalidMachineClass,
+			Secret:       providerSecret,
+		})
+		Expect(response).To(BeNil())
+		expectStatusCode(err, codes.InvalidArgument)
+		Expect(err.Error()).To(And(ContainSubstring("providerSpec.vSwitchID"), ContainSubstring("providerSpec.spotStrategy")))
+	})
+
+	It("should fail to create machine when the secret lacks the user data", func() {
+		secretWithoutUserData := providerSecret.DeepCopy()
+		delete(secretWithoutUserData.Data, spi.AlicloudUserData)
+
+		response, err := mockMachinePlugin.CreateMachine(ctx, &driver.CreateMachineRequest{
+			Machine:      machine,
+			MachineClass: machineClass,
+			Secret:       secretWithoutUserData,
+		})
+		Expect(response).To(BeNil())
+		expectStatusCode(err, codes.InvalidArgument)
+		Expect(err.Error()).To(ContainSubstring("secretRef.data[userData]"))
+	})
+
+	It("should fail to delete machine when the secret lacks credentials", func() {
+		response, err := mockMachinePlugin.DeleteMachine(ctx, &driver.DeleteMachineRequest{
+			Machine:      machine,
+			MachineClass: machineClass,
+			Secret:       &corev1.Secret{Data: map[string][]byte{spi.AlicloudUserData: []byte("mock-user-data")}},
+		})
+		Expect(response).To(BeNil())
+		expectStatusCode(err, codes.InvalidArgument)
+		Expect(err.Error()).To(ContainSubstring("secretRef.data[alicloudAccessKeyID]"))
+	})
+
+	Describe("#InitializeMachine", func() {
+		var (
+			initializeMachineRequest *driver.InitializeMachineRequest
+			runningInstance          *ecs.DescribeInstancesResponseBodyInstancesInstance
+
+			describeInstanceResponseFor = func(instance *ecs.DescribeInstancesResponseBodyInstancesInstance) *ecs.DescribeInstancesResponse {
+				return &ecs.DescribeInstancesResponse{
+					Body: &ecs.DescribeInstancesResponseBody{
+						TotalCount: tea.Int32(1),
+						Instances: &ecs.DescribeInstancesResponseBodyInstances{
+							Instance: []*ecs.DescribeInstancesResponseBodyInstancesInstance{instance},
+						},
+					},
+				}
+			}
+		)
+
+		BeforeEach(func() {
+			initializeMachineRequest = &driver.InitializeMachineRequest{
+				Machine:      machine,
+				MachineClass: machineClass,
+				Secret:       providerSecret,
+			}
+			runningInstance = &ecs.DescribeInstancesResponseBodyInstancesInstance{
+				Status:       tea.String("Running"),
+				InstanceId:   tea.String(instanceID),
+				InstanceName: tea.String(machineName),
+				NetworkInterfaces: &ecs.DescribeInstancesResponseBodyInstancesInstanceNetworkInterfaces{
+					NetworkInterface: []*ecs.DescribeInstancesResponseBodyInstancesInstanceNetworkInterfacesNetworkInterface{
+						{Type: tea.String("Primary"), PrimaryIpAddress: tea.String("10.250.0.10")},
+					},
+				},
+				Tags: &ecs.DescribeInstancesResponseBodyInstancesInstanceTags{
+					Tag: []*ecs.DescribeInstancesResponseBodyInstancesInstanceTagsTag{
+						{TagKey: tea.String("kubernetes.io/cluster/shoot--mcm"), TagValue: tea.String("1")},
+						{TagKey: tea.String("kubernetes.io/role/worker/shoot--mcm"), TagValue: tea.String("1")},
+						{TagKey: tea.String(TagMachineClassName), TagValue: tea.String(machineClassName)},
+						{TagKey: tea.String(TagMachineName), TagValue: tea.String(machineName)},
+					},
+				},
+			}
+
+			oldPollInterval, oldRunningTimeout := instancePollInterval, instanceRunningTimeout
+			instancePollInterval, instanceRunningTimeout = time.Millisecond, 20*time.Millisecond
+			DeferCleanup(func() {
+				instancePollInterval, instanceRunningTimeout = oldPollInterval, oldRunningTimeout
+			})
+		})
+
+		It("should initialize a running machine", func() {
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(initializeMachineRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseFor(runningInstance), nil),
+			)
+
+			response, err := mockMachinePlugin.InitializeMachine(ctx, initializeMachineRequest)
+			Expect(err).To(BeNil())
+			Expect(response).To(Equal(&driver.InitializeMachineResponse{
+				ProviderID: providerID,
+				NodeName:   nodeName,
+				Addresses: []corev1.NodeAddress{
+					{Type: corev1.NodeInternalIP, Address: "10.250.0.10"},
+					{Type: corev1.NodeHostName, Address: nodeName},
+				},
+			}))
+		})
+
+		It("should run the initialization hooks until they succeed", func() {
+			var hookedInstanceIDs []string
+			mockMachinePlugin.InitializationHooks = []InitializationHook{
+				func(_ context.Context, req *driver.InitializeMachineRequest, spec *api.ProviderSpec, instance *ecs.DescribeInstancesResponseBodyInstancesInstance) error {
+					Expect(req).To(Equal(initializeMachineRequest))
+					Expect(spec.Region).To(Equal(providerSpec.Region))
+					hookedInstanceIDs = append(hookedInstanceIDs, *instance.InstanceId)
+					if len(hookedInstanceIDs) == 1 {
+						return fmt.Errorf("EIP is not available yet")
+					}
+					return nil
+				},
+			}
+
+			mockPluginSPI.EXPECT().NewECSClient(initializeMachineRequest.Secret, providerSpec.Region).Return(mockECSClient, nil).Times(2)
+			mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil).Times(2)
+			mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseFor(runningInstance), nil).Times(2)
+
+			response, err := mockMachinePlugin.InitializeMachine(ctx, initializeMachineRequest)
+			Expect(response).To(BeNil())
+			expectStatusCode(err, codes.Internal)
+			Expect(err.Error()).To(ContainSubstring("EIP is not available yet"))
+
+			response, err = mockMachinePlugin.InitializeMachine(ctx, initializeMachineRequest)
+			Expect(err).To(BeNil())
+			Expect(response.ProviderID).To(Equal(providerID))
+			Expect(hookedInstanceIDs).To(Equal([]string{instanceID, instanceID}))
+		})
+
+		It("should wait for a pending machine and add missing tags", func() {
+			var (
+				pendingInstance    = &ecs.DescribeInstancesResponseBodyInstancesInstance{Status: tea.String("Pending"), InstanceId: tea.String(instanceID)}
+				tagResourceRequest = &ecs.TagResourcesRequest{}
+			)
+			runningInstance.Tags = nil
+
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(initializeMachineRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseFor(pendingInstance), nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseFor(runningInstance), nil),
+				mockPluginSPI.EXPECT().NewTagResourcesRequest(instanceID, providerSpec.Region, ownedTags).Return(tagResourceRequest, nil),
+				mockECSClient.EXPECT().TagResources(tagResourceRequest).Return(&ecs.TagResourcesResponse{}, nil),
+			)
+
+			response, err := mockMachinePlugin.InitializeMachine(ctx, initializeMachineRequest)
+			Expect(err).To(BeNil())
+			Expect(response.ProviderID).To(Equal(providerID))
+		})
+
+		It("should return Uninitialized if the machine does not become running in time", func() {
+			pendingInstance := &ecs.DescribeInstancesResponseBodyInstancesInstance{Status: tea.String("Starting"), InstanceId: tea.String(instanceID)}
+
+			mockPluginSPI.EXPECT().NewECSClient(initializeMachineRequest.Secret, providerSpec.Region).Return(mockECSClient, nil)
+			mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil).MinTimes(1)
+			mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseFor(pendingInstance), nil).MinTimes(1)
+
+			response, err := mockMachinePlugin.InitializeMachine(ctx, initializeMachineRequest)
+			Expect(response).To(BeNil())
+			expectStatusCode(err, codes.Uninitialized)
+		})
+
+		It("should return Uninitialized if the primary network interface has no private IP", func() {
+			runningInstance.NetworkInterfaces = nil
+
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(initializeMachineRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseFor(runningInstance), nil),
+			)
+
+			response, err := mockMachinePlugin.InitializeMachine(ctx, initializeMachineRequest)
+			Expect(response).To(BeNil())
+			expectStatusCode(err, codes.Uninitialized)
+		})
+
+		It("should return FailedPrecondition if the machine is locked", func() {
+			runningInstance.OperationLocks = &ecs.DescribeInstancesResponseBodyInstancesInstanceOperationLocks{
+				LockReason: []*ecs.DescribeInstancesResponseBodyInstancesInstanceOperationLocksLockReason{{LockReason: tea.String("security")}},
+			}
+
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(initializeMachineRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseFor(runningInstance), nil),
+			)
+
+			response, err := mockMachinePlugin.InitializeMachine(ctx, initializeMachineRequest)
+			Expect(response).To(BeNil())
+			expectStatusCode(err, codes.FailedPrecondition)
+		})
+
+		It("should return Unavailable if the machine is stopped", func() {
+			runningInstance.Status = tea.String("Stopped")
+
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(initializeMachineRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseFor(runningInstance), nil),
+			)
+
+			response, err := mockMachinePlugin.InitializeMachine(ctx, initializeMachineRequest)
+			Expect(response).To(BeNil())
+			expectStatusCode(err, codes.Unavailable)
+		})
+	})
+
 	Describe("should delete machine successfully", func() {
 		It("when machine.spec.providerID is set", func() {
 			var (
@@ -186,6 +532,8 @@ var _ = Describe("Machine Controller", func() {
 				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponse, nil),
 				mockPluginSPI.EXPECT().NewDeleteInstanceRequest(instanceID, true).Return(deleteInstanceRequest, nil),
 				mockECSClient.EXPECT().DeleteInstance(deleteInstanceRequest).Return(deleteInstanceResponse, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(emptyDescribeInstanceResponse, nil),
 			)
 
 			response, err := mockMachinePlugin.DeleteMachine(ctx, deleteMachineRequest)
@@ -210,6 +558,8 @@ var _ = Describe("Machine Controller", func() {
 				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponse, nil),
 				mockPluginSPI.EXPECT().NewDeleteInstanceRequest(instanceID, true).Return(deleteInstanceRequest, nil),
 				mockECSClient.EXPECT().DeleteInstance(deleteInstanceRequest).Return(deleteInstanceResponse, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(emptyDescribeInstanceResponse, nil),
 			)
 
 			deleteMachineRequest.Machine.Spec.ProviderID = ""
@@ -293,15 +643,14 @@ var _ = Describe("Machine Controller", func() {
 				}),
 			)
 
-			for _, inst := range page1Instances {
-				req := &ecs.DeleteInstanceRequest{InstanceId: inst.InstanceId, Force: tea.Bool(true)}
-				mockPluginSPI.EXPECT().NewDeleteInstanceRequest(*inst.InstanceId, true).Return(req, nil)
-				mockECSClient.EXPECT().DeleteInstance(req).Return(&ecs.DeleteInstanceResponse{}, nil)
-			}
-			for _, inst := range page2Instances {
+			for _, inst := range append(page1Instances, page2Instances...) {
 				req := &ecs.DeleteInstanceRequest{InstanceId: inst.InstanceId, Force: tea.Bool(true)}
 				mockPluginSPI.EXPECT().NewDeleteInstanceRequest(*inst.InstanceId, true).Return(req, nil)
 				mockECSClient.EXPECT().DeleteInstance(req).Return(&ecs.DeleteInstanceResponse{}, nil)
+
+				describeReleasedRequest := &ecs.DescribeInstancesRequest{InstanceIds: tea.String("[\"" + *inst.InstanceId + "\"]")}
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", *inst.InstanceId, providerSpec.Region, providerSpec.Tags).Return(describeReleasedRequest, nil)
+				mockECSClient.EXPECT().DescribeInstances(describeReleasedRequest).Return(emptyDescribeInstanceResponse, nil)
 			}
 
 			deleteMachineRequest.Machine.Spec.ProviderID = ""
@@ -310,30 +659,411 @@ var _ = Describe("Machine Controller", func() {
 			Expect(response).To(Equal(deleteMachineResponse))
 			deleteMachineRequest.Machine.Spec.ProviderID = providerID
 		})
-	})
 
-	It("should get machine status successfully", func() {
-		var (
-			getMachineStatusRequest = &driver.GetMachineStatusRequest{
+		It("when the instance has already been released", func() {
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(providerSecret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(emptyDescribeInstanceResponse, nil),
+			)
+
+			response, err := mockMachinePlugin.DeleteMachine(ctx, &driver.DeleteMachineRequest{
+				Machine:      machine,
+				MachineClass: machineClass,
+				Secret:       providerSecret,
+			})
+			Expect(err).To(BeNil())
+			Expect(response).To(Equal(&driver.DeleteMachineResponse{}))
+		})
+
+		It("when the instance is stopping, by deleting it once it is stopped", func() {
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(providerSecret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseWithStatus("Stopping"), nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseWithStatus("Stopped"), nil),
+				mockPluginSPI.EXPECT().NewDeleteInstanceRequest(instanceID, true).Return(deleteInstanceRequest, nil),
+				mockECSClient.EXPECT().DeleteInstance(deleteInstanceRequest).Return(deleteInstanceResponse, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(emptyDescribeInstanceResponse, nil),
+			)
+
+			response, err := mockMachinePlugin.DeleteMachine(ctx, &driver.DeleteMachineRequest{
+				Machine:      machine,
+				MachineClass: machineClass,
+				Secret:       providerSecret,
+			})
+			Expect(err).To(BeNil())
+			Expect(response).To(Equal(&driver.DeleteMachineResponse{
+				LastKnownState: "ECS instance i-mockinstanceid deleted for machine mock-machine-name",
+			}))
+		})
+
+		It("when the instance is pending, by force deleting it as soon as ECS allows it", func() {
+			incorrectInstanceStatusErr := tea.NewSDKError(map[string]any{
+				"statusCode": 403,
+				"code":       "IncorrectInstanceStatus",
+				"message":    "The current status of the resource does not support this operation.",
+			})
+
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(providerSecret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseWithStatus("Pending"), nil),
+				mockPluginSPI.EXPECT().NewDeleteInstanceRequest(instanceID, true).Return(deleteInstanceRequest, nil),
+				mockECSClient.EXPECT().DeleteInstance(deleteInstanceRequest).Return(nil, incorrectInstanceStatusErr),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseWithStatus("Starting"), nil),
+				mockPluginSPI.EXPECT().NewDeleteInstanceRequest(instanceID, true).Return(deleteInstanceRequest, nil),
+				mockECSClient.EXPECT().DeleteInstance(deleteInstanceRequest).Return(deleteInstanceResponse, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(emptyDescribeInstanceResponse, nil),
+			)
+
+			response, err := mockMachinePlugin.DeleteMachine(ctx, &driver.DeleteMachineRequest{
 				Machine:      machine,
 				MachineClass: machineClass,
 				Secret:       providerSecret,
+			})
+			Expect(err).To(BeNil())
+			Expect(response).To(Equal(&driver.DeleteMachineResponse{
+				LastKnownState: "ECS instance i-mockinstanceid deleted for machine mock-machine-name",
+			}))
+		})
+	})
+
+	It("should return Unavailable if the instance is not released in time", func() {
+		gomock.InOrder(
+			mockPluginSPI.EXPECT().NewECSClient(providerSecret, providerSpec.Region).Return(mockECSClient, nil),
+			mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+			mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponse, nil),
+			mockPluginSPI.EXPECT().NewDeleteInstanceRequest(instanceID, true).Return(deleteInstanceRequest, nil),
+			mockECSClient.EXPECT().DeleteInstance(deleteInstanceRequest).Return(deleteInstanceResponse, nil),
+		)
+		mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil).MinTimes(1)
+		mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseWithStatus("Stopping"), nil).MinTimes(1)
+
+		response, err := mockMachinePlugin.DeleteMachine(ctx, &driver.DeleteMachineRequest{
+			Machine:      machine,
+			MachineClass: machineClass,
+			Secret:       providerSecret,
+		})
+		expectStatusCode(err, codes.Unavailable)
+		Expect(response).To(Equal(&driver.DeleteMachineResponse{
+			LastKnownState: "Deletion of ECS instance(s) [i-mockinstanceid] for machine mock-machine-name in progress",
+		}))
+	})
+
+	Describe("#DeleteMachine with leaked resources", func() {
+		var (
+			deleteMachineRequest             *driver.DeleteMachineRequest
+			describeDisksRequest             *ecs.DescribeDisksRequest
+			describeNetworkInterfacesRequest *ecs.DescribeNetworkInterfacesRequest
+			diskName                         = "mock-machine-name-disk-1-data-disk"
+
+			describeDisksResponseFor = func(diskStatus string) *ecs.DescribeDisksResponse {
+				return &ecs.DescribeDisksResponse{
+					Body: &ecs.DescribeDisksResponseBody{
+						Disks: &ecs.DescribeDisksResponseBodyDisks{
+							Disk: []*ecs.DescribeDisksResponseBodyDisksDisk{
+								{DiskId: tea.String("d-disk1"), DiskName: tea.String(diskName), Status: tea.String(diskStatus)},
+							},
+						},
+					},
+				}
+			}
+			describeNetworkInterfacesResponseFor = func(networkInterfaceStatus string) *ecs.DescribeNetworkInterfacesResponse {
+				return &ecs.DescribeNetworkInterfacesResponse{
+					Body: &ecs.DescribeNetworkInterfacesResponseBody{
+						NetworkInterfaceSets: &ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSets{
+							NetworkInterfaceSet: []*ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSet{
+								{NetworkInterfaceId: tea.String("eni-secondary"), Status: tea.String(networkInterfaceStatus)},
+							},
+						},
+					},
+				}
+			}
+			describeInstanceWithNetworkInterfacesResponse = &ecs.DescribeInstancesResponse{
+				Body: &ecs.DescribeInstancesResponseBody{
+					TotalCount: tea.Int32(1),
+					Instances: &ecs.DescribeInstancesResponseBodyInstances{
+						Instance: []*ecs.DescribeInstancesResponseBodyInstancesInstance{
+							{
+								Status:       tea.String("Running"),
+								InstanceId:   tea.String(instanceID),
+								InstanceName: tea.String(machineName),
+								NetworkInterfaces: &ecs.DescribeInstancesResponseBodyInstancesInstanceNetworkInterfaces{
+									NetworkInterface: []*ecs.DescribeInstancesResponseBodyInstancesInstanceNetworkInterfacesNetworkInterface{
+										{Type: tea.String("Primary"), NetworkInterfaceId: tea.String("eni-primary")},
+										{Type: tea.String("Secondary"), NetworkInterfaceId: tea.String("eni-secondary")},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+		)
+
+		BeforeEach(func() {
+			providerSpecWithDisks := *providerSpec
+			providerSpecWithDisks.DataDisks = []api.AlicloudDataDisk{
+				{Name: "disk-1", Category: "cloud_essd", Size: 40, DeleteWithInstance: ptr.To(true)},
+				{Name: "disk-2", Category: "cloud_essd", Size: 40, DeleteWithInstance: ptr.To(false)},
 			}
-			getMahineStatusResponse = &driver.GetMachineStatusResponse{
+			providerSpecWithDisksRaw, err := json.Marshal(providerSpecWithDisks)
+			Expect(err).NotTo(HaveOccurred())
+
+			machineClassWithDisks := machineClass.DeepCopy()
+			machineClassWithDisks.ProviderSpec.Raw = providerSpecWithDisksRaw
+
+			deleteMachineRequest = &driver.DeleteMachineRequest{
+				Machine:      machine.DeepCopy(),
+				MachineClass: machineClassWithDisks,
+				Secret:       providerSecret,
+			}
+			describeDisksRequest = &ecs.DescribeDisksRequest{DiskName: tea.String(diskName)}
+			describeNetworkInterfacesRequest = &ecs.DescribeNetworkInterfacesRequest{NetworkInterfaceId: []*string{tea.String("eni-secondary")}}
+
+			oldPollInterval, oldReleaseTimeout := instancePollInterval, resourceReleaseTimeout
+			instancePollInterval, resourceReleaseTimeout = time.Millisecond, 20*time.Millisecond
+			DeferCleanup(func() {
+				instancePollInterval, resourceReleaseTimeout = oldPollInterval, oldReleaseTimeout
+			})
+		})
+
+		It("should delete leaked data disks and secondary network interfaces once they are available", func() {
+			deleteDiskRequest := &ecs.DeleteDiskRequest{DiskId: tea.String("d-disk1")}
+			deleteNetworkInterfaceRequest := &ecs.DeleteNetworkInterfaceRequest{NetworkInterfaceId: tea.String("eni-secondary")}
+
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(deleteMachineRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceWithNetworkInterfacesResponse, nil),
+				mockPluginSPI.EXPECT().NewDeleteInstanceRequest(instanceID, true).Return(deleteInstanceRequest, nil),
+				mockECSClient.EXPECT().DeleteInstance(deleteInstanceRequest).Return(deleteInstanceResponse, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(emptyDescribeInstanceResponse, nil),
+
+				// the network interface is still being detached
+				mockPluginSPI.EXPECT().NewDescribeDisksRequest(diskName, providerSpec.Region, providerSpec.Tags).Return(describeDisksRequest, nil),
+				mockECSClient.EXPECT().DescribeDisks(describeDisksRequest).Return(describeDisksResponseFor("In_use"), nil),
+				mockPluginSPI.EXPECT().NewDescribeNetworkInterfacesRequest([]string{"eni-secondary"}, providerSpec.Region).Return(describeNetworkInterfacesRequest, nil),
+				mockECSClient.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfacesRequest).Return(describeNetworkInterfacesResponseFor("InUse"), nil),
+
+				// the network interface is detached
+				mockPluginSPI.EXPECT().NewDescribeDisksRequest(diskName, providerSpec.Region, providerSpec.Tags).Return(describeDisksRequest, nil),
+				mockECSClient.EXPECT().DescribeDisks(describeDisksRequest).Return(describeDisksResponseFor("Available"), nil),
+				mockPluginSPI.EXPECT().NewDeleteDiskRequest("d-disk1").Return(deleteDiskRequest, nil),
+				mockECSClient.EXPECT().DeleteDisk(deleteDiskRequest).Return(&ecs.DeleteDiskResponse{}, nil),
+				mockPluginSPI.EXPECT().NewDescribeNetworkInterfacesRequest([]string{"eni-secondary"}, providerSpec.Region).Return(describeNetworkInterfacesRequest, nil),
+				mockECSClient.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfacesRequest).Return(describeNetworkInterfacesResponseFor("Available"), nil),
+				mockPluginSPI.EXPECT().NewDeleteNetworkInterfaceRequest("eni-secondary", providerSpec.Region).Return(deleteNetworkInterfaceRequest, nil),
+				mockECSClient.EXPECT().DeleteNetworkInterface(deleteNetworkInterfaceRequest).Return(&ecs.DeleteNetworkInterfaceResponse{}, nil),
+			)
+
+			response, err := mockMachinePlugin.DeleteMachine(ctx, deleteMachineRequest)
+			Expect(err).To(BeNil())
+			Expect(response).To(Equal(&driver.DeleteMachineResponse{
+				LastKnownState: "ECS instance i-mockinstanceid deleted for machine mock-machine-name, leaked data disk(s) [d-disk1] and network interface(s) [eni-secondary] deleted",
+			}))
+		})
+
+		It("should delete data disks leaked by an earlier attempt when no instance exists anymore", func() {
+			deleteMachineRequest.Machine.Spec.ProviderID = ""
+			deleteDiskRequest := &ecs.DeleteDiskRequest{DiskId: tea.String("d-disk1")}
+
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(deleteMachineRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest(machineName, "", providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(emptyDescribeInstanceResponse, nil),
+				mockPluginSPI.EXPECT().NewDescribeDisksRequest(diskName, providerSpec.Region, providerSpec.Tags).Return(describeDisksRequest, nil),
+				mockECSClient.EXPECT().DescribeDisks(describeDisksRequest).Return(describeDisksResponseFor("Available"), nil),
+				mockPluginSPI.EXPECT().NewDeleteDiskRequest("d-disk1").Return(deleteDiskRequest, nil),
+				mockECSClient.EXPECT().DeleteDisk(deleteDiskRequest).Return(&ecs.DeleteDiskResponse{}, nil),
+			)
+
+			response, err := mockMachinePlugin.DeleteMachine(ctx, deleteMachineRequest)
+			Expect(err).To(BeNil())
+			Expect(response).To(Equal(&driver.DeleteMachineResponse{
+				LastKnownState: "leaked data disk(s) [d-disk1] deleted",
+			}))
+		})
+
+		It("should return Unavailable if leaked resources do not become available in time", func() {
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(deleteMachineRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceWithNetworkInterfacesResponse, nil),
+				mockPluginSPI.EXPECT().NewDeleteInstanceRequest(instanceID, true).Return(deleteInstanceRequest, nil),
+				mockECSClient.EXPECT().DeleteInstance(deleteInstanceRequest).Return(deleteInstanceResponse, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(emptyDescribeInstanceResponse, nil),
+			)
+			mockPluginSPI.EXPECT().NewDescribeDisksRequest(diskName, providerSpec.Region, providerSpec.Tags).Return(describeDisksRequest, nil).MinTimes(1)
+			mockECSClient.EXPECT().DescribeDisks(describeDisksRequest).Return(describeDisksResponseFor("In_use"), nil).MinTimes(1)
+			mockPluginSPI.EXPECT().NewDescribeNetworkInterfacesRequest([]string{"eni-secondary"}, providerSpec.Region).Return(describeNetworkInterfacesRequest, nil).MinTimes(1)
+			mockECSClient.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfacesRequest).Return(describeNetworkInterfacesResponseFor("InUse"), nil).MinTimes(1)
+
+			response, err := mockMachinePlugin.DeleteMachine(ctx, deleteMachineRequest)
+			expectStatusCode(err, codes.Unavailable)
+			Expect(err.Error()).To(ContainSubstring("leaked data disk(s) [d-disk1] and network interface(s) [eni-secondary] of machine"))
+			Expect(response).To(Equal(&driver.DeleteMachineResponse{
+				LastKnownState: "ECS instance i-mockinstanceid deleted for machine mock-machine-name, leaked data disk(s) [d-disk1] and network interface(s) [eni-secondary] not yet Available",
+			}))
+		})
+	})
+
+	Describe("#GetMachineStatus", func() {
+		var (
+			getMachineStatusRequest  *driver.GetMachineStatusRequest
+			getMachineStatusResponse = &driver.GetMachineStatusResponse{
 				ProviderID: providerID,
 				NodeName:   nodeName,
 			}
+
+			describeInstanceResponseFor = func(instance *ecs.DescribeInstancesResponseBodyInstancesInstance) *ecs.DescribeInstancesResponse {
+				instance.InstanceId, instance.InstanceName = tea.String(instanceID), tea.String(machineName)
+				return &ecs.DescribeInstancesResponse{
+					Body: &ecs.DescribeInstancesResponseBody{
+						Instances: &ecs.DescribeInstancesResponseBodyInstances{
+							Instance: []*ecs.DescribeInstancesResponseBodyInstancesInstance{instance},
+						},
+					},
+				}
+			}
+			lockedBy = func(lockReason string) *ecs.DescribeInstancesResponseBodyInstancesInstanceOperationLocks {
+				return &ecs.DescribeInstancesResponseBodyInstancesInstanceOperationLocks{
+					LockReason: []*ecs.DescribeInstancesResponseBodyInstancesInstanceOperationLocksLockReason{
+						{LockReason: tea.String(lockReason)},
+					},
+				}
+			}
 		)
 
-		gomock.InOrder(
-			mockPluginSPI.EXPECT().NewECSClient(getMachineStatusRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
-			mockPluginSPI.EXPECT().NewDescribeInstancesRequest(getMachineStatusRequest.Machine.Name, "", providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
-			mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponse, nil),
+		BeforeEach(func() {
+			getMachineStatusRequest = &driver.GetMachineStatusRequest{
+				Machine:      machine.DeepCopy(),
+				MachineClass: machineClass,
+				Secret:       providerSecret,
+			}
+		})
+
+		It("should get machine status by provider ID successfully", func() {
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(getMachineStatusRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponse, nil),
+			)
+
+			response, err := mockMachinePlugin.GetMachineStatus(ctx, getMachineStatusRequest)
+			Expect(err).To(BeNil())
+			Expect(response).To(Equal(getMachineStatusResponse))
+		})
+
+		It("should get machine status by machine name if the provider ID is not set", func() {
+			getMachineStatusRequest.Machine.Spec.ProviderID = ""
+
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(getMachineStatusRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest(machineName, "", providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponse, nil),
+			)
+
+			response, err := mockMachinePlugin.GetMachineStatus(ctx, getMachineStatusRequest)
+			Expect(err).To(BeNil())
+			Expect(response).To(Equal(getMachineStatusResponse))
+		})
+
+		It("should return NotFound if no instance backs the machine", func() {
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(getMachineStatusRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(emptyDescribeInstanceResponse, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest(machineName, "", providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(emptyDescribeInstanceResponse, nil),
+			)
+
+			response, err := mockMachinePlugin.GetMachineStatus(ctx, getMachineStatusRequest)
+			expectStatusCode(err, codes.NotFound)
+			Expect(response).To(BeNil())
+		})
+
+		It("should retry throttled calls, map the Alicloud error code and report the request ID", func() {
+			throttlingErr := tea.NewSDKError(map[string]any{
+				"code":    "Throttling.User",
+				"message": "Request was denied due to user flow control.",
+				"data":    map[string]any{"RequestId": "mock-request-id"},
+			})
+
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(getMachineStatusRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(nil, throttlingErr).Times(2),
+			)
+
+			response, err := mockMachinePlugin.GetMachineStatus(ctx, getMachineStatusRequest)
+			expectStatusCode(err, codes.Unavailable)
+			Expect(err.Error()).To(ContainSubstring("request ID: mock-request-id"))
+			Expect(response).To(BeNil())
+		})
+
+		It("should fall back to the machine name if no instance has the provider ID", func() {
+			describeInstanceByNameRequest := &ecs.DescribeInstancesRequest{InstanceName: tea.String(machineName)}
+
+			gomock.InOrder(
+				mockPluginSPI.EXPECT().NewECSClient(getMachineStatusRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(emptyDescribeInstanceResponse, nil),
+				mockPluginSPI.EXPECT().NewDescribeInstancesRequest(machineName, "", providerSpec.Region, providerSpec.Tags).Return(describeInstanceByNameRequest, nil),
+				mockECSClient.EXPECT().DescribeInstances(describeInstanceByNameRequest).Return(describeInstanceResponse, nil),
+			)
+
+			response, err := mockMachinePlugin.GetMachineStatus(ctx, getMachineStatusRequest)
+			Expect(err).To(BeNil())
+			Expect(response).To(Equal(getMachineStatusResponse))
+		})
+
+		DescribeTable("should report instances which are not ready",
+			func(instance *ecs.DescribeInstancesResponseBodyInstancesInstance, expectedCode codes.Code) {
+				gomock.InOrder(
+					mockPluginSPI.EXPECT().NewECSClient(getMachineStatusRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+					mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+					mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseFor(instance), nil),
+				)
+
+				response, err := mockMachinePlugin.GetMachineStatus(ctx, getMachineStatusRequest)
+				expectStatusCode(err, expectedCode)
+				Expect(response).To(Equal(getMachineStatusResponse))
+			},
+			Entry("pending instance as uninitialized",
+				&ecs.DescribeInstancesResponseBodyInstancesInstance{Status: tea.String("Pending")}, codes.Uninitialized),
+			Entry("starting instance as uninitialized",
+				&ecs.DescribeInstancesResponseBodyInstancesInstance{Status: tea.String("Starting")}, codes.Uninitialized),
+			Entry("recycled spot instance as unavailable",
+				&ecs.DescribeInstancesResponseBodyInstancesInstance{Status: tea.String("Running"), OperationLocks: lockedBy("Recycling")}, codes.Unavailable),
 		)
 
-		response, err := mockMachinePlugin.GetMachineStatus(ctx, getMachineStatusRequest)
-		Expect(err).To(BeNil())
-		Expect(response).To(Equal(getMahineStatusResponse))
+		DescribeTable("should return the status of unhealthy instances, so that they can be deleted",
+			func(instance *ecs.DescribeInstancesResponseBodyInstancesInstance) {
+				gomock.InOrder(
+					mockPluginSPI.EXPECT().NewECSClient(getMachineStatusRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+					mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+					mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstanceResponseFor(instance), nil),
+				)
+
+				response, err := mockMachinePlugin.GetMachineStatus(ctx, getMachineStatusRequest)
+				Expect(err).To(BeNil())
+				Expect(response).To(Equal(getMachineStatusResponse))
+			},
+			Entry("stopping instance", &ecs.DescribeInstancesResponseBodyInstancesInstance{Status: tea.String("Stopping")}),
+			Entry("stopped instance", &ecs.DescribeInstancesResponseBodyInstancesInstance{Status: tea.String("Stopped")}),
+			Entry("instance locked due to overdue payments",
+				&ecs.DescribeInstancesResponseBodyInstancesInstance{Status: tea.String("Stopped"), OperationLocks: lockedBy("financial")}),
+			Entry("expired subscription instance",
+				&ecs.DescribeInstancesResponseBodyInstancesInstance{Status: tea.String("Running"), InstanceChargeType: tea.String("PrePaid"), ExpiredTime: tea.String("2017-12-10T04:04Z")}),
+		)
 	})
 
 	It("should list machines successfully", func() {
@@ -360,6 +1090,52 @@ var _ = Describe("Machine Controller", func() {
 		Expect(response).To(Equal(listMachinesResponse))
 	})
 
+	It("should only list the machines of the machine class and untagged legacy machines", func() {
+		var (
+			listMachinesRequest = &driver.ListMachinesRequest{
+				MachineClass: machineClass,
+				Secret:       providerSecret,
+			}
+			longMachineName = strings.Repeat("m", maxTagValueLength+10)
+			instanceTags    = func(machineClassName, machineName string) *ecs.DescribeInstancesResponseBodyInstancesInstanceTags {
+				return &ecs.DescribeInstancesResponseBodyInstancesInstanceTags{
+					Tag: []*ecs.DescribeInstancesResponseBodyInstancesInstanceTagsTag{
+						{TagKey: tea.String(TagMachineClassName), TagValue: tea.String(machineClassName)},
+						{TagKey: tea.String(TagMachineName), TagValue: tea.String(machineName)},
+					},
+				}
+			}
+			describeInstancesResponse = &ecs.DescribeInstancesResponse{
+				Body: &ecs.DescribeInstancesResponseBody{
+					Instances: &ecs.DescribeInstancesResponseBodyInstances{
+						Instance: []*ecs.DescribeInstancesResponseBodyInstancesInstance{
+							{InstanceId: tea.String("i-legacy"), InstanceName: tea.String("legacy-machine")},
+							{InstanceId: tea.String("i-owned"), InstanceName: tea.String("renamed-instance"), Tags: instanceTags(machineClassName, "owned-machine")},
+							{InstanceId: tea.String("i-foreign"), InstanceName: tea.String("foreign-machine"), Tags: instanceTags("other-machine-class", "foreign-machine")},
+							{InstanceId: tea.String("i-long"), InstanceName: tea.String(longMachineName), Tags: instanceTags(machineClassName, ownershipTagValue(longMachineName))},
+						},
+					},
+				},
+			}
+		)
+
+		gomock.InOrder(
+			mockPluginSPI.EXPECT().NewECSClient(listMachinesRequest.Secret, providerSpec.Region).Return(mockECSClient, nil),
+			mockPluginSPI.EXPECT().NewDescribeInstancesRequest("", "", providerSpec.Region, providerSpec.Tags).Return(describeInstanceRequest, nil),
+			mockECSClient.EXPECT().DescribeInstances(describeInstanceRequest).Return(describeInstancesResponse, nil),
+		)
+
+		response, err := mockMachinePlugin.ListMachines(ctx, listMachinesRequest)
+		Expect(err).To(BeNil())
+		Expect(response).To(Equal(&driver.ListMachinesResponse{
+			MachineList: map[string]string{
+				"cn-shanghai.i-legacy": "legacy-machine",
+				"cn-shanghai.i-owned":  "owned-machine",
+				"cn-shanghai.i-long":   longMachineName,
+			},
+		}))
+	})
+
 	It("should list machines successfully across multiple pages", func() {
 		var (
 			listMachinesRequest = &driver.ListMachinesRequest{
@@ -5,31 +5,187 @@
 package alicloud
 
 import (
-	"encoding/json"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"time"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 
 	api "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis/install"
+	apiv1alpha1 "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis/v1alpha1"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis/validation"
+	maperror "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/errors"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/vpc"
 	"github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 )
 
 const (
 	// ProviderAlicloud string const to identify Alicloud provider
 	ProviderAlicloud = "Alicloud"
+
+	// TagMachineClassName is the key of the tag identifying the MachineClass an ECS instance has been created for
+	TagMachineClassName = "mcm.gardener.cloud/machine-class"
+	// TagMachineName is the key of the tag identifying the Machine an ECS instance has been created for
+	TagMachineName = "mcm.gardener.cloud/machine-name"
+	// TagSpotFallback is the key of the tag marking ECS instances which have been launched as pay-as-you-go instances
+	// as ECS had no spot instances in stock. Its value is the spot strategy the instance has been requested with.
+	TagSpotFallback = "mcm.gardener.cloud/spot-fallback"
+	// maxTagValueLength is the maximum length of tag values ECS accepts
+	maxTagValueLength = 128
+	// tagValueHashLength is the length of the hash suffix of ownership tag values which are truncated
+	tagValueHashLength = 10
+
+	instanceStatusPending  = "Pending"
+	instanceStatusStarting = "Starting"
+	instanceStatusRunning  = "Running"
+	instanceStatusStopping = "Stopping"
+	instanceStatusStopped  = "Stopped"
+
+	instanceChargeTypePrePaid = "PrePaid"
+	// expiredTimeLayout is the layout of the ExpiredTime of ECS instances
+	expiredTimeLayout = "2006-01-02T15:04Z"
+	// lockReasonRecycling is the OperationLocks reason of spot instances which are reclaimed by ECS
+	lockReasonRecycling = "Recycling"
+	// spotInterruptionBehaviorTerminate is the default interruption behaviour of spot instances, i.e. they are released
+	spotInterruptionBehaviorTerminate = "Terminate"
+
+	networkInterfaceTypePrimary   = "Primary"
+	networkInterfaceTypeSecondary = "Secondary"
+
+	diskStatusAvailable             = "Available"
+	networkInterfaceStatusAvailable = "Available"
+)
+
+var (
+	// instancePollInterval is the interval in which the state of an ECS instance is polled while waiting for it
+	instancePollInterval = 5 * time.Second
+	// instanceRunningTimeout is the time InitializeMachine waits for an ECS instance to reach the Running state
+	// before asking MCM to retry the initialization, it is kept short as the wait blocks a worker of MCM
+	instanceRunningTimeout = 10 * time.Second
+	// instanceReleaseTimeout is the time DeleteMachine waits for ECS instances to be released before asking MCM to
+	// retry the deletion, it is kept short as the wait blocks a worker of MCM
+	instanceReleaseTimeout = 10 * time.Second
+	// resourceReleaseTimeout is the time DeleteMachine waits for the data disks and network interfaces of a released
+	// ECS instance to become Available before asking MCM to retry the deletion, it is kept short as the wait blocks a
+	// worker of MCM
+	resourceReleaseTimeout = 10 * time.Second
+
+	scheme = runtime.NewScheme()
+	// decoder decodes ProviderSpecs strictly, i.e. unknown and duplicate fields are rejected
+	decoder runtime.Decoder
+	// defaultProviderSpecGVK is assumed for ProviderSpecs which do not specify an apiVersion
+	defaultProviderSpecGVK = apiv1alpha1.SchemeGroupVersion.WithKind("ProviderSpec")
 )
 
+func init() {
+	install.Install(scheme)
+	decoder = serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDeserializer()
+}
+
+// decodeProviderSpec decodes the ProviderSpec of the given MachineClass according to its apiVersion, applies the
+// defaults of that version and converts it into the internal ProviderSpec.
 func decodeProviderSpec(machineClass *v1alpha1.MachineClass) (*api.ProviderSpec, error) {
-	var providerSpec *api.ProviderSpec
-	err := json.Unmarshal(machineClass.ProviderSpec.Raw, &providerSpec)
+	obj, _, err := decoder.Decode(machineClass.ProviderSpec.Raw, &defaultProviderSpecGVK, nil)
 	if err != nil {
+		errMessage := fmt.Sprintf("failed to decode ProviderSpec of MachineClass %q: %v", machineClass.Name, err)
+		return nil, status.Error(codes.InvalidArgument, errMessage)
+	}
+	scheme.Default(obj)
+
+	providerSpec := &api.ProviderSpec{}
+	if err := scheme.Convert(obj, providerSpec, nil); err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	return providerSpec, nil
 }
 
+// decodeProviderSpecAndSecret decodes the ProviderSpec of the given MachineClass and validates it together with
+// the Secret. Validation failures are reported as InvalidArgument so that no cloud call is made with a broken spec.
+func decodeProviderSpecAndSecret(machineClass *v1alpha1.MachineClass, secret *corev1.Secret) (*api.ProviderSpec, error) {
+	providerSpec, err := decodeProviderSpec(machineClass)
+	if err != nil {
+		return nil, err
+	}
+
+	if errs := validation.ValidateProviderSpecNSecret(providerSpec, secret); len(errs) > 0 {
+		err := fmt.Errorf("error while validating ProviderSpec of MachineClass %q: %v", machineClass.Name, utilerrors.NewAggregate(errs))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return providerSpec, nil
+}
+
+// validateUserData validates that the Secret contains the user data needed to launch an instance. Failures are
+// reported as InvalidArgument like the ones of decodeProviderSpecAndSecret.
+func validateUserData(machineClass *v1alpha1.MachineClass, secret *corev1.Secret) error {
+	if errs := validation.ValidateUserData(secret); len(errs) > 0 {
+		err := fmt.Errorf("error while validating Secret of MachineClass %q: %v", machineClass.Name, utilerrors.NewAggregate(errs))
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// newClientToken derives the ClientToken of the RunInstances request from the identity of the machine and the hash of
+// its MachineClass' ProviderSpec. Retried CreateMachine calls for the same machine therefore send the same token and
+// ECS returns the instance launched by the first call instead of launching a duplicate.
+func newClientToken(machine *v1alpha1.Machine, machineClass *v1alpha1.MachineClass) string {
+	identity := machine.Name
+	if machine.UID != "" {
+		identity = string(machine.UID)
+	}
+
+	specHash := sha256.Sum256(machineClass.ProviderSpec.Raw)
+	// the hex encoded SHA-256 sum has 64 characters, the maximum length of a ClientToken
+	token := sha256.Sum256([]byte(machine.Namespace + "/" + identity + "/" + hex.EncodeToString(specHash[:])))
+	return hex.EncodeToString(token[:])
+}
+
+// fallbackClientToken returns the ClientToken of a RunInstances call falling back from the call with the given token,
+// as ECS rejects calls reusing a token with different parameters.
+func fallbackClientToken(clientToken, fallback string) string {
+	token := sha256.Sum256([]byte(clientToken + "/" + fallback))
+	return hex.EncodeToString(token[:])
+}
+
+// withOwnershipTags returns a copy of the given tags extended by the tags identifying the MachineClass and the Machine
+// an ECS instance belongs to.
+func withOwnershipTags(tags map[string]string, machineClassName, machineName string) map[string]string {
+	ownedTags := make(map[string]string, len(tags)+2)
+	maps.Copy(ownedTags, tags)
+	ownedTags[TagMachineClassName] = ownershipTagValue(machineClassName)
+	ownedTags[TagMachineName] = ownershipTagValue(machineName)
+	return ownedTags
+}
+
+// ownershipTagValue returns the value of the ownership tag for the given name of a MachineClass or Machine. Names
+// longer than ECS accepts for tag values are truncated and suffixed by their hash, so that they stay unique.
+func ownershipTagValue(name string) string {
+	if len(name) <= maxTagValueLength {
+		return name
+	}
+	hash := sha256.Sum256([]byte(name))
+	return name[:maxTagValueLength-tagValueHashLength-1] + "-" + hex.EncodeToString(hash[:])[:tagValueHashLength]
+}
+
 func encodeProviderID(region, instanceID string) string {
 	return fmt.Sprintf("%s.%s", region, instanceID)
 }
@@ -44,3 +200,746 @@ func decodeProviderID(providerID string) string {
 func instanceIDToName(instanceID string) string {
 	return strings.Replace(instanceID, "-", "z", 1) + "z"
 }
+
+// waitForInstanceRunning polls the ECS instance backing the given machine until it reaches the Running state. The
+// instance is looked up by the instance ID encoded in the ProviderID or, if not set yet, by the machine name. As
+// freshly created instances may not be visible immediately, a missing instance is polled for as well.
+func (plugin *MachinePlugin) waitForInstanceRunning(ctx context.Context, client spi.ECSClient, machine *v1alpha1.Machine, providerSpec *api.ProviderSpec) (*ecs.DescribeInstancesResponseBodyInstancesInstance, error) {
+	var (
+		machineName = machine.Name
+		instanceID  string
+		instance    *ecs.DescribeInstancesResponseBodyInstancesInstance
+	)
+	if machine.Spec.ProviderID != "" {
+		machineName, instanceID = "", decodeProviderID(machine.Spec.ProviderID)
+	}
+
+	err := wait.PollUntilContextTimeout(ctx, instancePollInterval, instanceRunningTimeout, true, func(_ context.Context) (bool, error) {
+		request, err := plugin.SPI.NewDescribeInstancesRequest(machineName, instanceID, providerSpec.Region, providerSpec.Tags)
+		if err != nil {
+			return false, status.Error(codes.Internal, err.Error())
+		}
+
+		instances, err := plugin.GetAllInstances(client, request)
+		if err != nil {
+			klog.Errorf("error while fetching instance details for machine %q: %v", machine.Name, err)
+			return false, maperror.ToMCMError(err, fmt.Sprintf("failed to fetch ECS instance of machine %q", machine.Name))
+		}
+
+		switch len(instances) {
+		case 0:
+			klog.V(3).Infof("No ECS instance visible yet for machine %q", machine.Name)
+			return false, nil
+		case 1:
+			instance = instances[0]
+		default:
+			errMessage := fmt.Sprintf("multiple VM instances found backing machine %q", machine.Name)
+			return false, status.Error(codes.OutOfRange, errMessage)
+		}
+
+		if err := instanceHealthError(instance, machine.Name); err != nil {
+			return false, err
+		}
+
+		switch instanceStatus := ptr.Deref(instance.Status, ""); instanceStatus {
+		case instanceStatusRunning:
+			return true, nil
+		case instanceStatusPending, instanceStatusStarting:
+			klog.V(3).Infof("ECS instance %q for machine %q is %s, waiting for it to be Running", *instance.InstanceId, machine.Name, instanceStatus)
+			return false, nil
+		default:
+			errMessage := fmt.Sprintf("ECS instance %q for machine %q is %s and will not become Running", *instance.InstanceId, machine.Name, instanceStatus)
+			return false, status.Error(codes.Unavailable, errMessage)
+		}
+	})
+	if err == nil {
+		return instance, nil
+	}
+	if wait.Interrupted(err) {
+		errMessage := fmt.Sprintf("ECS instance for machine %q did not reach the Running state within %s", machine.Name, instanceRunningTimeout)
+		return nil, status.Error(codes.Uninitialized, errMessage)
+	}
+
+	return nil, err
+}
+
+// spotInstanceReclaimedError returns the error GetMachineStatus reports for a spot instance which is reclaimed by ECS,
+// i.e. which is released or stopped depending on its interruption behaviour
+func spotInstanceReclaimedError(instance *ecs.DescribeInstancesResponseBodyInstancesInstance, machineName string) error {
+	errMessage := fmt.Sprintf("ECS spot instance %q backing machine %q is reclaimed by ECS (spot strategy %s, interruption behaviour %s) and has to be replaced",
+		*instance.InstanceId, machineName, ptr.Deref(instance.SpotStrategy, "unknown"), ptr.Deref(instance.SpotInterruptionBehavior, spotInterruptionBehaviorTerminate))
+	return status.Error(codes.Unavailable, errMessage)
+}
+
+// instanceStatusError returns the error GetMachineStatus reports for an ECS instance, or nil if its status is to be
+// returned as is. MCM calls GetMachineStatus in its deletion flow as well, which only proceeds if no error or NotFound
+// is returned, so that the status of Stopped, locked or expired instances is returned with their node name. Their
+// health is checked by InitializeMachine (see instanceHealthError) and by the health checks of their nodes instead:
+//   - Pending or Starting instances are Uninitialized, so that MCM calls InitializeMachine
+//   - spot instances which are reclaimed by ECS are Unavailable but reported distinctly, as they do not recover and
+//     have to be replaced. ECS locks them only until they are released or stopped, so that a deletion is delayed by
+//     the error only briefly.
+func instanceStatusError(instance *ecs.DescribeInstancesResponseBodyInstancesInstance, machineName string) error {
+	if slices.ContainsFunc(GetInstanceLockReasons(instance), func(lockReason string) bool {
+		return strings.EqualFold(lockReason, lockReasonRecycling)
+	}) {
+		return spotInstanceReclaimedError(instance, machineName)
+	}
+
+	switch instanceStatus := ptr.Deref(instance.Status, ""); instanceStatus {
+	case instanceStatusPending, instanceStatusStarting:
+		// MCM calls InitializeMachine for uninitialized machines, which waits for the instance to become Running
+		errMessage := fmt.Sprintf("ECS instance %q backing machine %q is %s", *instance.InstanceId, machineName, instanceStatus)
+		return status.Error(codes.Uninitialized, errMessage)
+	}
+
+	return nil
+}
+
+// instanceHealthError returns the error InitializeMachine reports for an ECS instance which cannot serve as node until
+// the user intervenes, or nil if there is none. Expired subscriptions and instances locked e.g. due to overdue
+// payments or for security reasons are reported as FailedPrecondition.
+func instanceHealthError(instance *ecs.DescribeInstancesResponseBodyInstancesInstance, machineName string) error {
+	instanceID := *instance.InstanceId
+
+	if lockReasons := GetInstanceLockReasons(instance); len(lockReasons) > 0 {
+		errMessage := fmt.Sprintf("ECS instance %q backing machine %q is locked: %v", instanceID, machineName, lockReasons)
+		return status.Error(codes.FailedPrecondition, errMessage)
+	}
+
+	if ptr.Deref(instance.InstanceChargeType, "") == instanceChargeTypePrePaid && instance.ExpiredTime != nil {
+		if expiredTime, err := time.Parse(expiredTimeLayout, *instance.ExpiredTime); err == nil && expiredTime.Before(time.Now()) {
+			errMessage := fmt.Sprintf("subscription of ECS instance %q backing machine %q expired at %s", instanceID, machineName, *instance.ExpiredTime)
+			return status.Error(codes.FailedPrecondition, errMessage)
+		}
+	}
+
+	return nil
+}
+
+// logUnhealthyInstance logs the health issues of an ECS instance whose status GetMachineStatus returns nevertheless.
+func logUnhealthyInstance(instance *ecs.DescribeInstancesResponseBodyInstancesInstance, machineName string) {
+	if err := instanceHealthError(instance, machineName); err != nil {
+		klog.Warningf("Machine %q is not healthy, reporting its status nevertheless: %v", machineName, err)
+	} else if instanceStatus := ptr.Deref(instance.Status, ""); instanceStatus != instanceStatusRunning {
+		klog.Warningf("ECS instance %q backing machine %q is %s, reporting its status nevertheless", *instance.InstanceId, machineName, instanceStatus)
+	}
+}
+
+// getExistingInstance returns the ECS instance already launched for the given machine, or nil if there is none. Like
+// all lookups by machine name, it only finds instances with the cluster and role tags of the ProviderSpec. Instances
+// of another MachineClass and instances which are Stopping or reclaimed by ECS, i.e. which are being released, are
+// not adopted.
+func (plugin *MachinePlugin) getExistingInstance(client spi.ECSClient, machineName, machineClassName string, providerSpec *api.ProviderSpec) (*ecs.DescribeInstancesResponseBodyInstancesInstance, error) {
+	request, err := plugin.SPI.NewDescribeInstancesRequest(machineName, "", providerSpec.Region, providerSpec.Tags)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	found, err := plugin.GetAllInstances(client, request)
+	if err != nil {
+		klog.Errorf("error while fetching instance details for machine %q: %v", machineName, err)
+		return nil, maperror.ToMCMError(err, fmt.Sprintf("failed to fetch ECS instances of machine %q", machineName))
+	}
+
+	var instances []*ecs.DescribeInstancesResponseBodyInstancesInstance
+	for _, instance := range found {
+		instanceTags := GetInstanceTags(instance)
+		if name, ok := instanceTags[TagMachineClassName]; ok && name != ownershipTagValue(machineClassName) {
+			klog.V(4).Infof("Skipping ECS instance %q owned by machine class %q", *instance.InstanceId, name)
+			continue
+		}
+		if isInstanceBeingReleased(instance) {
+			klog.V(3).Infof("Skipping ECS instance %q of machine %q, which is being released", *instance.InstanceId, machineName)
+			continue
+		}
+		instances = append(instances, instance)
+	}
+
+	switch len(instances) {
+	case 0:
+		return nil, nil
+	case 1:
+		return instances[0], nil
+	default:
+		var instanceIDs []string
+		for _, instance := range instances {
+			instanceIDs = append(instanceIDs, *instance.InstanceId)
+		}
+		errMessage := fmt.Sprintf("multiple VM instances found backing machine %q. IDs for all backing VMs - %v", machineName, instanceIDs)
+		return nil, status.Error(codes.OutOfRange, errMessage)
+	}
+}
+
+// isInstanceBeingReleased returns true if the given ECS instance is Stopping, as instances are when they are
+// deleted, or reclaimed by ECS as spot instance.
+func isInstanceBeingReleased(instance *ecs.DescribeInstancesResponseBodyInstancesInstance) bool {
+	if ptr.Deref(instance.Status, "") == instanceStatusStopping {
+		return true
+	}
+	for _, lockReason := range GetInstanceLockReasons(instance) {
+		if strings.EqualFold(lockReason, lockReasonRecycling) {
+			return true
+		}
+	}
+	return false
+}
+
+// runInstanceOfAnyType sends the given RunInstances request and, as long as ECS has no capacity for the instance type,
+// sends it again with the next one of the given instance types. It returns the response and the instance type of the
+// launched instance, or the error of the last call.
+func runInstanceOfAnyType(client spi.ECSClient, request *ecs.RunInstancesRequest, instanceTypes []string, machineName string) (*ecs.RunInstancesResponse, string, error) {
+	instanceType := ptr.Deref(request.InstanceType, "")
+	response, err := client.RunInstances(request)
+
+	for _, next := range instanceTypes {
+		if err == nil || !maperror.IsInstanceTypeUnavailable(err) {
+			break
+		}
+		if next == ptr.Deref(request.InstanceType, "") {
+			continue
+		}
+		klog.V(2).Infof("ECS cannot launch instance type %s for machine %q (%s), trying instance type %s", instanceType, machineName, maperror.GetErrorMessage(err), next)
+
+		alternative := *request
+		alternative.InstanceType = &next
+		alternative.ClientToken = ptr.To(fallbackClientToken(ptr.Deref(request.ClientToken, ""), next))
+		instanceType = next
+		response, err = client.RunInstances(&alternative)
+	}
+
+	return response, instanceType, err
+}
+
+// shouldFallBackToOnDemand returns true if the given error of RunInstances reports that ECS has no spot instances in
+// stock and the spot fallback of the ProviderSpec allows another pay-as-you-go instance among the instances of the
+// MachineClass. If the share of pay-as-you-go instances cannot be determined, no instance falls back.
+func (plugin *MachinePlugin) shouldFallBackToOnDemand(client spi.ECSClient, providerSpec *api.ProviderSpec, machineClassName string, err error) bool {
+	if providerSpec.SpotFallback == nil || !maperror.HasErrorCode(err, maperror.OperationDeniedNoStock) {
+		return false
+	}
+	maxPercentage := providerSpec.SpotFallback.MaxPercentage
+	if maxPercentage == nil {
+		return true
+	}
+
+	request, err := plugin.SPI.NewDescribeInstancesRequest("", "", providerSpec.Region, providerSpec.Tags)
+	if err != nil {
+		klog.Warningf("Failed to fetch the ECS instances of machine class %q to determine their spot fallbacks: %v", machineClassName, err)
+		return false
+	}
+	instances, err := plugin.GetAllInstances(client, request)
+	if err != nil {
+		klog.Warningf("Failed to fetch the ECS instances of machine class %q to determine their spot fallbacks: %v", machineClassName, err)
+		return false
+	}
+
+	var total, fallbacks int
+	for _, instance := range instances {
+		instanceTags := GetInstanceTags(instance)
+		if instanceTags[TagMachineClassName] != ownershipTagValue(machineClassName) {
+			continue
+		}
+		total++
+		if _, ok := instanceTags[TagSpotFallback]; ok {
+			fallbacks++
+		}
+	}
+	// the instance to launch counts as well
+	if (fallbacks+1)*100 > *maxPercentage*(total+1) {
+		klog.V(2).Infof("%d of %d ECS instance(s) of machine class %q already fell back to pay-as-you-go instances, at most %d%% may", fallbacks, total, machineClassName, *maxPercentage)
+		return false
+	}
+	return true
+}
+
+// onDemandProviderSpec returns a copy of the given ProviderSpec of spot instances which launches pay-as-you-go
+// instances instead. They are tagged with the spot strategy they fall back from.
+func onDemandProviderSpec(providerSpec *api.ProviderSpec) *api.ProviderSpec {
+	onDemand := *providerSpec
+	onDemand.SpotStrategy = api.SpotStrategyNoSpot
+	onDemand.SpotPriceLimit, onDemand.SpotDuration, onDemand.SpotInterruptionBehavior = nil, nil, ""
+	onDemand.Tags = maps.Clone(providerSpec.Tags)
+	onDemand.Tags[TagSpotFallback] = providerSpec.SpotStrategy
+	return &onDemand
+}
+
+// requestsIPv6Addresses returns true if IPv6 addresses are assigned to any network interface of the ProviderSpec.
+func requestsIPv6Addresses(providerSpec *api.ProviderSpec) bool {
+	if providerSpec.IPv6AddressCount != nil || len(providerSpec.IPv6Addresses) > 0 {
+		return true
+	}
+	for _, networkInterface := range providerSpec.NetworkInterfaces {
+		if networkInterface.IPv6AddressCount != nil || len(networkInterface.IPv6Addresses) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// validateIPv6VSwitches checks that the vSwitch of every network interface of the ProviderSpec which IPv6 addresses are
+// assigned to has an IPv6 CIDR block. Otherwise, an InvalidArgument error naming the vSwitchID fields is returned, so
+// that the misconfiguration is reported before RunInstances is called. The VPC client is only created if there are
+// such network interfaces.
+func (plugin *MachinePlugin) validateIPv6VSwitches(ctx context.Context, secret *corev1.Secret, providerSpec *api.ProviderSpec, machineClassName string) error {
+	type ipv6VSwitch struct {
+		fldPath *field.Path
+		id      string
+	}
+	fldPath := field.NewPath("providerSpec")
+	var vSwitches []ipv6VSwitch
+	if providerSpec.IPv6AddressCount != nil || len(providerSpec.IPv6Addresses) > 0 {
+		vSwitches = append(vSwitches, ipv6VSwitch{fldPath.Child("vSwitchID"), providerSpec.VSwitchID})
+	}
+	for i, networkInterface := range providerSpec.NetworkInterfaces {
+		if networkInterface.IPv6AddressCount != nil || len(networkInterface.IPv6Addresses) > 0 {
+			vSwitches = append(vSwitches, ipv6VSwitch{fldPath.Child("networkInterfaces").Index(i).Child("vSwitchID"), networkInterface.VSwitchID})
+		}
+	}
+	if len(vSwitches) == 0 {
+		return nil
+	}
+
+	client, err := plugin.newVPCClient(ctx, secret, providerSpec.Region)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	// network interfaces often share a vSwitch, which is described only once
+	ipv6CidrBlocks := map[string]*string{}
+	var allErrs field.ErrorList
+	for _, vSwitch := range vSwitches {
+		ipv6CidrBlock, ok := ipv6CidrBlocks[vSwitch.id]
+		if !ok {
+			described, err := plugin.describeVSwitch(client, vSwitch.id, providerSpec.Region)
+			if err != nil {
+				return err
+			}
+			if described != nil {
+				ipv6CidrBlock = ptr.To(ptr.Deref(described.Ipv6CidrBlock, ""))
+			}
+			ipv6CidrBlocks[vSwitch.id] = ipv6CidrBlock
+		}
+		switch {
+		case ipv6CidrBlock == nil:
+			allErrs = append(allErrs, field.NotFound(vSwitch.fldPath, vSwitch.id))
+		case *ipv6CidrBlock == "":
+			allErrs = append(allErrs, field.Invalid(vSwitch.fldPath, vSwitch.id, "vSwitch has no IPv6 CIDR block, hence no IPv6 addresses can be assigned"))
+		}
+	}
+
+	if len(allErrs) > 0 {
+		err := fmt.Errorf("error while validating ProviderSpec of MachineClass %q: %v", machineClassName, allErrs.ToAggregate())
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// describeVSwitch returns the vSwitch with the given ID or nil if there is no such vSwitch in the region
+func (plugin *MachinePlugin) describeVSwitch(client spi.VPCClient, vSwitchID, region string) (*vpc.DescribeVSwitchesResponseBodyVSwitchesVSwitch, error) {
+	request, err := plugin.SPI.NewDescribeVSwitchesRequest(vSwitchID, region)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	response, err := client.DescribeVSwitches(request)
+	if err != nil {
+		return nil, maperror.ToMCMError(err, fmt.Sprintf("failed to fetch vSwitch %q", vSwitchID))
+	}
+	if response.Body == nil || response.Body.VSwitches == nil {
+		return nil, nil
+	}
+	for _, vSwitch := range response.Body.VSwitches.VSwitch {
+		if ptr.Deref(vSwitch.VSwitchId, "") == vSwitchID {
+			return vSwitch, nil
+		}
+	}
+	return nil, nil
+}
+
+// withIPv6Addresses appends the IPv6 addresses assigned to the network interfaces of the instance to the last known
+// state of a CreateMachine call.
+func withIPv6Addresses(lastKnownState string, instance *ecs.DescribeInstancesResponseBodyInstancesInstance) string {
+	ipv6Addresses := GetInstanceIPv6Addresses(instance, "")
+	if len(ipv6Addresses) == 0 {
+		return lastKnownState
+	}
+	return fmt.Sprintf("%s with IPv6 address(es) %v", lastKnownState, ipv6Addresses)
+}
+
+// withAssignedIPv6Addresses fetches the instance with the given ID and appends its IPv6 addresses to the last known
+// state of a CreateMachine call. The last known state is returned unchanged if the instance cannot be fetched, as the
+// instance has been created nevertheless.
+func (plugin *MachinePlugin) withAssignedIPv6Addresses(client spi.ECSClient, lastKnownState, instanceID string, providerSpec *api.ProviderSpec) string {
+	request, err := plugin.SPI.NewDescribeInstancesRequest("", instanceID, providerSpec.Region, nil)
+	if err != nil {
+		return lastKnownState
+	}
+
+	instances, err := plugin.GetAllInstances(client, request)
+	if err != nil || len(instances) != 1 {
+		klog.V(3).Infof("Could not fetch the IPv6 addresses of ECS instance %q: %v", instanceID, err)
+		return lastKnownState
+	}
+	return withIPv6Addresses(lastKnownState, instances[0])
+}
+
+// assignSecondaryIPv6Addresses assigns the IPv6 addresses of the secondary network interfaces of the ProviderSpec, which
+// ECS does not assign at launch, to the network interfaces attached to the instance and returns the assigned addresses.
+// Network interfaces which have IPv6 addresses already are skipped, so that repeated initializations assign them once.
+func (plugin *MachinePlugin) assignSecondaryIPv6Addresses(client spi.ECSClient, machineName string, providerSpec *api.ProviderSpec, instance *ecs.DescribeInstancesResponseBodyInstancesInstance) ([]string, error) {
+	var configured []api.AlicloudNetworkInterface
+	for _, networkInterface := range providerSpec.NetworkInterfaces {
+		if networkInterface.Type != api.NetworkInterfaceTypePrimary && (networkInterface.IPv6AddressCount != nil || len(networkInterface.IPv6Addresses) > 0) {
+			configured = append(configured, networkInterface)
+		}
+	}
+	if len(configured) == 0 {
+		return nil, nil
+	}
+
+	instanceID := *instance.InstanceId
+	networkInterfaceIDs := GetSecondaryNetworkInterfaceIDs(instance)
+	if len(networkInterfaceIDs) == 0 {
+		errMessage := fmt.Sprintf("secondary network interfaces of ECS instance %q are not attached yet", instanceID)
+		return nil, status.Error(codes.Uninitialized, errMessage)
+	}
+	describeNetworkInterfacesRequest, err := plugin.SPI.NewDescribeNetworkInterfacesRequest(networkInterfaceIDs, providerSpec.Region)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	describeNetworkInterfacesResponse, err := client.DescribeNetworkInterfaces(describeNetworkInterfacesRequest)
+	if err != nil {
+		return nil, maperror.ToMCMError(err, fmt.Sprintf("failed to fetch network interfaces %v of ECS instance %q", networkInterfaceIDs, instanceID))
+	}
+	networkInterfaces, err := GetNetworkInterfacesFromDescribeNetworkInterfacesResponse(describeNetworkInterfacesResponse)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	var assigned []string
+	for _, networkInterface := range configured {
+		networkInterfaceName := spi.NetworkInterfaceName(machineName, networkInterface.Name)
+		index := slices.IndexFunc(networkInterfaces, func(attached *ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSet) bool {
+			return ptr.Deref(attached.NetworkInterfaceName, "") == networkInterfaceName
+		})
+		if index < 0 {
+			errMessage := fmt.Sprintf("network interface %q of ECS instance %q is not attached yet", networkInterfaceName, instanceID)
+			return nil, status.Error(codes.Uninitialized, errMessage)
+		}
+		attached := networkInterfaces[index]
+		if attached.Ipv6Sets != nil && len(attached.Ipv6Sets.Ipv6Set) > 0 {
+			continue
+		}
+
+		networkInterfaceID := ptr.Deref(attached.NetworkInterfaceId, "")
+		assignIpv6AddressesRequest, err := plugin.SPI.NewAssignIpv6AddressesRequest(networkInterfaceID, providerSpec.Region, networkInterface.IPv6AddressCount, networkInterface.IPv6Addresses)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		assignIpv6AddressesResponse, err := client.AssignIpv6Addresses(assignIpv6AddressesRequest)
+		if err != nil {
+			return nil, maperror.ToMCMError(err, fmt.Sprintf("failed to assign IPv6 addresses to network interface %q of ECS instance %q", networkInterfaceID, instanceID))
+		}
+		if body := assignIpv6AddressesResponse.Body; body != nil && body.Ipv6Sets != nil {
+			for _, address := range body.Ipv6Sets.Ipv6Address {
+				if ptr.Deref(address, "") != "" {
+					assigned = append(assigned, *address)
+				}
+			}
+		}
+		klog.V(3).Infof("Assigned IPv6 addresses to network interface %q of ECS instance %q", networkInterfaceID, instanceID)
+	}
+
+	return assigned, nil
+}
+
+// releaseInstances deletes the given ECS instances and polls until ECS has released all of them, so that the machine
+// is only considered deleted once its VMs are really gone. Instances which are Stopping are waited for, all others are
+// force deleted. Pending and Starting instances are force deleted as soon as ECS accepts it, instances which are
+// already released count as deleted. The poll is brief, instances which are not released by then are reported as
+// Unavailable and waited for again by the next deletion attempt of MCM.
+func (plugin *MachinePlugin) releaseInstances(ctx context.Context, client spi.ECSClient, machineName string, providerSpec *api.ProviderSpec, instances []*ecs.DescribeInstancesResponseBodyInstancesInstance) error {
+	var (
+		instanceIDs = make([]string, 0, len(instances))
+		// known holds the instances whose state is known from the last lookup
+		known        = make(map[string]*ecs.DescribeInstancesResponseBodyInstancesInstance, len(instances))
+		deleteIssued = sets.New[string]()
+		released     = sets.New[string]()
+	)
+	for _, instance := range instances {
+		instanceIDs = append(instanceIDs, *instance.InstanceId)
+		known[*instance.InstanceId] = instance
+	}
+
+	err := wait.PollUntilContextTimeout(ctx, instancePollInterval, instanceReleaseTimeout, true, func(_ context.Context) (bool, error) {
+		for _, instanceID := range instanceIDs {
+			if released.Has(instanceID) {
+				continue
+			}
+
+			instance, ok := known[instanceID]
+			if !ok {
+				request, err := plugin.SPI.NewDescribeInstancesRequest("", instanceID, providerSpec.Region, providerSpec.Tags)
+				if err != nil {
+					return false, status.Error(codes.Internal, err.Error())
+				}
+				found, err := plugin.GetAllInstances(client, request)
+				if err != nil {
+					klog.Errorf("error while fetching instance details for instanceID %q: %v", instanceID, err)
+					return false, maperror.ToMCMError(err, fmt.Sprintf("failed to fetch ECS instance %q", instanceID))
+				}
+				if len(found) == 0 {
+					klog.V(3).Infof("ECS instance %q released for machine %q", instanceID, machineName)
+					released.Insert(instanceID)
+					continue
+				}
+				instance = found[0]
+			}
+			delete(known, instanceID)
+
+			instanceStatus := ptr.Deref(instance.Status, "")
+			if instanceStatus == instanceStatusStopping || deleteIssued.Has(instanceID) {
+				klog.V(3).Infof("ECS instance %q for machine %q is %s, waiting for it to be released", instanceID, machineName, instanceStatus)
+				continue
+			}
+
+			deleteInstanceRequest, err := plugin.SPI.NewDeleteInstanceRequest(instanceID, true)
+			if err != nil {
+				return false, status.Error(codes.Internal, err.Error())
+			}
+			_, err = client.DeleteInstance(deleteInstanceRequest)
+			switch {
+			case err == nil:
+				klog.V(3).Infof("ECS instance %q deleted for machine %q", instanceID, machineName)
+				deleteIssued.Insert(instanceID)
+			case maperror.HasErrorCode(err, maperror.InvalidInstanceIDNotFound):
+				klog.V(3).Infof("ECS instance %q already released for machine %q", instanceID, machineName)
+				released.Insert(instanceID)
+			case maperror.HasErrorCode(err, maperror.IncorrectInstanceStatus):
+				// e.g. a Pending instance which ECS does not allow to release yet
+				klog.V(3).Infof("ECS instance %q for machine %q cannot be deleted while %s, retrying", instanceID, machineName, instanceStatus)
+			default:
+				return false, maperror.ToMCMError(err, fmt.Sprintf("failed to delete ECS instance %q", instanceID))
+			}
+		}
+
+		return released.Len() == len(instanceIDs), nil
+	})
+	if wait.Interrupted(err) {
+		errMessage := fmt.Sprintf("%d of %d ECS instance(s) %v of machine %q not released within %s", len(instanceIDs)-released.Len(), len(instanceIDs), instanceIDs, machineName, instanceReleaseTimeout)
+		return status.Error(codes.Unavailable, errMessage)
+	}
+
+	return err
+}
+
+// leakedResources are the data disks and secondary network interfaces of a machine which were not released together
+// with its ECS instance. The deleted ones have been deleted by the driver, the pending ones are still in use and left to
+// the next deletion attempt.
+type leakedResources struct {
+	disks                    []string
+	networkInterfaces        []string
+	pendingDisks             []string
+	pendingNetworkInterfaces []string
+}
+
+func (r *leakedResources) String() string {
+	var parts []string
+	if deleted := describeResources(r.disks, r.networkInterfaces); deleted != "" {
+		parts = append(parts, "leaked "+deleted+" deleted")
+	}
+	if pending := describeResources(r.pendingDisks, r.pendingNetworkInterfaces); pending != "" {
+		parts = append(parts, "leaked "+pending+" not yet Available")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeResources lists the given data disks and network interfaces for the last known state.
+func describeResources(disks, networkInterfaces []string) string {
+	var parts []string
+	if len(disks) > 0 {
+		parts = append(parts, fmt.Sprintf("data disk(s) %v", disks))
+	}
+	if len(networkInterfaces) > 0 {
+		parts = append(parts, fmt.Sprintf("network interface(s) %v", networkInterfaces))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// withLeakedResources appends the deleted and pending leaked resources to the last known state of a DeleteMachine call.
+func withLeakedResources(lastKnownState string, leaked *leakedResources) string {
+	cleaned := leaked.String()
+	switch {
+	case cleaned == "":
+		return lastKnownState
+	case lastKnownState == "":
+		return cleaned
+	default:
+		return lastKnownState + ", " + cleaned
+	}
+}
+
+// deleteLeakedResources deletes the data disks and the given secondary network interfaces a machine leaves behind
+// once its ECS instance is released. Data disks and the configured secondary network interfaces are looked up by the
+// names assigned by NewInstanceDataDisks and NewInstanceNetworkInterfaces and the cluster tags, so resources leaked by
+// earlier deletion attempts are found as well. Resources still attached to the releasing instance are polled briefly
+// until ECS either releases them together with the instance or reports them as Available, which is when they can be
+// deleted. Resources which are still in use then are reported as pending and MCM is asked to retry the deletion.
+func (plugin *MachinePlugin) deleteLeakedResources(ctx context.Context, client spi.ECSClient, machineName string, providerSpec *api.ProviderSpec, networkInterfaceIDs []string) (*leakedResources, error) {
+	leaked := &leakedResources{}
+
+	networkInterfaceIDs, err := plugin.findNetworkInterfaces(client, machineName, providerSpec, networkInterfaceIDs)
+	if err != nil {
+		return leaked, err
+	}
+
+	err = wait.PollUntilContextTimeout(ctx, instancePollInterval, resourceReleaseTimeout, true, func(_ context.Context) (bool, error) {
+		leaked.pendingDisks, leaked.pendingNetworkInterfaces = nil, nil
+		if err := plugin.deleteAvailableDisks(client, machineName, providerSpec, leaked); err != nil {
+			return false, err
+		}
+		if err := plugin.deleteAvailableNetworkInterfaces(client, networkInterfaceIDs, providerSpec.Region, leaked); err != nil {
+			return false, err
+		}
+
+		if pending := len(leaked.pendingDisks) + len(leaked.pendingNetworkInterfaces); pending > 0 {
+			klog.V(3).Infof("Waiting for %d leaked resource(s) of machine %q to become Available", pending, machineName)
+			return false, nil
+		}
+		return true, nil
+	})
+	if wait.Interrupted(err) {
+		errMessage := fmt.Sprintf("leaked %s of machine %q did not become Available within %s", describeResources(leaked.pendingDisks, leaked.pendingNetworkInterfaces), machineName, resourceReleaseTimeout)
+		return leaked, status.Error(codes.Unavailable, errMessage)
+	}
+
+	return leaked, err
+}
+
+// deleteAvailableDisks deletes the Available data disks of a machine and records the deleted disks and those still in
+// use. Data disks which are not to be deleted together with the instance are kept.
+func (plugin *MachinePlugin) deleteAvailableDisks(client spi.ECSClient, machineName string, providerSpec *api.ProviderSpec, leaked *leakedResources) error {
+	for _, dataDisk := range providerSpec.DataDisks {
+		if !ptr.Deref(dataDisk.DeleteWithInstance, false) {
+			continue
+		}
+
+		diskName := spi.DataDiskName(machineName, dataDisk.Name)
+		describeDisksRequest, err := plugin.SPI.NewDescribeDisksRequest(diskName, providerSpec.Region, providerSpec.Tags)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		describeDisksResponse, err := client.DescribeDisks(describeDisksRequest)
+		if err != nil {
+			klog.Errorf("error while fetching data disk %q of machine %q: %v", diskName, machineName, err)
+			return maperror.ToMCMError(err, fmt.Sprintf("failed to fetch data disk %q", diskName))
+		}
+		disks, err := GetDisksFromDescribeDisksResponse(describeDisksResponse)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		for _, disk := range disks {
+			if ptr.Deref(disk.DiskName, "") != diskName {
+				continue
+			}
+			if ptr.Deref(disk.Status, "") != diskStatusAvailable {
+				leaked.pendingDisks = append(leaked.pendingDisks, *disk.DiskId)
+				continue
+			}
+
+			deleteDiskRequest, err := plugin.SPI.NewDeleteDiskRequest(*disk.DiskId)
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			if _, err := client.DeleteDisk(deleteDiskRequest); err != nil {
+				klog.Errorf("error while deleting data disk %q of machine %q: %v", *disk.DiskId, machineName, err)
+				return maperror.ToMCMError(err, fmt.Sprintf("failed to delete data disk %q", *disk.DiskId))
+			}
+			klog.V(3).Infof("Leaked data disk %q deleted for machine %q", *disk.DiskId, machineName)
+			leaked.disks = append(leaked.disks, *disk.DiskId)
+		}
+	}
+
+	return nil
+}
+
+// findNetworkInterfaces returns the given network interface IDs together with the IDs of the secondary network
+// interfaces of the ProviderSpec which still exist for a machine.
+func (plugin *MachinePlugin) findNetworkInterfaces(client spi.ECSClient, machineName string, providerSpec *api.ProviderSpec, networkInterfaceIDs []string) ([]string, error) {
+	for _, configured := range providerSpec.NetworkInterfaces {
+		if configured.Type == api.NetworkInterfaceTypePrimary {
+			// the primary network interface is always released together with the instance
+			continue
+		}
+
+		networkInterfaceName := spi.NetworkInterfaceName(machineName, configured.Name)
+		describeNetworkInterfacesRequest, err := plugin.SPI.NewDescribeNetworkInterfacesByNameRequest(networkInterfaceName, providerSpec.Region, providerSpec.Tags)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		describeNetworkInterfacesResponse, err := client.DescribeNetworkInterfaces(describeNetworkInterfacesRequest)
+		if err != nil {
+			klog.Errorf("error while fetching network interface %q of machine %q: %v", networkInterfaceName, machineName, err)
+			return nil, maperror.ToMCMError(err, fmt.Sprintf("failed to fetch network interface %q", networkInterfaceName))
+		}
+		networkInterfaces, err := GetNetworkInterfacesFromDescribeNetworkInterfacesResponse(describeNetworkInterfacesResponse)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		for _, networkInterface := range networkInterfaces {
+			networkInterfaceID := ptr.Deref(networkInterface.NetworkInterfaceId, "")
+			if ptr.Deref(networkInterface.NetworkInterfaceName, "") != networkInterfaceName || networkInterfaceID == "" {
+				continue
+			}
+			if !slices.Contains(networkInterfaceIDs, networkInterfaceID) {
+				networkInterfaceIDs = append(networkInterfaceIDs, networkInterfaceID)
+			}
+		}
+	}
+
+	return networkInterfaceIDs, nil
+}
+
+// deleteAvailableNetworkInterfaces deletes those of the given network interfaces which are Available and records the
+// deleted network interfaces and those still in use. Network interfaces which no longer exist have been released by ECS.
+func (plugin *MachinePlugin) deleteAvailableNetworkInterfaces(client spi.ECSClient, networkInterfaceIDs []string, regionID string, leaked *leakedResources) error {
+	if len(networkInterfaceIDs) == 0 {
+		return nil
+	}
+
+	describeNetworkInterfacesRequest, err := plugin.SPI.NewDescribeNetworkInterfacesRequest(networkInterfaceIDs, regionID)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	describeNetworkInterfacesResponse, err := client.DescribeNetworkInterfaces(describeNetworkInterfacesRequest)
+	if err != nil {
+		klog.Errorf("error while fetching network interfaces %v: %v", networkInterfaceIDs, err)
+		return maperror.ToMCMError(err, fmt.Sprintf("failed to fetch network interfaces %v", networkInterfaceIDs))
+	}
+	networkInterfaces, err := GetNetworkInterfacesFromDescribeNetworkInterfacesResponse(describeNetworkInterfacesResponse)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	for _, networkInterface := range networkInterfaces {
+		if ptr.Deref(networkInterface.Status, "") != networkInterfaceStatusAvailable {
+			leaked.pendingNetworkInterfaces = append(leaked.pendingNetworkInterfaces, *networkInterface.NetworkInterfaceId)
+			continue
+		}
+
+		deleteNetworkInterfaceRequest, err := plugin.SPI.NewDeleteNetworkInterfaceRequest(*networkInterface.NetworkInterfaceId, regionID)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if _, err := client.DeleteNetworkInterface(deleteNetworkInterfaceRequest); err != nil {
+			klog.Errorf("error while deleting network interface %q: %v", *networkInterface.NetworkInterfaceId, err)
+			return maperror.ToMCMError(err, fmt.Sprintf("failed to delete network interface %q", *networkInterface.NetworkInterfaceId))
+		}
+		klog.V(3).Infof("Leaked network interface %q deleted", *networkInterface.NetworkInterfaceId)
+		leaked.networkInterfaces = append(leaked.networkInterfaces, *networkInterface.NetworkInterfaceId)
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package alicloud
+
+import (
+	"strings"
+
+	"github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis"
+)
+
+var _ = Describe("ProviderSpec decoding", func() {
+	newMachineClass := func(raw string) *v1alpha1.MachineClass {
+		return &v1alpha1.MachineClass{
+			Provider:     ProviderAlicloud,
+			ProviderSpec: runtime.RawExtension{Raw: []byte(raw)},
+		}
+	}
+
+	It("should decode a ProviderSpec without apiVersion and apply defaults", func() {
+		providerSpec, err := decodeProviderSpec(newMachineClass(`{
+			"imageID": "m-uf6jf6utod2nfs9x21iwse",
+			"instanceType": "ecs.g6.large",
+			"region": "cn-shanghai",
+			"vSwitchID": "vsw-uf6s1fjxxks65rk1tkrpm",
+			"keyPairName": "shoot-ssh-publickey",
+			"systemDisk": {"size": 50},
+			"dataDisks": [
+				{"name": "disk-1", "size": 100},
+				{"name": "disk-2", "size": 100, "deleteWithInstance": false},
+				{"name": "disk-3", "size": 100, "category": "DiskEphemeralSSD"}
+			],
+			"networkInterfaces": [
+				{"name": "storage", "vSwitchID": "vsw-storage", "securityGroupIDs": ["sg-storage"], "queueNumber": 4}
+			]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(providerSpec).To(Equal(&api.ProviderSpec{
+			APIVersion:         api.V1alpha1,
+			ImageID:            "m-uf6jf6utod2nfs9x21iwse",
+			InstanceType:       "ecs.g6.large",
+			Region:             "cn-shanghai",
+			VSwitchID:          "vsw-uf6s1fjxxks65rk1tkrpm",
+			KeyPairName:        "shoot-ssh-publickey",
+			InstanceChargeType: "PostPaid",
+			InternetChargeType: "PayByTraffic",
+			SpotStrategy:       "NoSpot",
+			IoOptimized:        "optimized",
+			SystemDisk:         &api.AlicloudSystemDisk{Category: "cloud_efficiency", Size: 50},
+			DataDisks: []api.AlicloudDataDisk{
+				{Name: "disk-1", Category: "cloud_efficiency", Size: 100, DeleteWithInstance: ptr.To(true)},
+				{Name: "disk-2", Category: "cloud_efficiency", Size: 100, DeleteWithInstance: ptr.To(false)},
+				{Name: "disk-3", Category: "DiskEphemeralSSD", Size: 100},
+			},
+			NetworkInterfaces: []api.AlicloudNetworkInterface{
+				{Name: "storage", Type: api.NetworkInterfaceTypeSecondary, VSwitchID: "vsw-storage", SecurityGroupIDs: []string{"sg-storage"}, QueueNumber: ptr.To(4)},
+			},
+		}))
+	})
+
+	It("should default the instance type to the first of the instance types", func() {
+		providerSpec, err := decodeProviderSpec(newMachineClass(`{"instanceTypes": ["ecs.g6.large", "ecs.g7.large"]}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(providerSpec.InstanceType).To(Equal("ecs.g6.large"))
+		Expect(providerSpec.InstanceTypes).To(Equal([]string{"ecs.g6.large", "ecs.g7.large"}))
+	})
+
+	It("should not override configured values", func() {
+		providerSpec, err := decodeProviderSpec(newMachineClass(`{
+			"apiVersion": "mcm.gardener.cloud/v1alpha1",
+			"instanceChargeType": "PrePaid",
+			"internetChargeType": "PayByBandwidth",
+			"spotStrategy": "SpotAsPriceGo",
+			"IoOptimized": "none",
+			"systemDisk": {"category": "cloud_essd", "size": 50}
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(providerSpec.InstanceChargeType).To(Equal("PrePaid"))
+		Expect(providerSpec.InternetChargeType).To(Equal("PayByBandwidth"))
+		Expect(providerSpec.SpotStrategy).To(Equal("SpotAsPriceGo"))
+		Expect(providerSpec.IoOptimized).To(Equal("none"))
+		Expect(providerSpec.SystemDisk.Category).To(Equal("cloud_essd"))
+	})
+
+	It("should reject unknown fields", func() {
+		_, err := decodeProviderSpec(newMachineClass(`{"apiVersion": "mcm.gardener.cloud/v1alpha1", "ioOptimized": "optimized"}`))
+		expectStatusCode(err, codes.InvalidArgument)
+		Expect(err.Error()).To(ContainSubstring(`unknown field "ioOptimized"`))
+	})
+
+	It("should reject unknown API versions", func() {
+		_, err := decodeProviderSpec(newMachineClass(`{"apiVersion": "mcm.gardener.cloud/v1beta1"}`))
+		expectStatusCode(err, codes.InvalidArgument)
+	})
+
+	It("should reject malformed JSON", func() {
+		_, err := decodeProviderSpec(newMachineClass(`{"imageID": `))
+		expectStatusCode(err, codes.InvalidArgument)
+	})
+})
+
+var _ = Describe("#newClientToken", func() {
+	var (
+		machine      *v1alpha1.Machine
+		machineClass *v1alpha1.MachineClass
+	)
+
+	BeforeEach(func() {
+		machine = &v1alpha1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: "shoot--mcm", UID: "0c2b2d0c-7c1e-4b8e-9d0a-1f2e3d4c5b6a"}}
+		machineClass = &v1alpha1.MachineClass{ProviderSpec: runtime.RawExtension{Raw: []byte(`{"imageID":"m-1"}`)}}
+	})
+
+	It("should be stable for the same machine and class", func() {
+		token := newClientToken(machine, machineClass)
+		Expect(token).To(HaveLen(64))
+		Expect(newClientToken(machine.DeepCopy(), machineClass.DeepCopy())).To(Equal(token))
+	})
+
+	It("should differ for another machine or another class", func() {
+		token := newClientToken(machine, machineClass)
+
+		otherMachine := machine.DeepCopy()
+		otherMachine.UID = "5f0d6c1e-2b3a-4c5d-8e9f-0a1b2c3d4e5f"
+		Expect(newClientToken(otherMachine, machineClass)).NotTo(Equal(token))
+
+		otherMachineClass := machineClass.DeepCopy()
+		otherMachineClass.ProviderSpec.Raw = []byte(`{"imageID":"m-2"}`)
+		Expect(newClientToken(machine, otherMachineClass)).NotTo(Equal(token))
+	})
+})
+
+var _ = Describe("#ownershipTagValue", func() {
+	It("should keep names ECS accepts as tag values", func() {
+		name := strings.Repeat("a", maxTagValueLength)
+		Expect(ownershipTagValue(name)).To(Equal(name))
+	})
+
+	It("should truncate long names and keep them unique", func() {
+		name := strings.Repeat("a", 253)
+		value := ownershipTagValue(name)
+		Expect(value).To(HaveLen(maxTagValueLength))
+		Expect(value).To(HavePrefix(strings.Repeat("a", maxTagValueLength-tagValueHashLength-1) + "-"))
+		Expect(ownershipTagValue(name)).To(Equal(value))
+		Expect(ownershipTagValue(name[:252] + "b")).NotTo(Equal(value))
+	})
+})
+
+// expectStatusCode asserts that err is a machine codes status error with the given code
+func expectStatusCode(err error, code codes.Code) {
+	GinkgoHelper()
+	Expect(err).To(HaveOccurred())
+	statusErr, ok := status.FromError(err)
+	Expect(ok).To(BeTrue())
+	Expect(statusErr.Code()).To(Equal(code))
+}
@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package metrics contains the Prometheus metrics of the ECS and VPC calls and driver methods of the Alicloud provider. They
+// are registered with the default registry, which is served on the metrics endpoint of the machine controller.
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace       = "mcm_alicloud"
+	ecsSubsystem    = "ecs"
+	vpcSubsystem    = "vpc"
+	driverSubsystem = "driver"
+
+	// ResultSuccess is the result label of successful ECS and VPC calls
+	ResultSuccess = "success"
+	// ResultError is the result label of failed ECS and VPC calls
+	ResultError = "error"
+)
+
+var (
+	// ECSRequests counts the ECS calls by action, region, result and Alibaba Cloud error code
+	ECSRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: ecsSubsystem,
+		Name:      "requests_total",
+		Help:      "Number of ECS API requests, partitioned by action, region, result and Alibaba Cloud error code.",
+	}, []string{"action", "region", "result", "error_code"})
+
+	// ECSRequestDuration records the duration of the ECS calls by action, region and result
+	ECSRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: ecsSubsystem,
+		Name:      "request_duration_seconds",
+		Help:      "Time (in seconds) it takes for an ECS API request to complete, partitioned by action, region and result.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"action", "region", "result"})
+
+	// VPCRequests counts the VPC calls by action, region, result and Alibaba Cloud error code
+	VPCRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: vpcSubsystem,
+		Name:      "requests_total",
+		Help:      "Number of VPC API requests, partitioned by action, region, result and Alibaba Cloud error code.",
+	}, []string{"action", "region", "result", "error_code"})
+
+	// VPCRequestDuration records the duration of the VPC calls by action, region and result
+	VPCRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: vpcSubsystem,
+		Name:      "request_duration_seconds",
+		Help:      "Time (in seconds) it takes for a VPC API request to complete, partitioned by action, region and result.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"action", "region", "result"})
+
+	// DriverRequests counts the driver method calls by operation and returned MCM code
+	DriverRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: driverSubsystem,
+		Name:      "requests_total",
+		Help:      "Number of driver requests, partitioned by operation and returned MCM code.",
+	}, []string{"operation", "code"})
+
+	// DriverRequestDuration records the duration of the driver method calls by operation and returned MCM code
+	DriverRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: driverSubsystem,
+		Name:      "request_duration_seconds",
+		Help:      "Time (in seconds) it takes for a driver request to complete, partitioned by operation and returned MCM code.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 14),
+	}, []string{"operation", "code"})
+)
+
+func init() {
+	prometheus.MustRegister(ECSRequests)
+	prometheus.MustRegister(ECSRequestDuration)
+	prometheus.MustRegister(VPCRequests)
+	prometheus.MustRegister(VPCRequestDuration)
+	prometheus.MustRegister(DriverRequests)
+	prometheus.MustRegister(DriverRequestDuration)
+}
+
+// ObserveECSRequest records an ECS call of the given action in the region, which has been started at the given time
+// and returned the given error.
+func ObserveECSRequest(action, region string, start time.Time, err error) {
+	result, errorCode := requestResult(err)
+	ECSRequests.WithLabelValues(action, region, result, errorCode).Inc()
+	ECSRequestDuration.WithLabelValues(action, region, result).Observe(time.Since(start).Seconds())
+}
+
+// ObserveVPCRequest records a VPC call of the given action in the region, which has been started at the given time
+// and returned the given error.
+func ObserveVPCRequest(action, region string, start time.Time, err error) {
+	result, errorCode := requestResult(err)
+	VPCRequests.WithLabelValues(action, region, result, errorCode).Inc()
+	VPCRequestDuration.WithLabelValues(action, region, result).Observe(time.Since(start).Seconds())
+}
+
+// requestResult returns the result and Alibaba Cloud error code labels of a call which returned the given error
+func requestResult(err error) (string, string) {
+	if err == nil {
+		return ResultSuccess, ""
+	}
+	errorCode := "Unknown"
+	var aliErr *tea.SDKError
+	if errors.As(err, &aliErr) && aliErr.Code != nil {
+		errorCode = *aliErr.Code
+	}
+	return ResultError, errorCode
+}
+
+// ObserveDriverRequest records a call of the given driver operation, which has been started at the given time and
+// returned the given error. It is meant to be deferred with a pointer to the named error result of the driver method.
+func ObserveDriverRequest(operation string, start time.Time, err *error) {
+	code := codes.OK
+	if *err != nil {
+		s, _ := status.FromError(*err)
+		code = s.Code()
+	}
+
+	DriverRequests.WithLabelValues(operation, code.String()).Inc()
+	DriverRequestDuration.WithLabelValues(operation, code.String()).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveECSRequest(t *testing.T) {
+	g := NewWithT(t)
+	noStockErr := tea.NewSDKError(map[string]any{
+		"code":    "OperationDenied.NoStock",
+		"message": "The requested resource is sold out in the specified zone.",
+	})
+
+	ObserveECSRequest("RunInstances", "cn-shanghai", time.Now(), nil)
+	ObserveECSRequest("RunInstances", "cn-shanghai", time.Now(), noStockErr)
+	ObserveECSRequest("RunInstances", "cn-shanghai", time.Now(), noStockErr)
+	ObserveECSRequest("RunInstances", "cn-shanghai", time.Now(), errors.New("connection reset by peer"))
+
+	g.Expect(testutil.ToFloat64(ECSRequests.WithLabelValues("RunInstances", "cn-shanghai", ResultSuccess, ""))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(ECSRequests.WithLabelValues("RunInstances", "cn-shanghai", ResultError, "OperationDenied.NoStock"))).To(Equal(2.0))
+	g.Expect(testutil.ToFloat64(ECSRequests.WithLabelValues("RunInstances", "cn-shanghai", ResultError, "Unknown"))).To(Equal(1.0))
+	g.Expect(testutil.CollectAndCount(ECSRequestDuration)).To(Equal(2))
+}
+
+func TestObserveVPCRequest(t *testing.T) {
+	g := NewWithT(t)
+	throttlingErr := tea.NewSDKError(map[string]any{
+		"code":    "Throttling",
+		"message": "Request was denied due to request throttling.",
+	})
+
+	ObserveVPCRequest("DescribeVSwitches", "cn-shanghai", time.Now(), nil)
+	ObserveVPCRequest("DescribeVSwitches", "cn-shanghai", time.Now(), throttlingErr)
+
+	g.Expect(testutil.ToFloat64(VPCRequests.WithLabelValues("DescribeVSwitches", "cn-shanghai", ResultSuccess, ""))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(VPCRequests.WithLabelValues("DescribeVSwitches", "cn-shanghai", ResultError, "Throttling"))).To(Equal(1.0))
+	g.Expect(testutil.CollectAndCount(VPCRequestDuration)).To(Equal(2))
+}
+
+func TestObserveDriverRequest(t *testing.T) {
+	g := NewWithT(t)
+
+	var err error
+	ObserveDriverRequest("DeleteMachine", time.Now(), &err)
+	err = status.Error(codes.Unavailable, "failed to release ECS instance")
+	ObserveDriverRequest("DeleteMachine", time.Now(), &err)
+
+	g.Expect(testutil.ToFloat64(DriverRequests.WithLabelValues("DeleteMachine", codes.OK.String()))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(DriverRequests.WithLabelValues("DeleteMachine", codes.Unavailable.String()))).To(Equal(1.0))
+	g.Expect(testutil.CollectAndCount(DriverRequestDuration)).To(Equal(2))
+}
@@ -6,19 +6,69 @@
 package alicloud
 
 import (
-	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
+	"context"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/driver"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	api "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
 )
 
+// InitializationHook is a post-creation step of InitializeMachine, e.g. attaching an EIP to the running instance or
+// adding it to the backend servers of an SLB. The request carries the secret to create the clients of these services.
+// MCM retries InitializeMachine until all hooks succeed, so that a hook has to be idempotent.
+type InitializationHook func(ctx context.Context, req *driver.InitializeMachineRequest, providerSpec *api.ProviderSpec, instance *ecs.DescribeInstancesResponseBodyInstancesInstance) error
+
 // MachinePlugin implements the driver.Driver
 // It also implements the PluginSPI interface
 type MachinePlugin struct {
 	SPI spi.PluginSPI
+	// RetryBackoff is the backoff of retrying ECS and VPC calls failing with transient errors, zero steps disable
+	// retrying
+	RetryBackoff wait.Backoff
+	// RateLimiters limit the ECS and VPC calls per account and region, calls are not limited if it is nil
+	RateLimiters *spi.RateLimiters
+	// InitializationHooks are run in order by InitializeMachine once the instance is running and tagged
+	InitializationHooks []InitializationHook
 }
 
 // NewAlicloudPlugin returns a new Alicloud machine plugin.
-func NewAlicloudPlugin(pluginSPI spi.PluginSPI) driver.Driver {
+func NewAlicloudPlugin(pluginSPI spi.PluginSPI) *MachinePlugin {
 	return &MachinePlugin{
-		SPI: pluginSPI,
+		SPI:          pluginSPI,
+		RetryBackoff: spi.DefaultRetryBackoff,
+		RateLimiters: spi.NewRateLimiters(spi.DefaultRateLimits),
+	}
+}
+
+// newECSClient returns the ECS client for the given secret and region, which retries transient failures of its calls
+// until the context of the driver method is done. Each attempt is subject to the rate limits of the account and region
+// and traced as child of the span of the driver method.
+func (plugin *MachinePlugin) newECSClient(ctx context.Context, secret *corev1.Secret, region string) (spi.ECSClient, error) {
+	client, err := plugin.SPI.NewECSClient(secret, region)
+	if err != nil {
+		return nil, err
+	}
+	if plugin.RateLimiters != nil {
+		client = spi.NewRateLimitedECSClient(ctx, client, plugin.RateLimiters.ForAccount(secret, region))
+	}
+	client = spi.NewTracingECSClient(ctx, client, region)
+	return spi.NewRetryingECSClient(ctx, client, plugin.RetryBackoff), nil
+}
+
+// newVPCClient returns the VPC client for the given secret and region, which retries, rate limits and traces its calls
+// like the ECS client. The VPC calls are subject to rate limits of their own.
+func (plugin *MachinePlugin) newVPCClient(ctx context.Context, secret *corev1.Secret, region string) (spi.VPCClient, error) {
+	client, err := plugin.SPI.NewVPCClient(secret, region)
+	if err != nil {
+		return nil, err
+	}
+	if plugin.RateLimiters != nil {
+		client = spi.NewRateLimitedVPCClient(ctx, client, plugin.RateLimiters.ForAccount(secret, region))
 	}
+	client = spi.NewTracingVPCClient(ctx, client, region)
+	return spi.NewRetryingVPCClient(ctx, client, plugin.RetryBackoff), nil
 }
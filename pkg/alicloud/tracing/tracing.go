@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tracing contains the optional OpenTelemetry tracing of the driver methods and ECS calls of the Alicloud
+// provider. Spans are only exported if an OTLP endpoint is configured, otherwise the no-op tracer provider is used.
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ServiceName is the name of the service the spans are reported for
+	ServiceName = "machine-controller-manager-provider-alicloud"
+	// tracerName is the name of the tracer creating all spans of the provider
+	tracerName = "github.com/gardener/machine-controller-manager-provider-alicloud"
+
+	// MachineNameKey is the span attribute holding the name of the machine
+	MachineNameKey = attribute.Key("mcm.machine.name")
+	// MCMCodeKey is the span attribute holding the MCM code returned by a driver method
+	MCMCodeKey = attribute.Key("mcm.code")
+	// ECSActionKey is the span attribute holding the ECS API action
+	ECSActionKey = attribute.Key("alicloud.ecs.action")
+	// VPCActionKey is the span attribute holding the VPC API action
+	VPCActionKey = attribute.Key("alicloud.vpc.action")
+	// RegionKey is the span attribute holding the Alibaba Cloud region
+	RegionKey = attribute.Key("alicloud.region")
+	// InstanceIDKey is the span attribute holding the ID of the ECS instance
+	InstanceIDKey = attribute.Key("alicloud.ecs.instance_id")
+	// InstanceIDsKey is the span attribute holding the IDs of the ECS instances if a call concerns several instances
+	InstanceIDsKey = attribute.Key("alicloud.ecs.instance_ids")
+	// InstanceNameKey is the span attribute holding the name of the ECS instance
+	InstanceNameKey = attribute.Key("alicloud.ecs.instance_name")
+	// DiskIDKey is the span attribute holding the ID of the ECS disk
+	DiskIDKey = attribute.Key("alicloud.ecs.disk_id")
+	// NetworkInterfaceIDKey is the span attribute holding the ID of the ECS network interface
+	NetworkInterfaceIDKey = attribute.Key("alicloud.ecs.network_interface_id")
+	// NetworkInterfaceNameKey is the span attribute holding the name of the ECS network interface
+	NetworkInterfaceNameKey = attribute.Key("alicloud.ecs.network_interface_name")
+	// VSwitchIDKey is the span attribute holding the ID of the vSwitch
+	VSwitchIDKey = attribute.Key("alicloud.vpc.vswitch_id")
+	// RequestIDKey is the span attribute holding the ID of the Alibaba Cloud API request
+	RequestIDKey = attribute.Key("alicloud.request_id")
+)
+
+// DefaultOptions are the tracing options, which sample all traces once an endpoint is configured
+var DefaultOptions = Options{
+	SamplingRatio: 1,
+}
+
+// Options configure the export of spans
+type Options struct {
+	// Endpoint is the OTLP gRPC endpoint spans are exported to, tracing is disabled if it is empty
+	Endpoint string
+	// Insecure disables TLS for the connection to the endpoint
+	Insecure bool
+	// SamplingRatio is the ratio of traces which are sampled
+	SamplingRatio float64
+}
+
+// AddFlags adds the flags of the tracing options to the given flag set
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Endpoint, "tracing-otlp-endpoint", o.Endpoint, "OTLP gRPC endpoint (host:port) traces of driver methods and ECS calls are exported to, tracing is disabled if it is empty")
+	fs.BoolVar(&o.Insecure, "tracing-otlp-insecure", o.Insecure, "Disable TLS for the connection to the OTLP endpoint")
+	fs.Float64Var(&o.SamplingRatio, "tracing-sampling-ratio", o.SamplingRatio, "Ratio of traces which are sampled, between 0 and 1")
+}
+
+// Setup installs the global tracer provider exporting spans to the configured OTLP endpoint. The returned function
+// flushes and stops the export, it has to be called before the process exits. Nothing is set up if no endpoint is
+// configured.
+func Setup(ctx context.Context, options Options) (func(context.Context) error, error) {
+	if options.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	if options.SamplingRatio < 0 || options.SamplingRatio > 1 {
+		return nil, errors.New("tracing sampling ratio must be between 0 and 1")
+	}
+
+	exporterOptions := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(options.Endpoint)}
+	if options.Insecure {
+		exporterOptions = append(exporterOptions, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, exporterOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SamplingRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span with the given name and attributes as child of the span in the given context
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// EndDriverSpan ends the span of a driver method, which returned the given error. It is meant to be deferred with a
+// pointer to the named error result of the driver method.
+func EndDriverSpan(span trace.Span, err *error) {
+	code := codes.OK
+	if *err != nil {
+		s, _ := status.FromError(*err)
+		code = s.Code()
+		span.SetStatus(otelcodes.Error, s.Message())
+	}
+	span.SetAttributes(MCMCodeKey.String(code.String()))
+	span.End()
+}
+
+// EndSpan ends the given span, which failed if the given error is not nil
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
@@ -64,3 +64,128 @@ func (plugin *MachinePlugin) GetAllInstances(client spi.ECSClient, request *ecs.
 	}
 	return instances, nil
 }
+
+// GetInstancePrivateIP is a utility function to extract the private IP address of the primary network interface of an instance
+func GetInstancePrivateIP(instance *ecs.DescribeInstancesResponseBodyInstancesInstance) string {
+	if instance.NetworkInterfaces != nil {
+		for _, networkInterface := range instance.NetworkInterfaces.NetworkInterface {
+			if ptr.Deref(networkInterface.Type, "") == networkInterfaceTypePrimary && ptr.Deref(networkInterface.PrimaryIpAddress, "") != "" {
+				return *networkInterface.PrimaryIpAddress
+			}
+		}
+	}
+
+	if instance.VpcAttributes != nil && instance.VpcAttributes.PrivateIpAddress != nil {
+		for _, ipAddress := range instance.VpcAttributes.PrivateIpAddress.IpAddress {
+			if ptr.Deref(ipAddress, "") != "" {
+				return *ipAddress
+			}
+		}
+	}
+
+	return ""
+}
+
+// GetInstanceIPv6Addresses is a utility function to extract the IPv6 addresses of the network interfaces of the given
+// type of an instance, or of all its network interfaces if no type is given
+func GetInstanceIPv6Addresses(instance *ecs.DescribeInstancesResponseBodyInstancesInstance, networkInterfaceType string) []string {
+	var ipv6Addresses []string
+	if instance.NetworkInterfaces == nil {
+		return ipv6Addresses
+	}
+
+	for _, networkInterface := range instance.NetworkInterfaces.NetworkInterface {
+		if (networkInterfaceType != "" && ptr.Deref(networkInterface.Type, "") != networkInterfaceType) || networkInterface.Ipv6Sets == nil {
+			continue
+		}
+		for _, ipv6Set := range networkInterface.Ipv6Sets.Ipv6Set {
+			if ptr.Deref(ipv6Set.Ipv6Address, "") != "" {
+				ipv6Addresses = append(ipv6Addresses, *ipv6Set.Ipv6Address)
+			}
+		}
+	}
+
+	return ipv6Addresses
+}
+
+// GetInstanceTags is a utility function to extract the tags of an instance
+func GetInstanceTags(instance *ecs.DescribeInstancesResponseBodyInstancesInstance) map[string]string {
+	instanceTags := make(map[string]string)
+	if instance.Tags != nil {
+		for _, tag := range instance.Tags.Tag {
+			instanceTags[ptr.Deref(tag.TagKey, "")] = ptr.Deref(tag.TagValue, "")
+		}
+	}
+
+	return instanceTags
+}
+
+// GetMissingInstanceTags is a utility function to determine which of the given tags are not (yet) set on an instance
+func GetMissingInstanceTags(instance *ecs.DescribeInstancesResponseBodyInstancesInstance, tags map[string]string) map[string]string {
+	instanceTags := GetInstanceTags(instance)
+
+	missingTags := make(map[string]string)
+	for k, v := range tags {
+		if value, ok := instanceTags[k]; !ok || value != v {
+			missingTags[k] = v
+		}
+	}
+
+	return missingTags
+}
+
+// GetSecondaryNetworkInterfaceIDs is a utility function to extract the IDs of the secondary network interfaces attached to an instance
+func GetSecondaryNetworkInterfaceIDs(instance *ecs.DescribeInstancesResponseBodyInstancesInstance) []string {
+	var networkInterfaceIDs []string
+	if instance.NetworkInterfaces == nil {
+		return networkInterfaceIDs
+	}
+
+	for _, networkInterface := range instance.NetworkInterfaces.NetworkInterface {
+		if ptr.Deref(networkInterface.Type, "") == networkInterfaceTypeSecondary && ptr.Deref(networkInterface.NetworkInterfaceId, "") != "" {
+			networkInterfaceIDs = append(networkInterfaceIDs, *networkInterface.NetworkInterfaceId)
+		}
+	}
+
+	return networkInterfaceIDs
+}
+
+// GetDisksFromDescribeDisksResponse is a utility function to extract disks from DescribeDisksResponse
+func GetDisksFromDescribeDisksResponse(resp *ecs.DescribeDisksResponse) ([]*ecs.DescribeDisksResponseBodyDisksDisk, error) {
+	if resp == nil ||
+		resp.Body == nil ||
+		resp.Body.Disks == nil {
+
+		return nil, fmt.Errorf("invalid response")
+	}
+
+	return resp.Body.Disks.Disk, nil
+}
+
+// GetNetworkInterfacesFromDescribeNetworkInterfacesResponse is a utility function to extract network interfaces from DescribeNetworkInterfacesResponse
+func GetNetworkInterfacesFromDescribeNetworkInterfacesResponse(resp *ecs.DescribeNetworkInterfacesResponse) ([]*ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSet, error) {
+	if resp == nil ||
+		resp.Body == nil ||
+		resp.Body.NetworkInterfaceSets == nil {
+
+		return nil, fmt.Errorf("invalid response")
+	}
+
+	return resp.Body.NetworkInterfaceSets.NetworkInterfaceSet, nil
+}
+
+// GetInstanceLockReasons is a utility function to extract the reasons of the operation locks of an instance
+func GetInstanceLockReasons(instance *ecs.DescribeInstancesResponseBodyInstancesInstance) []string {
+	var lockReasons []string
+	if instance.OperationLocks == nil {
+		return lockReasons
+	}
+
+	for _, lockReason := range instance.OperationLocks.LockReason {
+		if ptr.Deref(lockReason.LockReason, "") != "" {
+			lockReasons = append(lockReasons, *lockReason.LockReason)
+		}
+	}
+
+	return lockReasons
+}
@@ -0,0 +1,1065 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fake contains a stateful in-memory fake of the ECS API, which allows to test the driver end-to-end and to
+// develop locally without an Alibaba Cloud account. The fake can be used as ECS client directly or be served over HTTP
+// to the real ECS SDK client.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
+	"github.com/alibabacloud-go/tea/tea"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	maperror "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/errors"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/vpc"
+)
+
+const (
+	// InstanceStatusPending is the status of instances which have just been launched
+	InstanceStatusPending = "Pending"
+	// InstanceStatusRunning is the status of launched instances
+	InstanceStatusRunning = "Running"
+	// InstanceStatusStopping is the status of instances which are being released
+	InstanceStatusStopping = "Stopping"
+	// InstanceStatusStopped is the status of stopped instances
+	InstanceStatusStopped = "Stopped"
+
+	// StatusAvailable is the status of disks and network interfaces which are not attached to an instance
+	StatusAvailable = "Available"
+	// DiskStatusInUse is the status of disks attached to an instance
+	DiskStatusInUse = "In_use"
+	// NetworkInterfaceStatusInUse is the status of network interfaces attached to an instance
+	NetworkInterfaceStatusInUse = "InUse"
+
+	networkInterfaceTypePrimary   = "Primary"
+	networkInterfaceTypeSecondary = "Secondary"
+
+	// defaultMaxResults is the page size of the Describe* calls if the request does not specify one
+	defaultMaxResults = 10
+	// maxMaxResults is the largest page size of the Describe* calls
+	maxMaxResults = 100
+
+	creationTimeLayout = "2006-01-02T15:04Z"
+
+	// maxTagValueLength is the maximum length of tag values
+	maxTagValueLength = 128
+
+	// errorRequestID is the request ID of all error responses
+	errorRequestID = "fake-error-request-id"
+
+	incorrectDiskStatus             = "IncorrectDiskStatus"
+	invalidEniIDNotFound            = "InvalidEniId.NotFound"
+	invalidEniState                 = "InvalidOperation.InvalidEniState"
+	invalidResourceIDNotFound       = "InvalidResourceId.NotFound"
+	invalidResourceTypeNotSupported = "InvalidResourceType.NotSupported"
+	invalidTagValueMalformed        = "InvalidTagValue.Malformed"
+)
+
+// instance is an ECS instance together with the state the fake needs to emulate its lifecycle
+type instance struct {
+	*ecs.DescribeInstancesResponseBodyInstancesInstance
+	// transitions is the number of DescribeInstances responses which still report the instance Pending or Stopping
+	transitions int
+}
+
+// ECS is a stateful in-memory fake of the ECS API. It keeps instances, disks, network interfaces and their tags, pages
+// Describe* responses with NextToken and emulates the state transitions of instances as well as the release of their
+// disks and network interfaces. Errors can be injected per action with FailNext. The vSwitches described by the
+// DescribeVSwitches action of the VPC API are added with AddVSwitch.
+//
+// ECS implements spi.ECSClient, spi.VPCClient and, as it returns itself as client for every secret and region,
+// spi.PluginSPI. The requests are built by the embedded spi.PluginSPIImpl, so that the driver is tested with the real requests.
+type ECS struct {
+	spi.PluginSPIImpl
+
+	// Transitions is the number of DescribeInstances responses an instance is reported Pending after it has been
+	// launched, and Stopping after it has been deleted, before it is Running respectively released. Zero makes both
+	// transitions immediate.
+	Transitions int
+
+	mu                sync.Mutex
+	instances         map[string]*instance
+	disks             map[string]*ecs.DescribeDisksResponseBodyDisksDisk
+	networkInterfaces map[string]*ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSet
+	vSwitches         map[string]*vpc.DescribeVSwitchesResponseBodyVSwitchesVSwitch
+	clientTokens      map[string]string
+	failures          map[string][]error
+	calls             map[string]int
+	lastID            int
+}
+
+var _ spi.ECSClient = &ECS{}
+var _ spi.VPCClient = &ECS{}
+var _ spi.PluginSPI = &ECS{}
+
+// NewECS returns an empty fake ECS
+func NewECS() *ECS {
+	return &ECS{
+		instances:         map[string]*instance{},
+		disks:             map[string]*ecs.DescribeDisksResponseBodyDisksDisk{},
+		networkInterfaces: map[string]*ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSet{},
+		vSwitches:         map[string]*vpc.DescribeVSwitchesResponseBodyVSwitchesVSwitch{},
+		clientTokens:      map[string]string{},
+		failures:          map[string][]error{},
+		calls:             map[string]int{},
+	}
+}
+
+// NewError returns the error the ECS SDK returns for an error response of the ECS API with the given HTTP status code,
+// error code and message.
+func NewError(statusCode int, code, message string) error {
+	return tea.NewSDKError(map[string]any{
+		"code":       code,
+		"message":    message,
+		"statusCode": statusCode,
+		"data": map[string]any{
+			"statusCode": statusCode,
+			"Code":       code,
+			"Message":    message,
+			"RequestId":  errorRequestID,
+		},
+	})
+}
+
+// NewECSClient returns the fake itself for every secret and region
+func (f *ECS) NewECSClient(_ *corev1.Secret, _ string) (spi.ECSClient, error) {
+	return f, nil
+}
+
+// NewVPCClient returns the fake itself for every secret and region
+func (f *ECS) NewVPCClient(_ *corev1.Secret, _ string) (spi.VPCClient, error) {
+	return f, nil
+}
+
+// FailNext makes the next calls of the given ECS action, e.g. RunInstances, fail with the given errors, one error per
+// call. The state of the fake is not changed by failed calls.
+func (f *ECS) FailNext(action string, errs ...error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures[action] = append(f.failures[action], errs...)
+}
+
+// Calls returns the number of calls of the given ECS action, including failed ones
+func (f *ECS) Calls(action string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[action]
+}
+
+// Instances returns copies of all instances which have not been released yet
+func (f *ECS) Instances() []*ecs.DescribeInstancesResponseBodyInstancesInstance {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var instances []*ecs.DescribeInstancesResponseBodyInstancesInstance
+	for _, id := range slices.Sorted(maps.Keys(f.instances)) {
+		instances = append(instances, deepCopy(f.instances[id].DescribeInstancesResponseBodyInstancesInstance))
+	}
+	return instances
+}
+
+// Disks returns copies of all disks which have not been deleted yet
+func (f *ECS) Disks() []*ecs.DescribeDisksResponseBodyDisksDisk {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var disks []*ecs.DescribeDisksResponseBodyDisksDisk
+	for _, id := range slices.Sorted(maps.Keys(f.disks)) {
+		disks = append(disks, deepCopy(f.disks[id]))
+	}
+	return disks
+}
+
+// NetworkInterfaces returns copies of all network interfaces which have not been deleted yet
+func (f *ECS) NetworkInterfaces() []*ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSet {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var networkInterfaces []*ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSet
+	for _, id := range slices.Sorted(maps.Keys(f.networkInterfaces)) {
+		networkInterfaces = append(networkInterfaces, deepCopy(f.networkInterfaces[id]))
+	}
+	return networkInterfaces
+}
+
+// AddVSwitch adds an available vSwitch of the given zone with the given IPv4 and IPv6 CIDR blocks, the IPv6 CIDR block
+// is empty if IPv6 is not enabled for the vSwitch. vSwitches are described in every region.
+func (f *ECS) AddVSwitch(vSwitchID, zoneID, cidrBlock, ipv6CidrBlock string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.vSwitches[vSwitchID] = &vpc.DescribeVSwitchesResponseBodyVSwitchesVSwitch{
+		VSwitchId:     tea.String(vSwitchID),
+		VpcId:         tea.String("vpc-fake"),
+		ZoneId:        tea.String(zoneID),
+		Status:        tea.String(StatusAvailable),
+		CidrBlock:     tea.String(cidrBlock),
+		Ipv6CidrBlock: tea.String(ipv6CidrBlock),
+	}
+}
+
+// UpdateInstance applies the given update to the instance with the given ID, e.g. to stop it or to add an operation
+// lock. An error is returned if there is no such instance.
+func (f *ECS) UpdateInstance(instanceID string, update func(instance *ecs.DescribeInstancesResponseBodyInstancesInstance)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	inst, ok := f.instances[instanceID]
+	if !ok {
+		return fmt.Errorf("instance %q not found", instanceID)
+	}
+	update(inst.DescribeInstancesResponseBodyInstancesInstance)
+	return nil
+}
+
+// UpdateNetworkInterface applies the given update to the network interface with the given ID, e.g. to keep it when its
+// instance is released. An error is returned if there is no such network interface.
+func (f *ECS) UpdateNetworkInterface(networkInterfaceID string, update func(networkInterface *ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSet)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	networkInterface, ok := f.networkInterfaces[networkInterfaceID]
+	if !ok {
+		return fmt.Errorf("network interface %q not found", networkInterfaceID)
+	}
+	update(networkInterface)
+	return nil
+}
+
+// RunInstances launches an instance together with its disks and network interfaces. Requests with a ClientToken which
+// has been sent before return the instance launched by the first request.
+func (f *ECS) RunInstances(request *ecs.RunInstancesRequest) (*ecs.RunInstancesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("RunInstances"); err != nil {
+		return nil, err
+	}
+
+	// the primary network interface can be configured by the first entry instead of the request parameters
+	primary := &ecs.RunInstancesRequestNetworkInterface{
+		VSwitchId:        request.VSwitchId,
+		SecurityGroupId:  request.SecurityGroupId,
+		SecurityGroupIds: request.SecurityGroupIds,
+		PrimaryIpAddress: request.PrivateIpAddress,
+		DeleteOnRelease:  tea.Bool(true),
+	}
+	secondaries := request.NetworkInterface
+	if len(secondaries) > 0 && ptr.Deref(secondaries[0].InstanceType, "") == networkInterfaceTypePrimary {
+		primary = &ecs.RunInstancesRequestNetworkInterface{
+			VSwitchId:            secondaries[0].VSwitchId,
+			SecurityGroupId:      secondaries[0].SecurityGroupId,
+			SecurityGroupIds:     secondaries[0].SecurityGroupIds,
+			PrimaryIpAddress:     secondaries[0].PrimaryIpAddress,
+			NetworkInterfaceName: secondaries[0].NetworkInterfaceName,
+			Description:          secondaries[0].Description,
+			QueueNumber:          secondaries[0].QueueNumber,
+			Ipv6AddressCount:     secondaries[0].Ipv6AddressCount,
+			Ipv6Address:          secondaries[0].Ipv6Address,
+			DeleteOnRelease:      tea.Bool(true),
+		}
+		secondaries = secondaries[1:]
+	}
+	// IPv6 addresses are assigned at launch to the primary network interface only, secondary network interfaces get
+	// theirs with AssignIpv6Addresses
+	for _, networkInterface := range secondaries {
+		if networkInterface.Ipv6AddressCount != nil || len(networkInterface.Ipv6Address) > 0 {
+			return nil, NewError(http.StatusBadRequest, maperror.InvalidParameter, "The specified parameter NetworkInterface.n.Ipv6Address or NetworkInterface.n.Ipv6AddressCount is only valid for the primary network interface.")
+		}
+	}
+	for name, value := range map[string]*string{
+		"RegionId":     request.RegionId,
+		"ImageId":      request.ImageId,
+		"InstanceType": request.InstanceType,
+		"VSwitchId":    primary.VSwitchId,
+	} {
+		if ptr.Deref(value, "") == "" {
+			return nil, missingParameter(name)
+		}
+	}
+	for _, tag := range request.Tag {
+		if err := validateTagValue(tag.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	if token := ptr.Deref(request.ClientToken, ""); token != "" {
+		if instanceID, ok := f.clientTokens[token]; ok {
+			return f.runInstancesResponse(instanceID), nil
+		}
+	}
+
+	instanceID := f.newID("i-")
+	inst := &instance{
+		DescribeInstancesResponseBodyInstancesInstance: &ecs.DescribeInstancesResponseBodyInstancesInstance{
+			InstanceId:               tea.String(instanceID),
+			InstanceName:             request.InstanceName,
+			HostName:                 request.HostName,
+			RegionId:                 request.RegionId,
+			ZoneId:                   request.ZoneId,
+			InstanceType:             request.InstanceType,
+			ImageId:                  request.ImageId,
+			InstanceChargeType:       tea.String(ptr.Deref(request.InstanceChargeType, "PostPaid")),
+			InternetChargeType:       request.InternetChargeType,
+			InternetMaxBandwidthIn:   request.InternetMaxBandwidthIn,
+			InternetMaxBandwidthOut:  request.InternetMaxBandwidthOut,
+			IoOptimized:              tea.Bool(ptr.Deref(request.IoOptimized, "") == "optimized"),
+			KeyPairName:              request.KeyPairName,
+			SpotStrategy:             tea.String(ptr.Deref(request.SpotStrategy, "NoSpot")),
+			SpotPriceLimit:           request.SpotPriceLimit,
+			SpotDuration:             request.SpotDuration,
+			SpotInterruptionBehavior: request.SpotInterruptionBehavior,
+			InstanceNetworkType:      tea.String("vpc"),
+			Status:                   tea.String(InstanceStatusRunning),
+			CreationTime:             tea.String(time.Now().UTC().Format(creationTimeLayout)),
+			SecurityGroupIds:         &ecs.DescribeInstancesResponseBodyInstancesInstanceSecurityGroupIds{},
+			NetworkInterfaces:        &ecs.DescribeInstancesResponseBodyInstancesInstanceNetworkInterfaces{},
+			Tags:                     &ecs.DescribeInstancesResponseBodyInstancesInstanceTags{},
+		},
+	}
+	if f.Transitions > 0 {
+		inst.Status = tea.String(InstanceStatusPending)
+		inst.transitions = f.Transitions
+	}
+	inst.SecurityGroupIds.SecurityGroupId = securityGroupIDs(primary)
+
+	// tags are added to the instance, its disks and its primary network interface, like ECS does
+	tags := map[string]string{}
+	for _, tag := range request.Tag {
+		tags[ptr.Deref(tag.Key, "")] = ptr.Deref(tag.Value, "")
+	}
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		inst.Tags.Tag = append(inst.Tags.Tag, &ecs.DescribeInstancesResponseBodyInstancesInstanceTagsTag{TagKey: tea.String(key), TagValue: tea.String(tags[key])})
+	}
+
+	primaryIP := ptr.Deref(primary.PrimaryIpAddress, "")
+	if primaryIP == "" {
+		primaryIP = f.newIP()
+	}
+	var ipv6Addresses []string
+	for _, address := range append(request.Ipv6Address, primary.Ipv6Address...) {
+		ipv6Addresses = append(ipv6Addresses, ptr.Deref(address, ""))
+	}
+	for range ptr.Deref(request.Ipv6AddressCount, 0) {
+		ipv6Addresses = append(ipv6Addresses, f.newIPv6())
+	}
+	for range ptr.Deref(primary.Ipv6AddressCount, 0) {
+		ipv6Addresses = append(ipv6Addresses, f.newIPv6())
+	}
+	inst.VpcAttributes = &ecs.DescribeInstancesResponseBodyInstancesInstanceVpcAttributes{
+		VSwitchId:        primary.VSwitchId,
+		PrivateIpAddress: &ecs.DescribeInstancesResponseBodyInstancesInstanceVpcAttributesPrivateIpAddress{IpAddress: []*string{tea.String(primaryIP)}},
+	}
+	f.attachNetworkInterface(inst, request, primary, networkInterfaceTypePrimary, primaryIP, ipv6Addresses, tags)
+
+	for _, networkInterface := range secondaries {
+		ip := ptr.Deref(networkInterface.PrimaryIpAddress, "")
+		if ip == "" {
+			ip = f.newIP()
+		}
+		f.attachNetworkInterface(inst, request, networkInterface, networkInterfaceTypeSecondary, ip, nil, nil)
+	}
+
+	systemDisk := &ecs.RunInstancesRequestDataDisk{DeleteWithInstance: tea.Bool(true), Size: tea.Int32(40)}
+	if request.SystemDisk != nil {
+		systemDisk.Category = request.SystemDisk.Category
+		systemDisk.DiskName = request.SystemDisk.DiskName
+		if size, err := strconv.Atoi(ptr.Deref(request.SystemDisk.Size, "")); err == nil {
+			systemDisk.Size = tea.Int32(int32(size)) // #nosec G115 -- disk sizes are small
+		}
+	}
+	f.attachDisk(inst, systemDisk, "system", tags)
+	for _, dataDisk := range request.DataDisk {
+		f.attachDisk(inst, dataDisk, "data", tags)
+	}
+
+	f.instances[instanceID] = inst
+	if token := ptr.Deref(request.ClientToken, ""); token != "" {
+		f.clientTokens[token] = instanceID
+	}
+	return f.runInstancesResponse(instanceID), nil
+}
+
+// DescribeInstances returns a page of the instances matching the filters of the request. Instances which are Pending
+// or Stopping advance their state with every response they are part of.
+func (f *ECS) DescribeInstances(request *ecs.DescribeInstancesRequest) (*ecs.DescribeInstancesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("DescribeInstances"); err != nil {
+		return nil, err
+	}
+	if ptr.Deref(request.RegionId, "") == "" {
+		return nil, missingParameter("RegionId")
+	}
+	instanceIDs, err := parseIDs("InstanceIds", request.InstanceIds)
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []*instance
+	for _, id := range slices.Sorted(maps.Keys(f.instances)) {
+		inst := f.instances[id]
+		if !matches(request.RegionId, inst.RegionId) ||
+			!matches(request.InstanceName, inst.InstanceName) ||
+			!matches(request.ZoneId, inst.ZoneId) ||
+			!matchesFold(request.Status, inst.Status) ||
+			(instanceIDs != nil && !slices.Contains(instanceIDs, id)) {
+			continue
+		}
+		tags := map[string]string{}
+		for _, tag := range inst.Tags.Tag {
+			tags[ptr.Deref(tag.TagKey, "")] = ptr.Deref(tag.TagValue, "")
+		}
+		if !matchesTags(tags, request.Tag, func(tag *ecs.DescribeInstancesRequestTag) (*string, *string) { return tag.Key, tag.Value }) {
+			continue
+		}
+		matching = append(matching, inst)
+	}
+
+	page, nextToken, err := paginate(matching, request.MaxResults, request.NextToken)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]*ecs.DescribeInstancesResponseBodyInstancesInstance, 0, len(page))
+	for _, inst := range page {
+		if !f.advance(inst) {
+			// the instance has been released in the meantime, ECS does not fill up the page
+			continue
+		}
+		instances = append(instances, deepCopy(inst.DescribeInstancesResponseBodyInstancesInstance))
+	}
+
+	return &ecs.DescribeInstancesResponse{
+		StatusCode: tea.Int32(http.StatusOK),
+		Body: &ecs.DescribeInstancesResponseBody{
+			RequestId:  f.newRequestID(),
+			TotalCount: tea.Int32(int32(len(matching))), // #nosec G115 -- the fake keeps only few instances
+			NextToken:  nextToken,
+			Instances:  &ecs.DescribeInstancesResponseBodyInstances{Instance: instances},
+		},
+	}, nil
+}
+
+// DeleteInstance deletes the instance. Running instances can only be deleted with Force, Pending instances and
+// instances which are already Stopping cannot be deleted at all.
+func (f *ECS) DeleteInstance(request *ecs.DeleteInstanceRequest) (*ecs.DeleteInstanceResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("DeleteInstance"); err != nil {
+		return nil, err
+	}
+	instanceID := ptr.Deref(request.InstanceId, "")
+	if instanceID == "" {
+		return nil, missingParameter("InstanceId")
+	}
+	inst, ok := f.instances[instanceID]
+	if !ok {
+		return nil, NewError(http.StatusNotFound, maperror.InvalidInstanceIDNotFound, "The specified InstanceId does not exist.")
+	}
+
+	switch ptr.Deref(inst.Status, "") {
+	case InstanceStatusStopped:
+	case InstanceStatusRunning:
+		if !ptr.Deref(request.Force, false) {
+			return nil, NewError(http.StatusForbidden, maperror.IncorrectInstanceStatus, "The current status of the resource does not support this operation.")
+		}
+	default:
+		return nil, NewError(http.StatusForbidden, maperror.IncorrectInstanceStatus, "The current status of the resource does not support this operation.")
+	}
+
+	if f.Transitions > 0 {
+		inst.Status = tea.String(InstanceStatusStopping)
+		inst.transitions = f.Transitions
+	} else {
+		f.release(inst)
+	}
+
+	return &ecs.DeleteInstanceResponse{
+		StatusCode: tea.Int32(http.StatusOK),
+		Body:       &ecs.DeleteInstanceResponseBody{RequestId: f.newRequestID()},
+	}, nil
+}
+
+// DescribeDisks returns a page of the disks matching the filters of the request
+func (f *ECS) DescribeDisks(request *ecs.DescribeDisksRequest) (*ecs.DescribeDisksResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("DescribeDisks"); err != nil {
+		return nil, err
+	}
+	if ptr.Deref(request.RegionId, "") == "" {
+		return nil, missingParameter("RegionId")
+	}
+	diskIDs, err := parseIDs("DiskIds", request.DiskIds)
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []*ecs.DescribeDisksResponseBodyDisksDisk
+	for _, id := range slices.Sorted(maps.Keys(f.disks)) {
+		disk := f.disks[id]
+		if !matches(request.RegionId, disk.RegionId) ||
+			!matches(request.DiskName, disk.DiskName) ||
+			!matches(request.InstanceId, disk.InstanceId) ||
+			!matchesFold(request.Status, disk.Status) ||
+			(diskIDs != nil && !slices.Contains(diskIDs, id)) {
+			continue
+		}
+		tags := map[string]string{}
+		for _, tag := range disk.Tags.Tag {
+			tags[ptr.Deref(tag.TagKey, "")] = ptr.Deref(tag.TagValue, "")
+		}
+		if !matchesTags(tags, request.Tag, func(tag *ecs.DescribeDisksRequestTag) (*string, *string) { return tag.Key, tag.Value }) {
+			continue
+		}
+		matching = append(matching, disk)
+	}
+
+	page, nextToken, err := paginate(matching, request.MaxResults, request.NextToken)
+	if err != nil {
+		return nil, err
+	}
+
+	disks := make([]*ecs.DescribeDisksResponseBodyDisksDisk, 0, len(page))
+	for _, disk := range page {
+		disks = append(disks, deepCopy(disk))
+	}
+
+	return &ecs.DescribeDisksResponse{
+		StatusCode: tea.Int32(http.StatusOK),
+		Body: &ecs.DescribeDisksResponseBody{
+			RequestId:  f.newRequestID(),
+			TotalCount: tea.Int32(int32(len(matching))), // #nosec G115 -- the fake keeps only few disks
+			NextToken:  nextToken,
+			Disks:      &ecs.DescribeDisksResponseBodyDisks{Disk: disks},
+		},
+	}, nil
+}
+
+// DeleteDisk deletes the disk, which must not be attached to an instance
+func (f *ECS) DeleteDisk(request *ecs.DeleteDiskRequest) (*ecs.DeleteDiskResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("DeleteDisk"); err != nil {
+		return nil, err
+	}
+	diskID := ptr.Deref(request.DiskId, "")
+	if diskID == "" {
+		return nil, missingParameter("DiskId")
+	}
+	disk, ok := f.disks[diskID]
+	if !ok {
+		return nil, NewError(http.StatusNotFound, maperror.InvalidDiskIDNotFound, "The specified disk does not exist.")
+	}
+	if ptr.Deref(disk.Status, "") != StatusAvailable {
+		return nil, NewError(http.StatusForbidden, incorrectDiskStatus, "The current disk status does not support this operation.")
+	}
+	delete(f.disks, diskID)
+
+	return &ecs.DeleteDiskResponse{
+		StatusCode: tea.Int32(http.StatusOK),
+		Body:       &ecs.DeleteDiskResponseBody{RequestId: f.newRequestID()},
+	}, nil
+}
+
+// DescribeNetworkInterfaces returns a page of the network interfaces matching the filters of the request
+func (f *ECS) DescribeNetworkInterfaces(request *ecs.DescribeNetworkInterfacesRequest) (*ecs.DescribeNetworkInterfacesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("DescribeNetworkInterfaces"); err != nil {
+		return nil, err
+	}
+	if ptr.Deref(request.RegionId, "") == "" {
+		return nil, missingParameter("RegionId")
+	}
+
+	var matching []*ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSet
+	for _, id := range slices.Sorted(maps.Keys(f.networkInterfaces)) {
+		networkInterface := f.networkInterfaces[id]
+		if !matches(request.InstanceId, networkInterface.InstanceId) ||
+			!matches(request.NetworkInterfaceName, networkInterface.NetworkInterfaceName) ||
+			!matches(request.Type, networkInterface.Type) ||
+			!matches(request.VSwitchId, networkInterface.VSwitchId) ||
+			!matchesFold(request.Status, networkInterface.Status) ||
+			(len(request.NetworkInterfaceId) > 0 && !slices.Contains(tea.StringSliceValue(request.NetworkInterfaceId), id)) {
+			continue
+		}
+		tags := map[string]string{}
+		for _, tag := range networkInterface.Tags.Tag {
+			tags[ptr.Deref(tag.TagKey, "")] = ptr.Deref(tag.TagValue, "")
+		}
+		if !matchesTags(tags, request.Tag, func(tag *ecs.DescribeNetworkInterfacesRequestTag) (*string, *string) { return tag.Key, tag.Value }) {
+			continue
+		}
+		matching = append(matching, networkInterface)
+	}
+
+	page, nextToken, err := paginate(matching, request.MaxResults, request.NextToken)
+	if err != nil {
+		return nil, err
+	}
+
+	networkInterfaces := make([]*ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSet, 0, len(page))
+	for _, networkInterface := range page {
+		networkInterfaces = append(networkInterfaces, deepCopy(networkInterface))
+	}
+
+	return &ecs.DescribeNetworkInterfacesResponse{
+		StatusCode: tea.Int32(http.StatusOK),
+		Body: &ecs.DescribeNetworkInterfacesResponseBody{
+			RequestId:            f.newRequestID(),
+			TotalCount:           tea.Int32(int32(len(matching))), // #nosec G115 -- the fake keeps only few network interfaces
+			NextToken:            nextToken,
+			NetworkInterfaceSets: &ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSets{NetworkInterfaceSet: networkInterfaces},
+		},
+	}, nil
+}
+
+// DeleteNetworkInterface deletes the network interface, which must not be attached to an instance
+func (f *ECS) DeleteNetworkInterface(request *ecs.DeleteNetworkInterfaceRequest) (*ecs.DeleteNetworkInterfaceResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("DeleteNetworkInterface"); err != nil {
+		return nil, err
+	}
+	networkInterfaceID := ptr.Deref(request.NetworkInterfaceId, "")
+	if networkInterfaceID == "" {
+		return nil, missingParameter("NetworkInterfaceId")
+	}
+	networkInterface, ok := f.networkInterfaces[networkInterfaceID]
+	if !ok {
+		return nil, NewError(http.StatusNotFound, invalidEniIDNotFound, "The specified network interface does not exist.")
+	}
+	if ptr.Deref(networkInterface.Status, "") != StatusAvailable {
+		return nil, NewError(http.StatusForbidden, invalidEniState, "The operation is not allowed in the current state of the network interface.")
+	}
+	delete(f.networkInterfaces, networkInterfaceID)
+
+	return &ecs.DeleteNetworkInterfaceResponse{
+		StatusCode: tea.Int32(http.StatusOK),
+		Body:       &ecs.DeleteNetworkInterfaceResponseBody{RequestId: f.newRequestID()},
+	}, nil
+}
+
+// TagResources adds the tags to the instances or network interfaces of the request, existing tags with the same keys
+// are overwritten
+func (f *ECS) TagResources(request *ecs.TagResourcesRequest) (*ecs.TagResourcesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("TagResources"); err != nil {
+		return nil, err
+	}
+	resourceType := ptr.Deref(request.ResourceType, "")
+	if resourceType != "instance" && resourceType != "eni" {
+		return nil, NewError(http.StatusBadRequest, invalidResourceTypeNotSupported, "The specified resource type is not supported.")
+	}
+	if len(request.Tag) == 0 {
+		return nil, missingParameter("Tag")
+	}
+	for _, tag := range request.Tag {
+		if err := validateTagValue(tag.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	// the tags are only added once all resources are known to exist
+	setTags := make([]func(key, value string), 0, len(request.ResourceId))
+	for _, id := range request.ResourceId {
+		if resourceType == "instance" {
+			if inst, ok := f.instances[ptr.Deref(id, "")]; ok {
+				setTags = append(setTags, inst.setTag)
+				continue
+			}
+		} else if networkInterface, ok := f.networkInterfaces[ptr.Deref(id, "")]; ok {
+			setTags = append(setTags, func(key, value string) { setNetworkInterfaceTag(networkInterface, key, value) })
+			continue
+		}
+		return nil, NewError(http.StatusNotFound, invalidResourceIDNotFound, "The specified resource does not exist.")
+	}
+
+	for _, setTag := range setTags {
+		for _, tag := range request.Tag {
+			setTag(ptr.Deref(tag.Key, ""), ptr.Deref(tag.Value, ""))
+		}
+	}
+
+	return &ecs.TagResourcesResponse{
+		StatusCode: tea.Int32(http.StatusOK),
+		Body:       &ecs.TagResourcesResponseBody{RequestId: f.newRequestID()},
+	}, nil
+}
+
+// AssignIpv6Addresses assigns the IPv6 addresses of the request, or the requested number of new ones, to the network
+// interface, which may be attached to an instance
+func (f *ECS) AssignIpv6Addresses(request *ecs.AssignIpv6AddressesRequest) (*ecs.AssignIpv6AddressesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("AssignIpv6Addresses"); err != nil {
+		return nil, err
+	}
+	if ptr.Deref(request.RegionId, "") == "" {
+		return nil, missingParameter("RegionId")
+	}
+	networkInterfaceID := ptr.Deref(request.NetworkInterfaceId, "")
+	if networkInterfaceID == "" {
+		return nil, missingParameter("NetworkInterfaceId")
+	}
+	if request.Ipv6AddressCount != nil && len(request.Ipv6Address) > 0 {
+		return nil, NewError(http.StatusBadRequest, maperror.InvalidParameter, "The parameters Ipv6Address.N and Ipv6AddressCount cannot be specified at the same time.")
+	}
+	networkInterface, ok := f.networkInterfaces[networkInterfaceID]
+	if !ok {
+		return nil, NewError(http.StatusNotFound, invalidEniIDNotFound, "The specified network interface does not exist.")
+	}
+
+	ipv6Addresses := tea.StringSliceValue(request.Ipv6Address)
+	for range ptr.Deref(request.Ipv6AddressCount, 0) {
+		ipv6Addresses = append(ipv6Addresses, f.newIPv6())
+	}
+	if len(ipv6Addresses) == 0 {
+		return nil, missingParameter("Ipv6AddressCount")
+	}
+
+	var instanceNetworkInterface *ecs.DescribeInstancesResponseBodyInstancesInstanceNetworkInterfacesNetworkInterface
+	if inst, ok := f.instances[ptr.Deref(networkInterface.InstanceId, "")]; ok {
+		for _, attached := range inst.NetworkInterfaces.NetworkInterface {
+			if ptr.Deref(attached.NetworkInterfaceId, "") == networkInterfaceID {
+				instanceNetworkInterface = attached
+			}
+		}
+	}
+	for _, address := range ipv6Addresses {
+		networkInterface.Ipv6Sets.Ipv6Set = append(networkInterface.Ipv6Sets.Ipv6Set, &ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSetIpv6SetsIpv6Set{Ipv6Address: tea.String(address)})
+		if instanceNetworkInterface != nil {
+			instanceNetworkInterface.Ipv6Sets.Ipv6Set = append(instanceNetworkInterface.Ipv6Sets.Ipv6Set, &ecs.DescribeInstancesResponseBodyInstancesInstanceNetworkInterfacesNetworkInterfaceIpv6SetsIpv6Set{Ipv6Address: tea.String(address)})
+		}
+	}
+
+	return &ecs.AssignIpv6AddressesResponse{
+		StatusCode: tea.Int32(http.StatusOK),
+		Body: &ecs.AssignIpv6AddressesResponseBody{
+			RequestId:          f.newRequestID(),
+			NetworkInterfaceId: tea.String(networkInterfaceID),
+			Ipv6Sets:           &ecs.AssignIpv6AddressesResponseBodyIpv6Sets{Ipv6Address: tea.StringSlice(ipv6Addresses)},
+		},
+	}, nil
+}
+
+// DescribeVSwitches returns the vSwitches added with AddVSwitch, filtered by the vSwitch ID of the request
+func (f *ECS) DescribeVSwitches(request *vpc.DescribeVSwitchesRequest) (*vpc.DescribeVSwitchesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("DescribeVSwitches"); err != nil {
+		return nil, err
+	}
+	if ptr.Deref(request.RegionId, "") == "" {
+		return nil, missingParameter("RegionId")
+	}
+
+	vSwitches := []*vpc.DescribeVSwitchesResponseBodyVSwitchesVSwitch{}
+	for _, id := range slices.Sorted(maps.Keys(f.vSwitches)) {
+		if matches(request.VSwitchId, &id) {
+			vSwitches = append(vSwitches, deepCopy(f.vSwitches[id]))
+		}
+	}
+
+	return &vpc.DescribeVSwitchesResponse{
+		StatusCode: tea.Int32(http.StatusOK),
+		Body: &vpc.DescribeVSwitchesResponseBody{
+			RequestId:  f.newRequestID(),
+			TotalCount: tea.Int32(int32(len(vSwitches))), // #nosec G115 -- the fake keeps only few vSwitches
+			VSwitches:  &vpc.DescribeVSwitchesResponseBodyVSwitches{VSwitch: vSwitches},
+		},
+	}, nil
+}
+
+func (i *instance) setTag(key, value string) {
+	for _, tag := range i.Tags.Tag {
+		if ptr.Deref(tag.TagKey, "") == key {
+			tag.TagValue = tea.String(value)
+			return
+		}
+	}
+	i.Tags.Tag = append(i.Tags.Tag, &ecs.DescribeInstancesResponseBodyInstancesInstanceTagsTag{TagKey: tea.String(key), TagValue: tea.String(value)})
+}
+
+func setNetworkInterfaceTag(networkInterface *ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSet, key, value string) {
+	for _, tag := range networkInterface.Tags.Tag {
+		if ptr.Deref(tag.TagKey, "") == key {
+			tag.TagValue = tea.String(value)
+			return
+		}
+	}
+	networkInterface.Tags.Tag = append(networkInterface.Tags.Tag, &ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSetTagsTag{TagKey: tea.String(key), TagValue: tea.String(value)})
+}
+
+// call counts a call of the given action and returns the next error injected for it
+func (f *ECS) call(action string) error {
+	f.calls[action]++
+	if errs := f.failures[action]; len(errs) > 0 {
+		f.failures[action] = errs[1:]
+		return errs[0]
+	}
+	return nil
+}
+
+// advance moves an instance which is Pending or Stopping one step further and returns false if it has been released
+func (f *ECS) advance(inst *instance) bool {
+	switch ptr.Deref(inst.Status, "") {
+	case InstanceStatusPending:
+		if inst.transitions > 0 {
+			inst.transitions--
+			return true
+		}
+		inst.Status = tea.String(InstanceStatusRunning)
+	case InstanceStatusStopping:
+		if inst.transitions > 0 {
+			inst.transitions--
+			return true
+		}
+		f.release(inst)
+		return false
+	}
+	return true
+}
+
+// release removes the instance together with the disks and network interfaces which are deleted with it, all other
+// disks and network interfaces become Available
+func (f *ECS) release(inst *instance) {
+	instanceID := ptr.Deref(inst.InstanceId, "")
+	delete(f.instances, instanceID)
+
+	for id, disk := range f.disks {
+		if ptr.Deref(disk.InstanceId, "") != instanceID {
+			continue
+		}
+		if ptr.Deref(disk.DeleteWithInstance, false) {
+			delete(f.disks, id)
+			continue
+		}
+		disk.Status = tea.String(StatusAvailable)
+		disk.InstanceId = tea.String("")
+		disk.DetachedTime = tea.String(time.Now().UTC().Format(creationTimeLayout))
+	}
+
+	for id, networkInterface := range f.networkInterfaces {
+		if ptr.Deref(networkInterface.InstanceId, "") != instanceID {
+			continue
+		}
+		if ptr.Deref(networkInterface.DeleteOnRelease, false) {
+			delete(f.networkInterfaces, id)
+			continue
+		}
+		networkInterface.Status = tea.String(StatusAvailable)
+		networkInterface.InstanceId = tea.String("")
+		networkInterface.Attachment = nil
+	}
+}
+
+// attachDisk creates a disk attached to the given instance
+func (f *ECS) attachDisk(inst *instance, request *ecs.RunInstancesRequestDataDisk, diskType string, tags map[string]string) {
+	disk := &ecs.DescribeDisksResponseBodyDisksDisk{
+		DiskId:             tea.String(f.newID("d-")),
+		DiskName:           request.DiskName,
+		Description:        request.Description,
+		Category:           request.Category,
+		Size:               request.Size,
+		Type:               tea.String(diskType),
+		Encrypted:          tea.Bool(ptr.Deref(request.Encrypted, "") == "true"),
+		DeleteWithInstance: tea.Bool(ptr.Deref(request.DeleteWithInstance, true)),
+		Status:             tea.String(DiskStatusInUse),
+		InstanceId:         inst.InstanceId,
+		RegionId:           inst.RegionId,
+		ZoneId:             inst.ZoneId,
+		CreationTime:       inst.CreationTime,
+		Tags:               &ecs.DescribeDisksResponseBodyDisksDiskTags{},
+	}
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		disk.Tags.Tag = append(disk.Tags.Tag, &ecs.DescribeDisksResponseBodyDisksDiskTagsTag{TagKey: tea.String(key), TagValue: tea.String(tags[key])})
+	}
+	f.disks[*disk.DiskId] = disk
+}
+
+// attachNetworkInterface creates a network interface attached to the given instance
+func (f *ECS) attachNetworkInterface(inst *instance, runRequest *ecs.RunInstancesRequest, request *ecs.RunInstancesRequestNetworkInterface, networkInterfaceType, ip string, ipv6Addresses []string, tags map[string]string) {
+	networkInterfaceID := f.newID("eni-")
+	vSwitchID := ptr.Deref(request.VSwitchId, ptr.Deref(runRequest.VSwitchId, ""))
+
+	networkInterface := &ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSet{
+		NetworkInterfaceId:   tea.String(networkInterfaceID),
+		NetworkInterfaceName: request.NetworkInterfaceName,
+		Description:          request.Description,
+		Type:                 tea.String(networkInterfaceType),
+		Status:               tea.String(NetworkInterfaceStatusInUse),
+		InstanceId:           inst.InstanceId,
+		VSwitchId:            tea.String(vSwitchID),
+		ZoneId:               inst.ZoneId,
+		PrivateIpAddress:     tea.String(ip),
+		DeleteOnRelease:      tea.Bool(ptr.Deref(request.DeleteOnRelease, networkInterfaceType == networkInterfaceTypePrimary)),
+		CreationTime:         inst.CreationTime,
+		Tags:                 &ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSetTags{},
+		Ipv6Sets:             &ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSetIpv6Sets{},
+		SecurityGroupIds:     &ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSetSecurityGroupIds{SecurityGroupId: securityGroupIDs(request)},
+	}
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		networkInterface.Tags.Tag = append(networkInterface.Tags.Tag, &ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSetTagsTag{TagKey: tea.String(key), TagValue: tea.String(tags[key])})
+	}
+	f.networkInterfaces[networkInterfaceID] = networkInterface
+
+	instanceNetworkInterface := &ecs.DescribeInstancesResponseBodyInstancesInstanceNetworkInterfacesNetworkInterface{
+		NetworkInterfaceId: tea.String(networkInterfaceID),
+		Type:               tea.String(networkInterfaceType),
+		PrimaryIpAddress:   tea.String(ip),
+		Ipv6Sets:           &ecs.DescribeInstancesResponseBodyInstancesInstanceNetworkInterfacesNetworkInterfaceIpv6Sets{},
+	}
+	for _, address := range ipv6Addresses {
+		networkInterface.Ipv6Sets.Ipv6Set = append(networkInterface.Ipv6Sets.Ipv6Set, &ecs.DescribeNetworkInterfacesResponseBodyNetworkInterfaceSetsNetworkInterfaceSetIpv6SetsIpv6Set{Ipv6Address: tea.String(address)})
+		instanceNetworkInterface.Ipv6Sets.Ipv6Set = append(instanceNetworkInterface.Ipv6Sets.Ipv6Set, &ecs.DescribeInstancesResponseBodyInstancesInstanceNetworkInterfacesNetworkInterfaceIpv6SetsIpv6Set{Ipv6Address: tea.String(address)})
+	}
+	inst.NetworkInterfaces.NetworkInterface = append(inst.NetworkInterfaces.NetworkInterface, instanceNetworkInterface)
+}
+
+// securityGroupIDs returns the security groups of the given network interface
+func securityGroupIDs(request *ecs.RunInstancesRequestNetworkInterface) []*string {
+	var ids []*string
+	if request.SecurityGroupId != nil {
+		ids = append(ids, request.SecurityGroupId)
+	}
+	return append(ids, request.SecurityGroupIds...)
+}
+
+func (f *ECS) runInstancesResponse(instanceID string) *ecs.RunInstancesResponse {
+	return &ecs.RunInstancesResponse{
+		StatusCode: tea.Int32(http.StatusOK),
+		Body: &ecs.RunInstancesResponseBody{
+			RequestId:      f.newRequestID(),
+			InstanceIdSets: &ecs.RunInstancesResponseBodyInstanceIdSets{InstanceIdSet: []*string{tea.String(instanceID)}},
+		},
+	}
+}
+
+func (f *ECS) newID(prefix string) string {
+	f.lastID++
+	return fmt.Sprintf("%sfake%08d", prefix, f.lastID)
+}
+
+func (f *ECS) newIP() string {
+	f.lastID++
+	return fmt.Sprintf("10.250.%d.%d", f.lastID/250, f.lastID%250+1)
+}
+
+func (f *ECS) newIPv6() string {
+	f.lastID++
+	return fmt.Sprintf("2408:4000:1000::%x", f.lastID)
+}
+
+func (f *ECS) newRequestID() *string {
+	f.lastID++
+	return tea.String(fmt.Sprintf("fake-request-%08d", f.lastID))
+}
+
+func missingParameter(name string) error {
+	return NewError(http.StatusBadRequest, maperror.MissingParameter, fmt.Sprintf("The input parameter %q that is mandatory for processing this request is not supplied.", name))
+}
+
+// validateTagValue returns the error of ECS for tag values which are too long
+func validateTagValue(value *string) error {
+	if len(ptr.Deref(value, "")) > maxTagValueLength {
+		return NewError(http.StatusBadRequest, invalidTagValueMalformed, "The specified Tag.n.Value is not valid.")
+	}
+	return nil
+}
+
+// parseIDs parses a JSON array of IDs, nil is returned if the parameter is not set
+func parseIDs(name string, value *string) ([]string, error) {
+	if ptr.Deref(value, "") == "" {
+		return nil, nil
+	}
+	var ids []string
+	if err := json.Unmarshal([]byte(*value), &ids); err != nil {
+		return nil, NewError(http.StatusBadRequest, maperror.InvalidParameter, fmt.Sprintf("The specified parameter %q is not valid.", name))
+	}
+	return ids, nil
+}
+
+// paginate returns the page of the given items starting at the NextToken and the token of the next page
+func paginate[T any](items []T, maxResults *int32, nextToken *string) ([]T, *string, error) {
+	pageSize := int(ptr.Deref(maxResults, defaultMaxResults))
+	if pageSize <= 0 || pageSize > maxMaxResults {
+		return nil, nil, NewError(http.StatusBadRequest, maperror.InvalidParameter, "The specified parameter \"MaxResults\" is not valid.")
+	}
+
+	start := 0
+	if token := ptr.Deref(nextToken, ""); token != "" {
+		offset, err := strconv.Atoi(strings.TrimPrefix(token, "fake-token-"))
+		if err != nil || !strings.HasPrefix(token, "fake-token-") || offset < 0 || offset > len(items) {
+			return nil, nil, NewError(http.StatusBadRequest, maperror.InvalidParameter, "The specified parameter \"NextToken\" is not valid.")
+		}
+		start = offset
+	}
+
+	end := min(start+pageSize, len(items))
+	if end == len(items) {
+		return items[start:end], nil, nil
+	}
+	return items[start:end], tea.String(fmt.Sprintf("fake-token-%d", end)), nil
+}
+
+func matches(filter, value *string) bool {
+	return filter == nil || *filter == ptr.Deref(value, "")
+}
+
+func matchesFold(filter, value *string) bool {
+	return filter == nil || strings.EqualFold(*filter, ptr.Deref(value, ""))
+}
+
+// matchesTags returns whether the given tags contain all tags of the filter, tags without a value match any value
+func matchesTags[T any](tags map[string]string, filter []*T, keyValue func(*T) (*string, *string)) bool {
+	for _, tag := range filter {
+		key, value := keyValue(tag)
+		actual, ok := tags[ptr.Deref(key, "")]
+		if !ok || (value != nil && *value != actual) {
+			return false
+		}
+	}
+	return true
+}
+
+// deepCopy returns a deep copy of an ECS model, so that callers cannot modify the state of the fake
+func deepCopy[T any](in *T) *T {
+	data, err := json.Marshal(in)
+	if err != nil {
+		panic(err)
+	}
+	out := new(T)
+	if err := json.Unmarshal(data, out); err != nil {
+		panic(err)
+	}
+	return out
+}
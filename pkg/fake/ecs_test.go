@@ -0,0 +1,256 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package fake
+
+import (
+	"net/http"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
+	"github.com/alibabacloud-go/tea/tea"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	maperror "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/errors"
+)
+
+var _ = Describe("Fake ECS", func() {
+	var (
+		fakeECS *ECS
+
+		region = "cn-shanghai"
+
+		runInstance = func(name string, tags map[string]string) string {
+			request := &ecs.RunInstancesRequest{
+				RegionId:     tea.String(region),
+				ImageId:      tea.String("m-image"),
+				InstanceType: tea.String("ecs.g6.large"),
+				VSwitchId:    tea.String("vsw-1"),
+				InstanceName: tea.String(name),
+				ClientToken:  tea.String(name),
+				DataDisk: []*ecs.RunInstancesRequestDataDisk{
+					{DiskName: tea.String(name + "-kept"), DeleteWithInstance: tea.Bool(false)},
+					{DiskName: tea.String(name + "-deleted"), DeleteWithInstance: tea.Bool(true)},
+				},
+				NetworkInterface: []*ecs.RunInstancesRequestNetworkInterface{
+					{NetworkInterfaceName: tea.String(name + "-eni"), InstanceType: tea.String("Secondary")},
+				},
+			}
+			for k, v := range tags {
+				request.Tag = append(request.Tag, &ecs.RunInstancesRequestTag{Key: tea.String(k), Value: tea.String(v)})
+			}
+			response, err := fakeECS.RunInstances(request)
+			Expect(err).NotTo(HaveOccurred())
+			return *response.Body.InstanceIdSets.InstanceIdSet[0]
+		}
+		describeInstance = func(instanceID string) []*ecs.DescribeInstancesResponseBodyInstancesInstance {
+			response, err := fakeECS.DescribeInstances(&ecs.DescribeInstancesRequest{RegionId: tea.String(region), InstanceIds: tea.String(`["` + instanceID + `"]`)})
+			Expect(err).NotTo(HaveOccurred())
+			return response.Body.Instances.Instance
+		}
+	)
+
+	BeforeEach(func() {
+		fakeECS = NewECS()
+	})
+
+	It("should launch instances with their disks and network interfaces only once per client token", func() {
+		instanceID := runInstance("machine", map[string]string{"cluster": "shoot"})
+		Expect(runInstance("machine", nil)).To(Equal(instanceID))
+
+		instances := describeInstance(instanceID)
+		Expect(instances).To(HaveLen(1))
+		Expect(*instances[0].Status).To(Equal(InstanceStatusRunning))
+		Expect(instances[0].NetworkInterfaces.NetworkInterface).To(HaveLen(2))
+		Expect(fakeECS.Disks()).To(HaveLen(3))
+		Expect(fakeECS.NetworkInterfaces()).To(HaveLen(2))
+		for _, disk := range fakeECS.Disks() {
+			Expect(*disk.Status).To(Equal(DiskStatusInUse))
+			Expect(disk.Tags.Tag).To(ConsistOf(&ecs.DescribeDisksResponseBodyDisksDiskTagsTag{TagKey: tea.String("cluster"), TagValue: tea.String("shoot")}))
+		}
+	})
+
+	It("should emulate the state transitions and the release of disks and network interfaces", func() {
+		fakeECS.Transitions = 1
+		instanceID := runInstance("machine", nil)
+
+		Expect(*describeInstance(instanceID)[0].Status).To(Equal(InstanceStatusPending))
+		Expect(*describeInstance(instanceID)[0].Status).To(Equal(InstanceStatusRunning))
+
+		_, err := fakeECS.DeleteInstance(&ecs.DeleteInstanceRequest{InstanceId: tea.String(instanceID)})
+		Expect(maperror.HasErrorCode(err, maperror.IncorrectInstanceStatus)).To(BeTrue())
+		_, err = fakeECS.DeleteInstance(&ecs.DeleteInstanceRequest{InstanceId: tea.String(instanceID), Force: tea.Bool(true)})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(*describeInstance(instanceID)[0].Status).To(Equal(InstanceStatusStopping))
+		Expect(describeInstance(instanceID)).To(BeEmpty())
+
+		_, err = fakeECS.DeleteInstance(&ecs.DeleteInstanceRequest{InstanceId: tea.String(instanceID), Force: tea.Bool(true)})
+		Expect(maperror.HasErrorCode(err, maperror.InvalidInstanceIDNotFound)).To(BeTrue())
+
+		disks := fakeECS.Disks()
+		Expect(disks).To(HaveLen(1))
+		Expect(*disks[0].DiskName).To(Equal("machine-kept"))
+		Expect(*disks[0].Status).To(Equal(StatusAvailable))
+		networkInterfaces := fakeECS.NetworkInterfaces()
+		Expect(networkInterfaces).To(HaveLen(1))
+		Expect(*networkInterfaces[0].NetworkInterfaceName).To(Equal("machine-eni"))
+		Expect(*networkInterfaces[0].Status).To(Equal(StatusAvailable))
+
+		_, err = fakeECS.DeleteDisk(&ecs.DeleteDiskRequest{DiskId: disks[0].DiskId})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = fakeECS.DeleteNetworkInterface(&ecs.DeleteNetworkInterfaceRequest{RegionId: tea.String(region), NetworkInterfaceId: networkInterfaces[0].NetworkInterfaceId})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeECS.Disks()).To(BeEmpty())
+		Expect(fakeECS.NetworkInterfaces()).To(BeEmpty())
+	})
+
+	It("should not delete disks and network interfaces which are in use", func() {
+		runInstance("machine", nil)
+
+		_, err := fakeECS.DeleteDisk(&ecs.DeleteDiskRequest{DiskId: fakeECS.Disks()[0].DiskId})
+		Expect(maperror.HasErrorCode(err, incorrectDiskStatus)).To(BeTrue())
+		_, err = fakeECS.DeleteNetworkInterface(&ecs.DeleteNetworkInterfaceRequest{RegionId: tea.String(region), NetworkInterfaceId: fakeECS.NetworkInterfaces()[0].NetworkInterfaceId})
+		Expect(maperror.HasErrorCode(err, invalidEniState)).To(BeTrue())
+	})
+
+	It("should filter by tags and page the results", func() {
+		for _, name := range []string{"a", "b", "c", "d", "e"} {
+			runInstance(name, map[string]string{"cluster": "shoot", "pool": name})
+		}
+		runInstance("other", map[string]string{"cluster": "other"})
+
+		request := &ecs.DescribeInstancesRequest{
+			RegionId:   tea.String(region),
+			MaxResults: tea.Int32(2),
+			Tag:        []*ecs.DescribeInstancesRequestTag{{Key: tea.String("cluster"), Value: tea.String("shoot")}, {Key: tea.String("pool")}},
+		}
+		var names []string
+		for {
+			response, err := fakeECS.DescribeInstances(request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*response.Body.TotalCount).To(Equal(int32(5)))
+			for _, instance := range response.Body.Instances.Instance {
+				names = append(names, *instance.InstanceName)
+			}
+			if response.Body.NextToken == nil {
+				break
+			}
+			request.NextToken = response.Body.NextToken
+		}
+		Expect(names).To(Equal([]string{"a", "b", "c", "d", "e"}))
+
+		request.NextToken = tea.String("invalid")
+		_, err := fakeECS.DescribeInstances(request)
+		Expect(maperror.HasErrorCode(err, maperror.InvalidParameter)).To(BeTrue())
+	})
+
+	It("should tag instances", func() {
+		instanceID := runInstance("machine", map[string]string{"cluster": "shoot"})
+
+		_, err := fakeECS.TagResources(&ecs.TagResourcesRequest{
+			RegionId:     tea.String(region),
+			ResourceType: tea.String("instance"),
+			ResourceId:   []*string{tea.String(instanceID)},
+			Tag:          []*ecs.TagResourcesRequestTag{{Key: tea.String("cluster"), Value: tea.String("other")}, {Key: tea.String("role"), Value: tea.String("worker")}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(describeInstance(instanceID)[0].Tags.Tag).To(ConsistOf(
+			&ecs.DescribeInstancesResponseBodyInstancesInstanceTagsTag{TagKey: tea.String("cluster"), TagValue: tea.String("other")},
+			&ecs.DescribeInstancesResponseBodyInstancesInstanceTagsTag{TagKey: tea.String("role"), TagValue: tea.String("worker")},
+		))
+	})
+
+	It("should tag the primary network interface on launch and the secondary ones on request", func() {
+		runInstance("machine", map[string]string{"cluster": "shoot"})
+		describeNetworkInterfaces := func() []string {
+			response, err := fakeECS.DescribeNetworkInterfaces(&ecs.DescribeNetworkInterfacesRequest{
+				RegionId: tea.String(region),
+				Tag:      []*ecs.DescribeNetworkInterfacesRequestTag{{Key: tea.String("cluster"), Value: tea.String("shoot")}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			var types []string
+			for _, networkInterface := range response.Body.NetworkInterfaceSets.NetworkInterfaceSet {
+				types = append(types, *networkInterface.Type)
+			}
+			return types
+		}
+		Expect(describeNetworkInterfaces()).To(ConsistOf("Primary"))
+
+		var secondaryID *string
+		for _, networkInterface := range fakeECS.NetworkInterfaces() {
+			if *networkInterface.Type == "Secondary" {
+				secondaryID = networkInterface.NetworkInterfaceId
+			}
+		}
+		_, err := fakeECS.TagResources(&ecs.TagResourcesRequest{
+			RegionId:     tea.String(region),
+			ResourceType: tea.String("eni"),
+			ResourceId:   []*string{secondaryID},
+			Tag:          []*ecs.TagResourcesRequestTag{{Key: tea.String("cluster"), Value: tea.String("shoot")}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(describeNetworkInterfaces()).To(ConsistOf("Primary", "Secondary"))
+	})
+
+	It("should fail the next calls with the injected errors", func() {
+		fakeECS.FailNext("DescribeInstances", NewError(http.StatusServiceUnavailable, maperror.ServiceUnavailable, "The request has failed due to a temporary failure of the server."))
+
+		_, err := fakeECS.DescribeInstances(&ecs.DescribeInstancesRequest{RegionId: tea.String(region)})
+		Expect(maperror.IsTransient(err)).To(BeTrue())
+		Expect(maperror.GetRequestID(err)).To(Equal("fake-error-request-id"))
+		_, err = fakeECS.DescribeInstances(&ecs.DescribeInstancesRequest{RegionId: tea.String(region)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeECS.Calls("DescribeInstances")).To(Equal(2))
+	})
+
+	It("should assign IPv6 addresses at launch to the primary network interface only", func() {
+		request := &ecs.RunInstancesRequest{
+			RegionId:     tea.String(region),
+			ImageId:      tea.String("m-image"),
+			InstanceType: tea.String("ecs.g6.large"),
+			NetworkInterface: []*ecs.RunInstancesRequestNetworkInterface{
+				{InstanceType: tea.String("Primary"), VSwitchId: tea.String("vsw-1"), Ipv6AddressCount: tea.Int64(2)},
+				{InstanceType: tea.String("Secondary"), VSwitchId: tea.String("vsw-2"), Ipv6Address: tea.StringSlice([]string{"2408:4005:39c:8300::10"})},
+			},
+		}
+		_, err := fakeECS.RunInstances(request)
+		Expect(err).To(MatchError(ContainSubstring(maperror.InvalidParameter)))
+		Expect(fakeECS.Instances()).To(BeEmpty())
+
+		request.NetworkInterface[1].Ipv6Address = nil
+		response, err := fakeECS.RunInstances(request)
+		Expect(err).NotTo(HaveOccurred())
+		networkInterfaces := describeInstance(*response.Body.InstanceIdSets.InstanceIdSet[0])[0].NetworkInterfaces.NetworkInterface
+		Expect(networkInterfaces).To(HaveLen(2))
+		for _, networkInterface := range networkInterfaces {
+			if *networkInterface.Type == "Primary" {
+				Expect(networkInterface.Ipv6Sets.Ipv6Set).To(HaveLen(2))
+			} else {
+				Expect(networkInterface.Ipv6Sets).To(Or(BeNil(), HaveField("Ipv6Set", BeEmpty())))
+			}
+		}
+	})
+
+	It("should assign IPv6 addresses to attached network interfaces", func() {
+		instanceID := runInstance("machine", nil)
+		networkInterfaceID := describeInstance(instanceID)[0].NetworkInterfaces.NetworkInterface[1].NetworkInterfaceId
+
+		_, err := fakeECS.AssignIpv6Addresses(&ecs.AssignIpv6AddressesRequest{RegionId: tea.String(region), NetworkInterfaceId: tea.String("eni-unknown"), Ipv6AddressCount: tea.Int32(1)})
+		Expect(err).To(MatchError(ContainSubstring("InvalidEniId.NotFound")))
+		_, err = fakeECS.AssignIpv6Addresses(&ecs.AssignIpv6AddressesRequest{RegionId: tea.String(region), NetworkInterfaceId: networkInterfaceID})
+		Expect(err).To(MatchError(ContainSubstring(maperror.MissingParameter)))
+
+		response, err := fakeECS.AssignIpv6Addresses(&ecs.AssignIpv6AddressesRequest{
+			RegionId:           tea.String(region),
+			NetworkInterfaceId: networkInterfaceID,
+			Ipv6Address:        tea.StringSlice([]string{"2408:4005:39c:8300::10"}),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Body.Ipv6Sets.Ipv6Address).To(Equal(tea.StringSlice([]string{"2408:4005:39c:8300::10"})))
+		Expect(describeInstance(instanceID)[0].NetworkInterfaces.NetworkInterface[1].Ipv6Sets.Ipv6Set).To(ConsistOf(
+			HaveField("Ipv6Address", HaveValue(Equal("2408:4005:39c:8300::10"))),
+		))
+	})
+})
@@ -0,0 +1,319 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package fake
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"k8s.io/utils/ptr"
+
+	maperror "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/errors"
+)
+
+const (
+	// signatureAlgorithm is the algorithm the ECS SDK signs requests with
+	signatureAlgorithm = "ACS3-HMAC-SHA256"
+
+	headerAction        = "x-acs-action"
+	headerContentSHA256 = "x-acs-content-sha256"
+	responseContentType = "application/json;charset=utf-8"
+
+	contentSHA256DoesNotMatch  = "ContentSHA256DoesNotMatch"
+	incompleteSignature        = "IncompleteSignature"
+	internalError              = "InternalError"
+	invalidAccessKeyIDNotFound = "InvalidAccessKeyId.NotFound"
+	invalidActionNotFound      = "InvalidAction.NotFound"
+	signatureDoesNotMatch      = "SignatureDoesNotMatch"
+)
+
+// handler decodes the parameters of an ECS action, calls the fake and returns the body of the response
+type handler func(params url.Values) (any, error)
+
+// Server serves the fake ECS over the RPC protocol of the ECS OpenAPI, so that the real ECS SDK client can be pointed
+// at it with an endpoint override. This tests the request signing, the serialization of the request parameters and
+// the decoding of error responses, which are skipped if the fake is used as spi.ECSClient directly.
+//
+// The actions RunInstances, DescribeInstances, DeleteInstance, DescribeDisks, DeleteDisk, DescribeNetworkInterfaces,
+// DeleteNetworkInterface, TagResources and AssignIpv6Addresses are served, errors of the fake are returned as ECS error responses with
+// their HTTP status code. The DescribeVSwitches action of the VPC API is served as well, so that the server can be
+// used as VPC endpoint, too.
+type Server struct {
+	// ECS is the fake serving the actions
+	ECS *ECS
+	// AccessKeys maps access key IDs to their secrets. If it is not empty, the signature of every request is verified
+	// and requests signed with unknown or wrong credentials are rejected.
+	AccessKeys map[string]string
+
+	handlers map[string]handler
+}
+
+var _ http.Handler = &Server{}
+
+// NewServer returns a server serving the given fake ECS
+func NewServer(f *ECS) *Server {
+	return &Server{
+		ECS: f,
+		handlers: map[string]handler{
+			"RunInstances":              serve(f.RunInstances),
+			"DescribeInstances":         serve(f.DescribeInstances),
+			"DeleteInstance":            serve(f.DeleteInstance),
+			"DescribeDisks":             serve(f.DescribeDisks),
+			"DeleteDisk":                serve(f.DeleteDisk),
+			"DescribeNetworkInterfaces": serve(f.DescribeNetworkInterfaces),
+			"DeleteNetworkInterface":    serve(f.DeleteNetworkInterface),
+			"TagResources":              serve(f.TagResources),
+			"AssignIpv6Addresses":       serve(f.AssignIpv6Addresses),
+			"DescribeVSwitches":         serve(f.DescribeVSwitches),
+		},
+	}
+}
+
+// ServeHTTP serves an ECS OpenAPI RPC request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, NewError(http.StatusBadRequest, maperror.InvalidParameter, err.Error()))
+		return
+	}
+	if len(s.AccessKeys) > 0 {
+		if err := s.verifySignature(r, body); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err := r.ParseForm(); err != nil {
+		writeError(w, NewError(http.StatusBadRequest, maperror.InvalidParameter, err.Error()))
+		return
+	}
+	action := r.Header.Get(headerAction)
+	if action == "" {
+		action = r.Form.Get("Action")
+	}
+	handle, ok := s.handlers[action]
+	if !ok {
+		writeError(w, NewError(http.StatusNotFound, invalidActionNotFound, fmt.Sprintf("The specified action %q is not supported.", action)))
+		return
+	}
+
+	responseBody, err := handle(r.Form)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", responseContentType)
+	_ = json.NewEncoder(w).Encode(responseBody)
+}
+
+// verifySignature verifies the ACS3-HMAC-SHA256 signature of the given request as described in
+// https://www.alibabacloud.com/help/en/sdk/product-overview/v3-request-structure-and-signature
+func (s *Server) verifySignature(r *http.Request, body []byte) error {
+	algorithm, parameters, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if algorithm != signatureAlgorithm {
+		return NewError(http.StatusBadRequest, incompleteSignature, fmt.Sprintf("The signature algorithm %q is not supported.", algorithm))
+	}
+	var accessKeyID, signedHeaders, signature string
+	for _, parameter := range strings.Split(parameters, ",") {
+		name, value, _ := strings.Cut(parameter, "=")
+		switch name {
+		case "Credential":
+			accessKeyID = value
+		case "SignedHeaders":
+			signedHeaders = value
+		case "Signature":
+			signature = value
+		}
+	}
+	if accessKeyID == "" || signedHeaders == "" || signature == "" {
+		return NewError(http.StatusBadRequest, incompleteSignature, "The request signature does not conform to Aliyun standards.")
+	}
+	accessKeySecret, ok := s.AccessKeys[accessKeyID]
+	if !ok {
+		return NewError(http.StatusNotFound, invalidAccessKeyIDNotFound, "Specified access key is not found.")
+	}
+
+	payloadHash := sha256.Sum256(body)
+	if hex.EncodeToString(payloadHash[:]) != r.Header.Get(headerContentSHA256) {
+		return NewError(http.StatusBadRequest, contentSHA256DoesNotMatch, "The content SHA256 does not match the request body.")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		values := r.Header.Values(name)
+		if name == "host" {
+			values = []string{r.Host}
+		}
+		values = slices.Clone(values)
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+		slices.Sort(values)
+		canonicalHeaders.WriteString(name + ":" + strings.Join(values, ",") + "\n")
+	}
+	canonicalURI := r.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		canonicalURI,
+		canonicalQueryString(r.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		r.Header.Get(headerContentSHA256),
+	}, "\n")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := signatureAlgorithm + "\n" + hex.EncodeToString(canonicalRequestHash[:])
+
+	mac := hmac.New(sha256.New, []byte(accessKeySecret))
+	mac.Write([]byte(stringToSign))
+	if !hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(signature)) {
+		return NewError(http.StatusBadRequest, signatureDoesNotMatch, "The request signature does not conform to Aliyun standards.")
+	}
+	return nil
+}
+
+// canonicalQueryString returns the query parameters sorted by name and percent-encoded
+func canonicalQueryString(query url.Values) string {
+	var parameters []string
+	for _, name := range slices.Sorted(maps.Keys(query)) {
+		parameters = append(parameters, percentEncode(name)+"="+percentEncode(query.Get(name)))
+	}
+	return strings.Join(parameters, "&")
+}
+
+// percentEncode encodes the given value as required for the canonical request, i.e. RFC 3986 with spaces as %20
+func percentEncode(value string) string {
+	encoded := url.QueryEscape(value)
+	encoded = strings.ReplaceAll(encoded, "+", "%20")
+	encoded = strings.ReplaceAll(encoded, "*", "%2A")
+	return strings.ReplaceAll(encoded, "%7E", "~")
+}
+
+// serve returns the handler of the ECS action implemented by the given method of the fake
+func serve[Request, Response any](call func(*Request) (*Response, error)) handler {
+	return func(params url.Values) (any, error) {
+		request := new(Request)
+		if err := decodeParams(params, "", reflect.ValueOf(request).Elem()); err != nil {
+			return nil, NewError(http.StatusBadRequest, maperror.InvalidParameter, err.Error())
+		}
+		response, err := call(request)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(response).Elem().FieldByName("Body").Interface(), nil
+	}
+}
+
+// decodeParams decodes the request parameters with the given name into the given value. The parameters are flattened
+// like the ECS SDK does it, i.e. fields of structs are named `<name>.<field>` and elements of lists `<name>.<index>`,
+// starting at 1.
+func decodeParams(params url.Values, name string, value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if !hasParam(params, name) {
+			return nil
+		}
+		elem := reflect.New(value.Type().Elem())
+		if err := decodeParams(params, name, elem.Elem()); err != nil {
+			return err
+		}
+		value.Set(elem)
+	case reflect.Struct:
+		for i := range value.NumField() {
+			fieldName, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ",")
+			if fieldName == "" || fieldName == "-" {
+				continue
+			}
+			if name != "" {
+				fieldName = name + "." + fieldName
+			}
+			if err := decodeParams(params, fieldName, value.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 1; hasParam(params, name+"."+strconv.Itoa(i)); i++ {
+			elem := reflect.New(value.Type().Elem()).Elem()
+			if err := decodeParams(params, name+"."+strconv.Itoa(i), elem); err != nil {
+				return err
+			}
+			value.Set(reflect.Append(value, elem))
+		}
+	case reflect.String:
+		value.SetString(params.Get(name))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(params.Get(name))
+		if err != nil {
+			return fmt.Errorf("the parameter %q is not a boolean: %w", name, err)
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(params.Get(name), 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("the parameter %q is not an integer: %w", name, err)
+		}
+		value.SetInt(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(params.Get(name), value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("the parameter %q is not a number: %w", name, err)
+		}
+		value.SetFloat(f)
+	default:
+		return fmt.Errorf("the parameter %q has the unsupported type %s", name, value.Type())
+	}
+	return nil
+}
+
+// hasParam returns true if the request has the parameter with the given name or any of its fields or elements
+func hasParam(params url.Values, name string) bool {
+	if params.Has(name) {
+		return true
+	}
+	for param := range params {
+		if strings.HasPrefix(param, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeError writes the ECS error response of the given error
+func writeError(w http.ResponseWriter, err error) {
+	statusCode, code, message := http.StatusInternalServerError, internalError, err.Error()
+	var sdkErr *tea.SDKError
+	if errors.As(err, &sdkErr) {
+		statusCode, code, message = ptr.Deref(sdkErr.StatusCode, statusCode), ptr.Deref(sdkErr.Code, code), ptr.Deref(sdkErr.Message, message)
+	}
+	requestID := maperror.GetRequestID(err)
+	if requestID == "" {
+		requestID = errorRequestID
+	}
+
+	w.Header().Set("Content-Type", responseContentType)
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"RequestId": requestID,
+		"Code":      code,
+		"Message":   message,
+	})
+}
@@ -0,0 +1,193 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package fake
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
+	"github.com/alibabacloud-go/tea/tea"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis"
+	maperror "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/errors"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
+)
+
+var _ = Describe("Fake ECS server", func() {
+	var (
+		fakeECS    *ECS
+		server     *Server
+		httpServer *httptest.Server
+		pluginSPI  *spi.PluginSPIImpl
+
+		region = "cn-shanghai"
+
+		newSecret = func(accessKeyID, accessKeySecret string) *corev1.Secret {
+			return &corev1.Secret{Data: map[string][]byte{
+				spi.AlicloudAccessKeyID:     []byte(accessKeyID),
+				spi.AlicloudAccessKeySecret: []byte(accessKeySecret),
+			}}
+		}
+		newClient = func(accessKeyID, accessKeySecret string) spi.ECSClient {
+			client, err := pluginSPI.NewECSClient(newSecret(accessKeyID, accessKeySecret), region)
+			Expect(err).NotTo(HaveOccurred())
+			return client
+		}
+		expectSDKError = func(err error, statusCode int, code string) {
+			var sdkErr *tea.SDKError
+			ExpectWithOffset(1, errors.As(err, &sdkErr)).To(BeTrue(), "expected a *tea.SDKError but got %v", err)
+			ExpectWithOffset(1, *sdkErr.StatusCode).To(Equal(statusCode))
+			ExpectWithOffset(1, *sdkErr.Code).To(Equal(code))
+		}
+	)
+
+	BeforeEach(func() {
+		fakeECS = NewECS()
+		server = NewServer(fakeECS)
+		server.AccessKeys = map[string]string{"access-key-id": "access-key-secret"}
+		httpServer = httptest.NewServer(server)
+		DeferCleanup(httpServer.Close)
+
+		serverURL, err := url.Parse(httpServer.URL)
+		Expect(err).NotTo(HaveOccurred())
+		pluginSPI = &spi.PluginSPIImpl{ClientOptions: spi.ClientOptions{Endpoint: serverURL.Host, VPCEndpoint: serverURL.Host, Protocol: "http"}}
+	})
+
+	It("should serve the requests of the ECS SDK client", func() {
+		client := newClient("access-key-id", "access-key-secret")
+		request, err := pluginSPI.NewRunInstancesRequest(&api.ProviderSpec{
+			ImageID:         "m-image",
+			InstanceType:    "ecs.g6.large",
+			Region:          region,
+			ZoneID:          "cn-shanghai-e",
+			SecurityGroupID: "sg-1",
+			VSwitchID:       "vsw-1",
+			SpotStrategy:    "NoSpot",
+			Tags:            map[string]string{"kubernetes.io/cluster/shoot--mcm": "1", "kubernetes.io/role/worker/shoot--mcm": "1", "name": "with spaces & symbols*~"},
+			SystemDisk:      &api.AlicloudSystemDisk{Category: "cloud_essd", Size: 50},
+			DataDisks: []api.AlicloudDataDisk{
+				{Name: "kubelet", Category: "cloud_essd", Size: 100, DeleteWithInstance: ptr.To(true)},
+				{Name: "data", Category: "cloud_efficiency", Size: 200, Encrypted: true, DeleteWithInstance: ptr.To(false)},
+			},
+		}, "machine-0", "client-token", []byte("user-data"))
+		Expect(err).NotTo(HaveOccurred())
+
+		runResponse, err := client.RunInstances(request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*runResponse.StatusCode).To(Equal(int32(http.StatusOK)))
+		Expect(runResponse.Body.InstanceIdSets.InstanceIdSet).To(HaveLen(1))
+		instanceID := *runResponse.Body.InstanceIdSets.InstanceIdSet[0]
+
+		instances := fakeECS.Instances()
+		Expect(instances).To(HaveLen(1))
+		Expect(*instances[0].InstanceName).To(Equal("machine-0"))
+		Expect(instances[0].Tags.Tag).To(ConsistOf(
+			&ecs.DescribeInstancesResponseBodyInstancesInstanceTagsTag{TagKey: tea.String("kubernetes.io/cluster/shoot--mcm"), TagValue: tea.String("1")},
+			&ecs.DescribeInstancesResponseBodyInstancesInstanceTagsTag{TagKey: tea.String("kubernetes.io/role/worker/shoot--mcm"), TagValue: tea.String("1")},
+			&ecs.DescribeInstancesResponseBodyInstancesInstanceTagsTag{TagKey: tea.String("name"), TagValue: tea.String("with spaces & symbols*~")},
+		))
+
+		describeDisksResponse, err := client.DescribeDisks(&ecs.DescribeDisksRequest{RegionId: tea.String(region), InstanceId: tea.String(instanceID)})
+		Expect(err).NotTo(HaveOccurred())
+		disks := map[string]*ecs.DescribeDisksResponseBodyDisksDisk{}
+		for _, disk := range describeDisksResponse.Body.Disks.Disk {
+			disks[*disk.Type] = disk
+		}
+		Expect(describeDisksResponse.Body.Disks.Disk).To(HaveLen(3))
+		Expect(*disks["system"].Category).To(Equal("cloud_essd"))
+		Expect(*disks["system"].Size).To(Equal(int32(50)))
+		dataDisks := map[string]*ecs.DescribeDisksResponseBodyDisksDisk{}
+		for _, disk := range fakeECS.Disks() {
+			if *disk.Type == "data" {
+				dataDisks[*disk.DiskName] = disk
+			}
+		}
+		Expect(dataDisks).To(HaveLen(2))
+		Expect(*dataDisks[spi.DataDiskName("machine-0", "kubelet")].Size).To(Equal(int32(100)))
+		Expect(*dataDisks[spi.DataDiskName("machine-0", "kubelet")].DeleteWithInstance).To(BeTrue())
+		Expect(*dataDisks[spi.DataDiskName("machine-0", "data")].Category).To(Equal("cloud_efficiency"))
+		Expect(*dataDisks[spi.DataDiskName("machine-0", "data")].Encrypted).To(BeTrue())
+		Expect(*dataDisks[spi.DataDiskName("machine-0", "data")].DeleteWithInstance).To(BeFalse())
+
+		describeRequest, err := pluginSPI.NewDescribeInstancesRequest("", instanceID, region, nil)
+		Expect(err).NotTo(HaveOccurred())
+		describeResponse, err := client.DescribeInstances(describeRequest)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(describeResponse.Body.Instances.Instance).To(HaveLen(1))
+		Expect(*describeResponse.Body.Instances.Instance[0].Status).To(Equal(InstanceStatusRunning))
+
+		describeNetworkInterfacesResponse, err := client.DescribeNetworkInterfaces(&ecs.DescribeNetworkInterfacesRequest{RegionId: tea.String(region), InstanceId: tea.String(instanceID)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(describeNetworkInterfacesResponse.Body.NetworkInterfaceSets.NetworkInterfaceSet).To(HaveLen(1))
+
+		deleteRequest, err := pluginSPI.NewDeleteInstanceRequest(instanceID, true)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.DeleteInstance(deleteRequest)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeECS.Instances()).To(BeEmpty())
+
+		remainingDisks := fakeECS.Disks()
+		Expect(remainingDisks).To(HaveLen(1))
+		deleteDiskRequest, err := pluginSPI.NewDeleteDiskRequest(*remainingDisks[0].DiskId)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.DeleteDisk(deleteDiskRequest)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeECS.Disks()).To(BeEmpty())
+	})
+
+	It("should serve the DescribeVSwitches requests of the VPC client", func() {
+		client, err := pluginSPI.NewVPCClient(newSecret("access-key-id", "access-key-secret"), region)
+		Expect(err).NotTo(HaveOccurred())
+		fakeECS.AddVSwitch("vsw-1", "cn-shanghai-e", "10.250.0.0/16", "2408:4005:39c:8200::/64")
+		fakeECS.AddVSwitch("vsw-2", "cn-shanghai-e", "10.251.0.0/16", "")
+
+		request, err := pluginSPI.NewDescribeVSwitchesRequest("vsw-1", region)
+		Expect(err).NotTo(HaveOccurred())
+		response, err := client.DescribeVSwitches(request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*response.StatusCode).To(Equal(int32(http.StatusOK)))
+		Expect(response.Body.RequestId).NotTo(BeNil())
+		Expect(response.Body.VSwitches.VSwitch).To(HaveLen(1))
+		Expect(*response.Body.VSwitches.VSwitch[0].VSwitchId).To(Equal("vsw-1"))
+		Expect(*response.Body.VSwitches.VSwitch[0].CidrBlock).To(Equal("10.250.0.0/16"))
+		Expect(*response.Body.VSwitches.VSwitch[0].Ipv6CidrBlock).To(Equal("2408:4005:39c:8200::/64"))
+
+		fakeECS.FailNext("DescribeVSwitches", NewError(http.StatusForbidden, maperror.Throttling, "Request was denied due to request throttling."))
+		_, err = client.DescribeVSwitches(request)
+		expectSDKError(err, http.StatusForbidden, maperror.Throttling)
+	})
+
+	It("should return the errors of the fake as ECS error responses", func() {
+		client := newClient("access-key-id", "access-key-secret")
+		fakeECS.FailNext("RunInstances", NewError(http.StatusForbidden, maperror.OperationDeniedNoStock, "The requested resource is sold out in the specified zone."))
+
+		_, err := client.RunInstances(&ecs.RunInstancesRequest{RegionId: tea.String(region)})
+		expectSDKError(err, http.StatusForbidden, maperror.OperationDeniedNoStock)
+		Expect(maperror.HasErrorCode(err, maperror.OperationDeniedNoStock)).To(BeTrue())
+		Expect(maperror.GetRequestID(err)).To(Equal(errorRequestID))
+
+		_, err = client.DeleteInstance(&ecs.DeleteInstanceRequest{InstanceId: tea.String("i-unknown")})
+		expectSDKError(err, http.StatusNotFound, maperror.InvalidInstanceIDNotFound)
+
+		_, err = client.DeleteNetworkInterface(&ecs.DeleteNetworkInterfaceRequest{RegionId: tea.String(region), NetworkInterfaceId: tea.String("eni-unknown")})
+		expectSDKError(err, http.StatusNotFound, invalidEniIDNotFound)
+	})
+
+	It("should reject requests with unknown or wrong credentials", func() {
+		_, err := newClient("unknown-access-key-id", "access-key-secret").DescribeInstances(&ecs.DescribeInstancesRequest{RegionId: tea.String(region)})
+		expectSDKError(err, http.StatusNotFound, invalidAccessKeyIDNotFound)
+
+		_, err = newClient("access-key-id", "wrong-access-key-secret").DescribeInstances(&ecs.DescribeInstancesRequest{RegionId: tea.String(region)})
+		expectSDKError(err, http.StatusBadRequest, signatureDoesNotMatch)
+		Expect(fakeECS.Calls("DescribeInstances")).To(BeZero())
+	})
+})
@@ -2,6 +2,6 @@
 //
 // SPDX-License-Identifier: Apache-2.0
 
-//go:generate mockgen -package=client -destination=mocks.go github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi ECSClient
+//go:generate mockgen -package=client -destination=mocks.go github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi ECSClient,VPCClient
 
 package client
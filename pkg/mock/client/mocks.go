@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi (interfaces: ECSClient)
+// Source: github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi (interfaces: ECSClient,VPCClient)
 
 // Package client is a generated GoMock package.
 package client
@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	client "github.com/alibabacloud-go/ecs-20140526/v7/client"
+	vpc "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/vpc"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -34,6 +35,21 @@ func (m *MockECSClient) EXPECT() *MockECSClientMockRecorder {
 	return m.recorder
 }
 
+// AssignIpv6Addresses mocks base method.
+func (m *MockECSClient) AssignIpv6Addresses(arg0 *client.AssignIpv6AddressesRequest) (*client.AssignIpv6AddressesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignIpv6Addresses", arg0)
+	ret0, _ := ret[0].(*client.AssignIpv6AddressesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssignIpv6Addresses indicates an expected call of AssignIpv6Addresses.
+func (mr *MockECSClientMockRecorder) AssignIpv6Addresses(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignIpv6Addresses", reflect.TypeOf((*MockECSClient)(nil).AssignIpv6Addresses), arg0)
+}
+
 // DeleteDisk mocks base method.
 func (m *MockECSClient) DeleteDisk(arg0 *client.DeleteDiskRequest) (*client.DeleteDiskResponse, error) {
 	m.ctrl.T.Helper()
@@ -138,3 +154,56 @@ func (mr *MockECSClientMockRecorder) RunInstances(arg0 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInstances", reflect.TypeOf((*MockECSClient)(nil).RunInstances), arg0)
 }
+
+// TagResources mocks base method.
+func (m *MockECSClient) TagResources(arg0 *client.TagResourcesRequest) (*client.TagResourcesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagResources", arg0)
+	ret0, _ := ret[0].(*client.TagResourcesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TagResources indicates an expected call of TagResources.
+func (mr *MockECSClientMockRecorder) TagResources(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagResources", reflect.TypeOf((*MockECSClient)(nil).TagResources), arg0)
+}
+
+// MockVPCClient is a mock of VPCClient interface.
+type MockVPCClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockVPCClientMockRecorder
+}
+
+// MockVPCClientMockRecorder is the mock recorder for MockVPCClient.
+type MockVPCClientMockRecorder struct {
+	mock *MockVPCClient
+}
+
+// NewMockVPCClient creates a new mock instance.
+func NewMockVPCClient(ctrl *gomock.Controller) *MockVPCClient {
+	mock := &MockVPCClient{ctrl: ctrl}
+	mock.recorder = &MockVPCClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVPCClient) EXPECT() *MockVPCClientMockRecorder {
+	return m.recorder
+}
+
+// DescribeVSwitches mocks base method.
+func (m *MockVPCClient) DescribeVSwitches(arg0 *vpc.DescribeVSwitchesRequest) (*vpc.DescribeVSwitchesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeVSwitches", arg0)
+	ret0, _ := ret[0].(*vpc.DescribeVSwitchesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeVSwitches indicates an expected call of DescribeVSwitches.
+func (mr *MockVPCClientMockRecorder) DescribeVSwitches(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeVSwitches", reflect.TypeOf((*MockVPCClient)(nil).DescribeVSwitches), arg0)
+}
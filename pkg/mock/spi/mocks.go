@@ -10,6 +10,7 @@ import (
 	client "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	api "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis"
 	spi "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
+	vpc "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/vpc"
 	gomock "github.com/golang/mock/gomock"
 	v1 "k8s.io/api/core/v1"
 )
@@ -37,6 +38,36 @@ func (m *MockPluginSPI) EXPECT() *MockPluginSPIMockRecorder {
 	return m.recorder
 }
 
+// NewAssignIpv6AddressesRequest mocks base method.
+func (m *MockPluginSPI) NewAssignIpv6AddressesRequest(arg0, arg1 string, arg2 *int, arg3 []string) (*client.AssignIpv6AddressesRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewAssignIpv6AddressesRequest", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*client.AssignIpv6AddressesRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewAssignIpv6AddressesRequest indicates an expected call of NewAssignIpv6AddressesRequest.
+func (mr *MockPluginSPIMockRecorder) NewAssignIpv6AddressesRequest(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewAssignIpv6AddressesRequest", reflect.TypeOf((*MockPluginSPI)(nil).NewAssignIpv6AddressesRequest), arg0, arg1, arg2, arg3)
+}
+
+// NewDeleteDiskRequest mocks base method.
+func (m *MockPluginSPI) NewDeleteDiskRequest(arg0 string) (*client.DeleteDiskRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewDeleteDiskRequest", arg0)
+	ret0, _ := ret[0].(*client.DeleteDiskRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewDeleteDiskRequest indicates an expected call of NewDeleteDiskRequest.
+func (mr *MockPluginSPIMockRecorder) NewDeleteDiskRequest(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewDeleteDiskRequest", reflect.TypeOf((*MockPluginSPI)(nil).NewDeleteDiskRequest), arg0)
+}
+
 // NewDeleteInstanceRequest mocks base method.
 func (m *MockPluginSPI) NewDeleteInstanceRequest(arg0 string, arg1 bool) (*client.DeleteInstanceRequest, error) {
 	m.ctrl.T.Helper()
@@ -52,6 +83,36 @@ func (mr *MockPluginSPIMockRecorder) NewDeleteInstanceRequest(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewDeleteInstanceRequest", reflect.TypeOf((*MockPluginSPI)(nil).NewDeleteInstanceRequest), arg0, arg1)
 }
 
+// NewDeleteNetworkInterfaceRequest mocks base method.
+func (m *MockPluginSPI) NewDeleteNetworkInterfaceRequest(arg0, arg1 string) (*client.DeleteNetworkInterfaceRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewDeleteNetworkInterfaceRequest", arg0, arg1)
+	ret0, _ := ret[0].(*client.DeleteNetworkInterfaceRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewDeleteNetworkInterfaceRequest indicates an expected call of NewDeleteNetworkInterfaceRequest.
+func (mr *MockPluginSPIMockRecorder) NewDeleteNetworkInterfaceRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewDeleteNetworkInterfaceRequest", reflect.TypeOf((*MockPluginSPI)(nil).NewDeleteNetworkInterfaceRequest), arg0, arg1)
+}
+
+// NewDescribeDisksRequest mocks base method.
+func (m *MockPluginSPI) NewDescribeDisksRequest(arg0, arg1 string, arg2 map[string]string) (*client.DescribeDisksRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewDescribeDisksRequest", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*client.DescribeDisksRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewDescribeDisksRequest indicates an expected call of NewDescribeDisksRequest.
+func (mr *MockPluginSPIMockRecorder) NewDescribeDisksRequest(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewDescribeDisksRequest", reflect.TypeOf((*MockPluginSPI)(nil).NewDescribeDisksRequest), arg0, arg1, arg2)
+}
+
 // NewDescribeInstancesRequest mocks base method.
 func (m *MockPluginSPI) NewDescribeInstancesRequest(arg0, arg1, arg2 string, arg3 map[string]string) (*client.DescribeInstancesRequest, error) {
 	m.ctrl.T.Helper()
@@ -67,6 +128,51 @@ func (mr *MockPluginSPIMockRecorder) NewDescribeInstancesRequest(arg0, arg1, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewDescribeInstancesRequest", reflect.TypeOf((*MockPluginSPI)(nil).NewDescribeInstancesRequest), arg0, arg1, arg2, arg3)
 }
 
+// NewDescribeNetworkInterfacesByNameRequest mocks base method.
+func (m *MockPluginSPI) NewDescribeNetworkInterfacesByNameRequest(arg0, arg1 string, arg2 map[string]string) (*client.DescribeNetworkInterfacesRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewDescribeNetworkInterfacesByNameRequest", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*client.DescribeNetworkInterfacesRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewDescribeNetworkInterfacesByNameRequest indicates an expected call of NewDescribeNetworkInterfacesByNameRequest.
+func (mr *MockPluginSPIMockRecorder) NewDescribeNetworkInterfacesByNameRequest(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewDescribeNetworkInterfacesByNameRequest", reflect.TypeOf((*MockPluginSPI)(nil).NewDescribeNetworkInterfacesByNameRequest), arg0, arg1, arg2)
+}
+
+// NewDescribeNetworkInterfacesRequest mocks base method.
+func (m *MockPluginSPI) NewDescribeNetworkInterfacesRequest(arg0 []string, arg1 string) (*client.DescribeNetworkInterfacesRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewDescribeNetworkInterfacesRequest", arg0, arg1)
+	ret0, _ := ret[0].(*client.DescribeNetworkInterfacesRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewDescribeNetworkInterfacesRequest indicates an expected call of NewDescribeNetworkInterfacesRequest.
+func (mr *MockPluginSPIMockRecorder) NewDescribeNetworkInterfacesRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewDescribeNetworkInterfacesRequest", reflect.TypeOf((*MockPluginSPI)(nil).NewDescribeNetworkInterfacesRequest), arg0, arg1)
+}
+
+// NewDescribeVSwitchesRequest mocks base method.
+func (m *MockPluginSPI) NewDescribeVSwitchesRequest(arg0, arg1 string) (*vpc.DescribeVSwitchesRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewDescribeVSwitchesRequest", arg0, arg1)
+	ret0, _ := ret[0].(*vpc.DescribeVSwitchesRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewDescribeVSwitchesRequest indicates an expected call of NewDescribeVSwitchesRequest.
+func (mr *MockPluginSPIMockRecorder) NewDescribeVSwitchesRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewDescribeVSwitchesRequest", reflect.TypeOf((*MockPluginSPI)(nil).NewDescribeVSwitchesRequest), arg0, arg1)
+}
+
 // NewECSClient mocks base method.
 func (m *MockPluginSPI) NewECSClient(arg0 *v1.Secret, arg1 string) (spi.ECSClient, error) {
 	m.ctrl.T.Helper()
@@ -96,6 +202,20 @@ func (mr *MockPluginSPIMockRecorder) NewInstanceDataDisks(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewInstanceDataDisks", reflect.TypeOf((*MockPluginSPI)(nil).NewInstanceDataDisks), arg0, arg1)
 }
 
+// NewInstanceNetworkInterfaces mocks base method.
+func (m *MockPluginSPI) NewInstanceNetworkInterfaces(arg0 []api.AlicloudNetworkInterface, arg1 string) []*client.RunInstancesRequestNetworkInterface {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewInstanceNetworkInterfaces", arg0, arg1)
+	ret0, _ := ret[0].([]*client.RunInstancesRequestNetworkInterface)
+	return ret0
+}
+
+// NewInstanceNetworkInterfaces indicates an expected call of NewInstanceNetworkInterfaces.
+func (mr *MockPluginSPIMockRecorder) NewInstanceNetworkInterfaces(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewInstanceNetworkInterfaces", reflect.TypeOf((*MockPluginSPI)(nil).NewInstanceNetworkInterfaces), arg0, arg1)
+}
+
 // NewRunInstanceTags mocks base method.
 func (m *MockPluginSPI) NewRunInstanceTags(arg0 map[string]string) ([]*client.RunInstancesRequestTag, error) {
 	m.ctrl.T.Helper()
@@ -112,16 +232,61 @@ func (mr *MockPluginSPIMockRecorder) NewRunInstanceTags(arg0 interface{}) *gomoc
 }
 
 // NewRunInstancesRequest mocks base method.
-func (m *MockPluginSPI) NewRunInstancesRequest(arg0 *api.ProviderSpec, arg1 string, arg2 []byte) (*client.RunInstancesRequest, error) {
+func (m *MockPluginSPI) NewRunInstancesRequest(arg0 *api.ProviderSpec, arg1, arg2 string, arg3 []byte) (*client.RunInstancesRequest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NewRunInstancesRequest", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "NewRunInstancesRequest", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(*client.RunInstancesRequest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // NewRunInstancesRequest indicates an expected call of NewRunInstancesRequest.
-func (mr *MockPluginSPIMockRecorder) NewRunInstancesRequest(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockPluginSPIMockRecorder) NewRunInstancesRequest(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewRunInstancesRequest", reflect.TypeOf((*MockPluginSPI)(nil).NewRunInstancesRequest), arg0, arg1, arg2, arg3)
+}
+
+// NewTagNetworkInterfacesRequest mocks base method.
+func (m *MockPluginSPI) NewTagNetworkInterfacesRequest(arg0 []string, arg1 string, arg2 map[string]string) (*client.TagResourcesRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewTagNetworkInterfacesRequest", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*client.TagResourcesRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewTagNetworkInterfacesRequest indicates an expected call of NewTagNetworkInterfacesRequest.
+func (mr *MockPluginSPIMockRecorder) NewTagNetworkInterfacesRequest(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewTagNetworkInterfacesRequest", reflect.TypeOf((*MockPluginSPI)(nil).NewTagNetworkInterfacesRequest), arg0, arg1, arg2)
+}
+
+// NewTagResourcesRequest mocks base method.
+func (m *MockPluginSPI) NewTagResourcesRequest(arg0, arg1 string, arg2 map[string]string) (*client.TagResourcesRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewTagResourcesRequest", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*client.TagResourcesRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewTagResourcesRequest indicates an expected call of NewTagResourcesRequest.
+func (mr *MockPluginSPIMockRecorder) NewTagResourcesRequest(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewTagResourcesRequest", reflect.TypeOf((*MockPluginSPI)(nil).NewTagResourcesRequest), arg0, arg1, arg2)
+}
+
+// NewVPCClient mocks base method.
+func (m *MockPluginSPI) NewVPCClient(arg0 *v1.Secret, arg1 string) (spi.VPCClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewVPCClient", arg0, arg1)
+	ret0, _ := ret[0].(spi.VPCClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewVPCClient indicates an expected call of NewVPCClient.
+func (mr *MockPluginSPIMockRecorder) NewVPCClient(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewRunInstancesRequest", reflect.TypeOf((*MockPluginSPI)(nil).NewRunInstancesRequest), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewVPCClient", reflect.TypeOf((*MockPluginSPI)(nil).NewVPCClient), arg0, arg1)
 }
@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package replay records the calls of the driver to the ECS API in cassettes and replays them in tests. This allows to
+// capture realistic requests and responses once with an Alibaba Cloud account and to test the request construction
+// and the handling of the responses against them deterministically without one.
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	api "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis"
+)
+
+// Redacted replaces the values of the scrubbed string fields of requests and responses
+const Redacted = "REDACTED"
+
+var (
+	// redactedFields are the fields of requests and responses whose values are replaced, as they contain credentials or
+	// identify the Alibaba Cloud account
+	redactedFields = map[string]bool{
+		"UserData":             true,
+		"Password":             true,
+		"OwnerId":              true,
+		"OwnerAccount":         true,
+		"ResourceOwnerId":      true,
+		"ResourceOwnerAccount": true,
+		"AccessKeyId":          true,
+		"AccessKeySecret":      true,
+		"SecurityToken":        true,
+	}
+	// droppedFields are the fields of responses which are not recorded, as they are specific to a single call
+	droppedFields = map[string]bool{
+		"headers": true,
+	}
+)
+
+// Cassette is a recording of ECS calls
+type Cassette struct {
+	// ProviderSpec is the provider spec of the machine class the calls were recorded with
+	ProviderSpec *api.ProviderSpec `json:"providerSpec,omitempty"`
+	// Interactions are the recorded calls in the order they were made
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded ECS call
+type Interaction struct {
+	// Action is the ECS action, e.g. RunInstances
+	Action string `json:"action"`
+	// Request is the scrubbed request
+	Request json.RawMessage `json:"request"`
+	// Response is the scrubbed response of a successful call
+	Response json.RawMessage `json:"response,omitempty"`
+	// Error is the error of a failed call
+	Error *Error `json:"error,omitempty"`
+}
+
+// Error is the error response of a failed ECS call
+type Error struct {
+	StatusCode int    `json:"statusCode"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	RequestID  string `json:"requestId,omitempty"`
+}
+
+// Load reads the cassette from the given file
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- cassettes are read by tests only
+	if err != nil {
+		return nil, err
+	}
+	cassette := &Cassette{}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("failed to decode cassette %q: %w", path, err)
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette to the given file, creating its directory if it does not exist
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// Scrub returns the JSON encoding of the given request or response with the values of fields containing credentials
+// replaced and the fields specific to a single call removed. The keys of the returned objects are sorted, so that
+// scrubbed requests can be compared byte by byte.
+func Scrub(v any) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return json.Marshal(scrub(decoded))
+}
+
+func scrub(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			switch {
+			case droppedFields[key]:
+				delete(v, key)
+			case redactedFields[key]:
+				if _, ok := value.(string); ok {
+					v[key] = Redacted
+				} else {
+					v[key] = json.Number("0")
+				}
+			default:
+				v[key] = scrub(value)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = scrub(v[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package replay
+
+import (
+	"errors"
+	"sync"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
+	"github.com/alibabacloud-go/tea/tea"
+	corev1 "k8s.io/api/core/v1"
+
+	maperror "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/errors"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/vpc"
+)
+
+// Recorder is a spi.PluginSPI recording all calls of the ECS and VPC clients of the wrapped PluginSPI in a cassette. Requests
+// and responses are scrubbed before they are recorded.
+type Recorder struct {
+	spi.PluginSPI
+
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+var _ spi.PluginSPI = &Recorder{}
+
+// NewRecorder returns a Recorder adding the calls of the ECS and VPC clients of the given PluginSPI to the given
+// cassette
+func NewRecorder(pluginSPI spi.PluginSPI, cassette *Cassette) *Recorder {
+	return &Recorder{
+		PluginSPI: pluginSPI,
+		cassette:  cassette,
+	}
+}
+
+// NewECSClient returns the ECS client of the wrapped PluginSPI, whose calls are recorded
+func (r *Recorder) NewECSClient(secret *corev1.Secret, region string) (spi.ECSClient, error) {
+	client, err := r.PluginSPI.NewECSClient(secret, region)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingECSClient{client: client, recorder: r}, nil
+}
+
+// NewVPCClient returns the VPC client of the wrapped PluginSPI, whose calls are recorded
+func (r *Recorder) NewVPCClient(secret *corev1.Secret, region string) (spi.VPCClient, error) {
+	client, err := r.PluginSPI.NewVPCClient(secret, region)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingVPCClient{client: client, recorder: r}, nil
+}
+
+// record adds the given call to the cassette
+func (r *Recorder) record(action string, request, response any, err error) error {
+	interaction := &Interaction{Action: action}
+	var scrubErr error
+	if interaction.Request, scrubErr = Scrub(request); scrubErr != nil {
+		return scrubErr
+	}
+	if err != nil {
+		interaction.Error = newError(err)
+	} else if interaction.Response, scrubErr = Scrub(response); scrubErr != nil {
+		return scrubErr
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	return nil
+}
+
+// newError returns the recorded error of the given error returned by the ECS SDK
+func newError(err error) *Error {
+	recorded := &Error{Message: err.Error(), RequestID: maperror.GetRequestID(err)}
+	var sdkErr *tea.SDKError
+	if errors.As(err, &sdkErr) {
+		recorded.StatusCode = tea.IntValue(sdkErr.StatusCode)
+		recorded.Code = tea.StringValue(sdkErr.Code)
+		recorded.Message = tea.StringValue(sdkErr.Message)
+	}
+	return recorded
+}
+
+// recordingECSClient is an ECSClient recording its calls
+type recordingECSClient struct {
+	client   spi.ECSClient
+	recorder *Recorder
+}
+
+// record calls the given function and records the call unless its request or response cannot be encoded
+func record[Request, Response any](r *Recorder, action string, request *Request, call func(*Request) (*Response, error)) (*Response, error) {
+	response, err := call(request)
+	if recordErr := r.record(action, request, response, err); recordErr != nil && err == nil {
+		return response, recordErr
+	}
+	return response, err
+}
+
+func (c *recordingECSClient) RunInstances(request *ecs.RunInstancesRequest) (*ecs.RunInstancesResponse, error) {
+	return record(c.recorder, "RunInstances", request, c.client.RunInstances)
+}
+
+func (c *recordingECSClient) DescribeInstances(request *ecs.DescribeInstancesRequest) (*ecs.DescribeInstancesResponse, error) {
+	return record(c.recorder, "DescribeInstances", request, c.client.DescribeInstances)
+}
+
+func (c *recordingECSClient) DeleteInstance(request *ecs.DeleteInstanceRequest) (*ecs.DeleteInstanceResponse, error) {
+	return record(c.recorder, "DeleteInstance", request, c.client.DeleteInstance)
+}
+
+func (c *recordingECSClient) DescribeDisks(request *ecs.DescribeDisksRequest) (*ecs.DescribeDisksResponse, error) {
+	return record(c.recorder, "DescribeDisks", request, c.client.DescribeDisks)
+}
+
+func (c *recordingECSClient) DeleteDisk(request *ecs.DeleteDiskRequest) (*ecs.DeleteDiskResponse, error) {
+	return record(c.recorder, "DeleteDisk", request, c.client.DeleteDisk)
+}
+
+func (c *recordingECSClient) DescribeNetworkInterfaces(request *ecs.DescribeNetworkInterfacesRequest) (*ecs.DescribeNetworkInterfacesResponse, error) {
+	return record(c.recorder, "DescribeNetworkInterfaces", request, c.client.DescribeNetworkInterfaces)
+}
+
+func (c *recordingECSClient) DeleteNetworkInterface(request *ecs.DeleteNetworkInterfaceRequest) (*ecs.DeleteNetworkInterfaceResponse, error) {
+	return record(c.recorder, "DeleteNetworkInterface", request, c.client.DeleteNetworkInterface)
+}
+
+func (c *recordingECSClient) TagResources(request *ecs.TagResourcesRequest) (*ecs.TagResourcesResponse, error) {
+	return record(c.recorder, "TagResources", request, c.client.TagResources)
+}
+
+func (c *recordingECSClient) AssignIpv6Addresses(request *ecs.AssignIpv6AddressesRequest) (*ecs.AssignIpv6AddressesResponse, error) {
+	return record(c.recorder, "AssignIpv6Addresses", request, c.client.AssignIpv6Addresses)
+}
+
+// recordingVPCClient is a VPCClient recording its calls
+type recordingVPCClient struct {
+	client   spi.VPCClient
+	recorder *Recorder
+}
+
+func (c *recordingVPCClient) DescribeVSwitches(request *vpc.DescribeVSwitchesRequest) (*vpc.DescribeVSwitchesResponse, error) {
+	return record(c.recorder, "DescribeVSwitches", request, c.client.DescribeVSwitches)
+}
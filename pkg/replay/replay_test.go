@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package replay
+
+import (
+	"net/http"
+	"path/filepath"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
+	"github.com/alibabacloud-go/tea/tea"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	maperror "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/errors"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/fake"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
+)
+
+var _ = Describe("Replay", func() {
+	var (
+		region = "cn-shanghai"
+		secret = &corev1.Secret{}
+
+		runInstancesRequest = func() *ecs.RunInstancesRequest {
+			return &ecs.RunInstancesRequest{
+				RegionId:     tea.String(region),
+				ImageId:      tea.String("m-image"),
+				InstanceType: tea.String("ecs.g6.large"),
+				VSwitchId:    tea.String("vsw-1"),
+				InstanceName: tea.String("machine-0"),
+				UserData:     tea.String("c2VjcmV0LWJvb3RzdHJhcC10b2tlbg=="),
+				OwnerId:      tea.Int64(1234567890),
+				Tag:          []*ecs.RunInstancesRequestTag{{Key: tea.String("cluster"), Value: tea.String("shoot")}},
+			}
+		}
+
+		record = func(calls func(client spi.ECSClient)) *Cassette {
+			cassette := &Cassette{}
+			client, err := NewRecorder(fake.NewECS(), cassette).NewECSClient(secret, region)
+			Expect(err).NotTo(HaveOccurred())
+			calls(client)
+			return cassette
+		}
+		newReplayingClient = func(cassette *Cassette) (*Replayer, spi.ECSClient) {
+			replayer := NewReplayer(cassette)
+			client, err := replayer.NewECSClient(secret, region)
+			Expect(err).NotTo(HaveOccurred())
+			return replayer, client
+		}
+	)
+
+	It("should record scrubbed calls and replay them after the cassette has been saved", func() {
+		var instanceID string
+		cassette := record(func(client spi.ECSClient) {
+			response, err := client.RunInstances(runInstancesRequest())
+			Expect(err).NotTo(HaveOccurred())
+			instanceID = *response.Body.InstanceIdSets.InstanceIdSet[0]
+			_, err = client.DescribeInstances(&ecs.DescribeInstancesRequest{RegionId: tea.String(region)})
+			Expect(err).NotTo(HaveOccurred())
+		})
+		Expect(cassette.Interactions).To(HaveLen(2))
+		Expect(string(cassette.Interactions[0].Request)).To(ContainSubstring(`"UserData":"REDACTED"`))
+		Expect(string(cassette.Interactions[0].Request)).To(ContainSubstring(`"OwnerId":0`))
+		Expect(string(cassette.Interactions[0].Request)).NotTo(ContainSubstring("c2VjcmV0LWJvb3RzdHJhcC10b2tlbg=="))
+		Expect(string(cassette.Interactions[0].Response)).NotTo(ContainSubstring("headers"))
+
+		path := filepath.Join(GinkgoT().TempDir(), "cassette.json")
+		Expect(cassette.Save(path)).To(Succeed())
+		cassette, err := Load(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		replayer, client := newReplayingClient(cassette)
+		runResponse, err := client.RunInstances(runInstancesRequest())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*runResponse.Body.InstanceIdSets.InstanceIdSet[0]).To(Equal(instanceID))
+		describeResponse, err := client.DescribeInstances(&ecs.DescribeInstancesRequest{RegionId: tea.String(region)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(describeResponse.Body.Instances.Instance).To(HaveLen(1))
+		Expect(*describeResponse.Body.Instances.Instance[0].InstanceId).To(Equal(instanceID))
+		Expect(replayer.Verify()).To(Succeed())
+	})
+
+	It("should replay the recorded errors", func() {
+		cassette := record(func(client spi.ECSClient) {
+			_, err := client.DeleteInstance(&ecs.DeleteInstanceRequest{InstanceId: tea.String("i-unknown")})
+			Expect(err).To(HaveOccurred())
+		})
+		Expect(cassette.Interactions[0].Error).To(Equal(&Error{
+			StatusCode: http.StatusNotFound,
+			Code:       maperror.InvalidInstanceIDNotFound,
+			Message:    "The specified InstanceId does not exist.",
+			RequestID:  "fake-error-request-id",
+		}))
+
+		replayer, client := newReplayingClient(cassette)
+		_, err := client.DeleteInstance(&ecs.DeleteInstanceRequest{InstanceId: tea.String("i-unknown")})
+		Expect(maperror.HasErrorCode(err, maperror.InvalidInstanceIDNotFound)).To(BeTrue())
+		Expect(maperror.GetRequestID(err)).To(Equal("fake-error-request-id"))
+		Expect(replayer.Verify()).To(Succeed())
+	})
+
+	It("should fail calls which do not match the recorded ones", func() {
+		cassette := record(func(client spi.ECSClient) {
+			_, err := client.RunInstances(runInstancesRequest())
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		replayer, client := newReplayingClient(cassette)
+		request := runInstancesRequest()
+		request.UserData = tea.String("b3RoZXItdG9rZW4=")
+		request.InstanceType = tea.String("ecs.g6.xlarge")
+		_, err := client.RunInstances(request)
+		Expect(err).To(MatchError(ContainSubstring("request of call 1 (RunInstances) does not match the recorded one")))
+		Expect(replayer.Verify()).To(MatchError(err))
+
+		replayer, client = newReplayingClient(cassette)
+		_, err = client.DescribeInstances(&ecs.DescribeInstancesRequest{RegionId: tea.String(region)})
+		Expect(err).To(MatchError("call 1 is DescribeInstances, but RunInstances has been recorded"))
+		Expect(replayer.Verify()).To(MatchError(err))
+	})
+
+	It("should report recorded calls which have not been made", func() {
+		cassette := record(func(client spi.ECSClient) {
+			_, err := client.DescribeInstances(&ecs.DescribeInstancesRequest{RegionId: tea.String(region)})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		replayer, client := newReplayingClient(cassette)
+		Expect(replayer.Verify()).To(MatchError("1 recorded call(s) have not been made, the next one is DescribeInstances"))
+
+		_, err := client.DescribeInstances(&ecs.DescribeInstancesRequest{RegionId: tea.String(region)})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.DescribeInstances(&ecs.DescribeInstancesRequest{RegionId: tea.String(region)})
+		Expect(err).To(MatchError("unexpected call 2 (DescribeInstances), only 1 call(s) have been recorded"))
+	})
+})
@@ -0,0 +1,208 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
+	"github.com/alibabacloud-go/tea/tea"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/spi"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/vpc"
+)
+
+// Replayer is a spi.PluginSPI whose ECS and VPC clients replay the calls of a cassette in their recorded order. Every call
+// has to match the next recorded one, i.e. the action and the scrubbed request have to be equal, otherwise it fails.
+// Verify reports the first mismatch and calls which have been recorded but not made.
+//
+// The requests are built by the embedded spi.PluginSPIImpl, so that regressions in their construction are caught.
+type Replayer struct {
+	spi.PluginSPIImpl
+
+	mu       sync.Mutex
+	cassette *Cassette
+	next     int
+	err      error
+}
+
+var _ spi.ECSClient = &Replayer{}
+var _ spi.VPCClient = &Replayer{}
+var _ spi.PluginSPI = &Replayer{}
+
+// NewReplayer returns a Replayer replaying the calls of the given cassette
+func NewReplayer(cassette *Cassette) *Replayer {
+	return &Replayer{cassette: cassette}
+}
+
+// NewECSClient returns the replayer itself for every secret and region
+func (r *Replayer) NewECSClient(_ *corev1.Secret, _ string) (spi.ECSClient, error) {
+	return r, nil
+}
+
+// NewVPCClient returns the replayer itself for every secret and region
+func (r *Replayer) NewVPCClient(_ *corev1.Secret, _ string) (spi.VPCClient, error) {
+	return r, nil
+}
+
+// Verify returns an error if a call did not match the recorded one or if not all recorded calls have been made
+func (r *Replayer) Verify() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	if remaining := len(r.cassette.Interactions) - r.next; remaining > 0 {
+		return fmt.Errorf("%d recorded call(s) have not been made, the next one is %s", remaining, r.cassette.Interactions[r.next].Action)
+	}
+	return nil
+}
+
+// replay matches the given call with the next recorded one and decodes the recorded response into the given response
+func (r *Replayer) replay(action string, request, response any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.match(action, request); err != nil {
+		if r.err == nil {
+			r.err = err
+		}
+		return err
+	}
+	interaction := r.cassette.Interactions[r.next]
+	r.next++
+
+	if interaction.Error != nil {
+		return newSDKError(interaction.Error)
+	}
+	if err := json.Unmarshal(interaction.Response, response); err != nil {
+		return fmt.Errorf("failed to decode the recorded response of call %d (%s): %w", r.next, action, err)
+	}
+	return nil
+}
+
+// match returns an error if the given call does not match the next recorded one
+func (r *Replayer) match(action string, request any) error {
+	if r.next >= len(r.cassette.Interactions) {
+		return fmt.Errorf("unexpected call %d (%s), only %d call(s) have been recorded", r.next+1, action, len(r.cassette.Interactions))
+	}
+	interaction := r.cassette.Interactions[r.next]
+	if interaction.Action != action {
+		return fmt.Errorf("call %d is %s, but %s has been recorded", r.next+1, action, interaction.Action)
+	}
+
+	actual, err := Scrub(request)
+	if err != nil {
+		return err
+	}
+	expected := &bytes.Buffer{}
+	if err := json.Compact(expected, interaction.Request); err != nil {
+		return fmt.Errorf("failed to decode the recorded request of call %d (%s): %w", r.next+1, action, err)
+	}
+	if !bytes.Equal(actual, expected.Bytes()) {
+		return fmt.Errorf("request of call %d (%s) does not match the recorded one\nactual:   %s\nrecorded: %s", r.next+1, action, actual, expected)
+	}
+	return nil
+}
+
+// newSDKError returns the error the ECS SDK returns for the given recorded error response
+func newSDKError(recorded *Error) error {
+	return tea.NewSDKError(map[string]any{
+		"code":       recorded.Code,
+		"message":    recorded.Message,
+		"statusCode": recorded.StatusCode,
+		"data": map[string]any{
+			"statusCode": recorded.StatusCode,
+			"Code":       recorded.Code,
+			"Message":    recorded.Message,
+			"RequestId":  recorded.RequestID,
+		},
+	})
+}
+
+func (r *Replayer) RunInstances(request *ecs.RunInstancesRequest) (*ecs.RunInstancesResponse, error) {
+	response := &ecs.RunInstancesResponse{}
+	if err := r.replay("RunInstances", request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (r *Replayer) DescribeInstances(request *ecs.DescribeInstancesRequest) (*ecs.DescribeInstancesResponse, error) {
+	response := &ecs.DescribeInstancesResponse{}
+	if err := r.replay("DescribeInstances", request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (r *Replayer) DeleteInstance(request *ecs.DeleteInstanceRequest) (*ecs.DeleteInstanceResponse, error) {
+	response := &ecs.DeleteInstanceResponse{}
+	if err := r.replay("DeleteInstance", request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (r *Replayer) DescribeDisks(request *ecs.DescribeDisksRequest) (*ecs.DescribeDisksResponse, error) {
+	response := &ecs.DescribeDisksResponse{}
+	if err := r.replay("DescribeDisks", request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (r *Replayer) DeleteDisk(request *ecs.DeleteDiskRequest) (*ecs.DeleteDiskResponse, error) {
+	response := &ecs.DeleteDiskResponse{}
+	if err := r.replay("DeleteDisk", request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (r *Replayer) DescribeNetworkInterfaces(request *ecs.DescribeNetworkInterfacesRequest) (*ecs.DescribeNetworkInterfacesResponse, error) {
+	response := &ecs.DescribeNetworkInterfacesResponse{}
+	if err := r.replay("DescribeNetworkInterfaces", request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (r *Replayer) DeleteNetworkInterface(request *ecs.DeleteNetworkInterfaceRequest) (*ecs.DeleteNetworkInterfaceResponse, error) {
+	response := &ecs.DeleteNetworkInterfaceResponse{}
+	if err := r.replay("DeleteNetworkInterface", request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (r *Replayer) TagResources(request *ecs.TagResourcesRequest) (*ecs.TagResourcesResponse, error) {
+	response := &ecs.TagResourcesResponse{}
+	if err := r.replay("TagResources", request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (r *Replayer) AssignIpv6Addresses(request *ecs.AssignIpv6AddressesRequest) (*ecs.AssignIpv6AddressesResponse, error) {
+	response := &ecs.AssignIpv6AddressesResponse{}
+	if err := r.replay("AssignIpv6Addresses", request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (r *Replayer) DescribeVSwitches(request *vpc.DescribeVSwitchesRequest) (*vpc.DescribeVSwitchesResponse, error) {
+	response := &vpc.DescribeVSwitchesResponse{}
+	if err := r.replay("DescribeVSwitches", request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
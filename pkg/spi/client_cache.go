@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"slices"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxCachedClients is the number of ECS or VPC clients cached at most. Secrets without a name, e.g. with credentials
+// merged by MCM, are not evicted on rotation, so the least recently used client is evicted once the cache is full.
+const maxCachedClients = 64
+
+// clientCache caches ECS or VPC clients, so that their HTTP connections are reused across driver calls. Clients are
+// keyed by a hash of the credentials and the region, i.e. rotated credentials result in a new client. The client built
+// for the previous credentials of a secret is evicted once the secret changes, and the least recently used client
+// once more than maxCachedClients are cached.
+type clientCache[C any] struct {
+	mu sync.Mutex
+	// clients are the cached clients by credentials hash and region
+	clients map[string]*cachedClient[C]
+	// secretKeys are the keys of the clients last used per secret namespace, name and region
+	secretKeys map[string]string
+	// uses counts the uses of the cache, it orders the cached clients by their last use
+	uses uint64
+}
+
+// cachedClient is a cached client together with the number of the use of the cache it was last used by
+type cachedClient[C any] struct {
+	client   C
+	lastUsed uint64
+}
+
+// get returns the cached client for the credentials of the given secret and the region. If there is none, a new
+// client is built by the given function and cached.
+func (c *clientCache[C]) get(secret *corev1.Secret, region string, newClient func() (C, error)) (C, error) {
+	key := credentialsHash(secret) + "/" + region
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.clients == nil {
+		c.clients = map[string]*cachedClient[C]{}
+		c.secretKeys = map[string]string{}
+	}
+	c.uses++
+
+	if secret.Name != "" {
+		secretKey := secret.Namespace + "/" + secret.Name + "/" + region
+		if oldKey, ok := c.secretKeys[secretKey]; ok && oldKey != key {
+			// the credentials of the secret have been changed
+			c.evict(oldKey)
+		}
+		c.secretKeys[secretKey] = key
+	}
+
+	if cached, ok := c.clients[key]; ok {
+		cached.lastUsed = c.uses
+		return cached.client, nil
+	}
+
+	client, err := newClient()
+	if err != nil {
+		var noClient C
+		return noClient, err
+	}
+	c.clients[key] = &cachedClient[C]{client: client, lastUsed: c.uses}
+	if len(c.clients) > maxCachedClients {
+		c.evictLeastRecentlyUsed()
+	}
+	return client, nil
+}
+
+// evictLeastRecentlyUsed evicts the client which has not been used for the longest time
+func (c *clientCache[C]) evictLeastRecentlyUsed() {
+	var oldestKey string
+	oldest := c.uses + 1
+	for key, cached := range c.clients {
+		if cached.lastUsed < oldest {
+			oldestKey, oldest = key, cached.lastUsed
+		}
+	}
+	c.evict(oldestKey)
+}
+
+// evict removes the client with the given key and the secrets referring to it
+func (c *clientCache[C]) evict(key string) {
+	delete(c.clients, key)
+	maps.DeleteFunc(c.secretKeys, func(_, clientKey string) bool {
+		return clientKey == key
+	})
+}
+
+// credentialsHash returns the hash of all data of the given secret except the user data, which is irrelevant for
+// building clients
+func credentialsHash(secret *corev1.Secret) string {
+	hash := sha256.New()
+	for _, key := range slices.Sorted(maps.Keys(secret.Data)) {
+		if key == AlicloudUserData {
+			continue
+		}
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		hash.Write(secret.Data[key])
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
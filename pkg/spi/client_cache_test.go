@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spi
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Client cache", func() {
+	var (
+		cachingSPI *PluginSPIImpl
+		secret     *corev1.Secret
+	)
+
+	BeforeEach(func() {
+		cachingSPI = &PluginSPIImpl{}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shoot--mcm", Name: "cloudprovider"},
+			Data: map[string][]byte{
+				AlicloudAccessKeyID:     []byte("access-key-id"),
+				AlicloudAccessKeySecret: []byte("access-key-secret"),
+				AlicloudUserData:        []byte("user-data"),
+			},
+		}
+	})
+
+	It("should reuse the client for the same credentials and region", func() {
+		client, err := cachingSPI.NewECSClient(secret, "cn-shanghai")
+		Expect(err).NotTo(HaveOccurred())
+
+		otherSecret := secret.DeepCopy()
+		otherSecret.Name = "other"
+		otherSecret.Data[AlicloudUserData] = []byte("other-user-data")
+		Expect(cachingSPI.NewECSClient(otherSecret, "cn-shanghai")).To(BeIdenticalTo(client))
+		Expect(cachingSPI.NewECSClient(secret, "cn-beijing")).NotTo(BeIdenticalTo(client))
+		Expect(cachingSPI.clients.clients).To(HaveLen(2))
+	})
+
+	It("should cache VPC clients separately from ECS clients", func() {
+		client, err := cachingSPI.NewVPCClient(secret, "cn-shanghai")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cachingSPI.NewVPCClient(secret, "cn-shanghai")).To(BeIdenticalTo(client))
+		Expect(cachingSPI.vpcClients.clients).To(HaveLen(1))
+		Expect(cachingSPI.clients.clients).To(BeEmpty())
+	})
+
+	It("should build a new client and evict the old one once the credentials of the secret change", func() {
+		client, err := cachingSPI.NewECSClient(secret, "cn-shanghai")
+		Expect(err).NotTo(HaveOccurred())
+
+		secret.Data[AlicloudAccessKeySecret] = []byte("rotated-access-key-secret")
+		Expect(cachingSPI.NewECSClient(secret, "cn-shanghai")).NotTo(BeIdenticalTo(client))
+		Expect(cachingSPI.clients.clients).To(HaveLen(1))
+	})
+
+	It("should not grow with every rotation of the credentials", func() {
+		for i := range 2 * maxCachedClients {
+			secret.Data[AlicloudAccessKeySecret] = []byte(fmt.Sprintf("rotated-access-key-secret-%d", i))
+			_, err := cachingSPI.NewECSClient(secret, "cn-shanghai")
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(cachingSPI.clients.clients).To(HaveLen(1))
+		Expect(cachingSPI.clients.secretKeys).To(HaveLen(1))
+
+		// the credentials of secrets without a name cannot be told apart from other credentials
+		secret.Name = ""
+		for i := range 2 * maxCachedClients {
+			secret.Data[AlicloudAccessKeySecret] = []byte(fmt.Sprintf("merged-access-key-secret-%d", i))
+			_, err := cachingSPI.NewECSClient(secret, "cn-shanghai")
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(cachingSPI.clients.clients).To(HaveLen(maxCachedClients))
+	})
+
+	It("should evict the least recently used client once the cache is full", func() {
+		client, err := cachingSPI.NewECSClient(secret, "cn-shanghai")
+		Expect(err).NotTo(HaveOccurred())
+
+		other := secret.DeepCopy()
+		other.Name = ""
+		for i := range maxCachedClients {
+			other.Data[AlicloudAccessKeySecret] = []byte(fmt.Sprintf("other-access-key-secret-%d", i))
+			_, err := cachingSPI.NewECSClient(other, "cn-shanghai")
+			Expect(err).NotTo(HaveOccurred())
+			// keep the client of the secret in use
+			Expect(cachingSPI.NewECSClient(secret, "cn-shanghai")).To(BeIdenticalTo(client))
+		}
+		Expect(cachingSPI.clients.clients).To(HaveLen(maxCachedClients))
+
+		other.Data[AlicloudAccessKeySecret] = []byte("other-access-key-secret-0")
+		_, err = cachingSPI.NewECSClient(other, "cn-shanghai")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cachingSPI.clients.clients).To(HaveLen(maxCachedClients))
+		Expect(cachingSPI.NewECSClient(secret, "cn-shanghai")).To(BeIdenticalTo(client))
+	})
+})
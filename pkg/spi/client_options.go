@@ -0,0 +1,155 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spi
+
+import (
+	"fmt"
+	"time"
+
+	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/aliyun/credentials-go/credentials"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// AlicloudEndpoint is a constant for a key name of a secret containing the ECS endpoint to use, e.g. of a
+	// finance-cloud region. It overrides the endpoint configured for the controller.
+	AlicloudEndpoint = "alicloudEndpoint"
+	// AlicloudEndpointType is a constant for a key name of a secret containing the type of the ECS endpoint to use,
+	// i.e. EndpointTypePublic or EndpointTypeVPC. It overrides the endpoint type configured for the controller.
+	AlicloudEndpointType = "alicloudEndpointType"
+	// AlicloudVPCEndpoint is a constant for a key name of a secret containing the VPC endpoint to use, e.g. of a
+	// finance-cloud region. It overrides the VPC endpoint configured for the controller.
+	AlicloudVPCEndpoint = "alicloudVPCEndpoint"
+
+	// EndpointTypePublic selects the public ECS and STS endpoints resolved by the SDK
+	EndpointTypePublic = "public"
+	// EndpointTypeVPC selects the ECS, VPC and STS endpoints reachable from within VPCs, e.g. `ecs-vpc.<region>.aliyuncs.com`
+	EndpointTypeVPC = "vpc"
+)
+
+var (
+	// ValidEndpointTypes are the supported types of ECS endpoints
+	ValidEndpointTypes = sets.New(EndpointTypePublic, EndpointTypeVPC)
+	// ValidProtocols are the supported protocols of ECS endpoints
+	ValidProtocols = sets.New("https", "http")
+)
+
+// ClientOptions configure how ECS clients connect to the ECS API
+type ClientOptions struct {
+	// Endpoint overrides the ECS endpoint resolved by the SDK
+	Endpoint string
+	// VPCEndpoint overrides the endpoint of the VPC API, which is called to describe vSwitches
+	VPCEndpoint string
+	// EndpointType is the type of the ECS, VPC and STS endpoints, i.e. EndpointTypePublic or EndpointTypeVPC
+	EndpointType string
+	// Protocol is the protocol used to call the ECS API, i.e. https or http
+	Protocol string
+	// Proxy is the URL of the proxy used for all calls to the ECS and STS APIs
+	Proxy string
+	// ConnectTimeout is the timeout of establishing connections, the SDK default is used if it is zero
+	ConnectTimeout time.Duration
+	// ReadTimeout is the timeout of reading responses, the SDK default is used if it is zero
+	ReadTimeout time.Duration
+}
+
+// AddFlags adds the flags of the client options to the given flag set
+func (o *ClientOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Endpoint, "ecs-endpoint", o.Endpoint, "ECS endpoint overriding the one resolved for the region, e.g. of a finance-cloud region")
+	fs.StringVar(&o.VPCEndpoint, "vpc-endpoint", o.VPCEndpoint, "VPC endpoint overriding the one of the region, e.g. of a finance-cloud region")
+	fs.StringVar(&o.EndpointType, "ecs-endpoint-type", o.EndpointType, "Type of the ECS, VPC and STS endpoints, either public or vpc")
+	fs.StringVar(&o.Protocol, "ecs-protocol", o.Protocol, "Protocol used to call the ECS API, either https or http")
+	fs.StringVar(&o.Proxy, "ecs-proxy", o.Proxy, "URL of the proxy used to call the ECS and STS APIs")
+	fs.DurationVar(&o.ConnectTimeout, "ecs-connect-timeout", o.ConnectTimeout, "Timeout of establishing connections to the ECS and STS APIs")
+	fs.DurationVar(&o.ReadTimeout, "ecs-read-timeout", o.ReadTimeout, "Timeout of reading responses of the ECS and STS APIs")
+}
+
+// Validate returns an error if the client options are invalid
+func (o *ClientOptions) Validate() error {
+	if o.EndpointType != "" && !ValidEndpointTypes.Has(o.EndpointType) {
+		return fmt.Errorf("unsupported ECS endpoint type %q, supported types are %v", o.EndpointType, sets.List(ValidEndpointTypes))
+	}
+	if o.Protocol != "" && !ValidProtocols.Has(o.Protocol) {
+		return fmt.Errorf("unsupported ECS protocol %q, supported protocols are %v", o.Protocol, sets.List(ValidProtocols))
+	}
+	return nil
+}
+
+// forSecret returns the client options with the endpoint settings of the given secret applied
+func (o ClientOptions) forSecret(secret *corev1.Secret) ClientOptions {
+	if endpoint := extractCredentialsFromData(secret.Data, AlicloudEndpoint); endpoint != "" {
+		o.Endpoint = endpoint
+	}
+	if vpcEndpoint := extractCredentialsFromData(secret.Data, AlicloudVPCEndpoint); vpcEndpoint != "" {
+		o.VPCEndpoint = vpcEndpoint
+	}
+	if endpointType := extractCredentialsFromData(secret.Data, AlicloudEndpointType); endpointType != "" {
+		o.EndpointType = endpointType
+	}
+	return o
+}
+
+// applyTo sets the endpoint, protocol, proxy and timeouts of the given ECS client config
+func (o ClientOptions) applyTo(config *openapi.Config, region string) {
+	switch {
+	case o.Endpoint != "":
+		config.Endpoint = tea.String(o.Endpoint)
+	case o.EndpointType == EndpointTypeVPC:
+		// the SDK maps some regions to public endpoints regardless of the network, hence the endpoint is set explicitly
+		config.Endpoint = tea.String(fmt.Sprintf("ecs-vpc.%s.aliyuncs.com", region))
+	}
+	o.applyConnectionTo(config)
+}
+
+// applyToVPC sets the endpoint, protocol, proxy and timeouts of the given VPC client config. The generic OpenAPI
+// client does not resolve endpoints, hence the regional endpoint is always set.
+func (o ClientOptions) applyToVPC(config *openapi.Config, region string) {
+	switch {
+	case o.VPCEndpoint != "":
+		config.Endpoint = tea.String(o.VPCEndpoint)
+	case o.EndpointType == EndpointTypeVPC:
+		config.Endpoint = tea.String(fmt.Sprintf("vpc-vpc.%s.aliyuncs.com", region))
+	default:
+		config.Endpoint = tea.String(fmt.Sprintf("vpc.%s.aliyuncs.com", region))
+	}
+	o.applyConnectionTo(config)
+}
+
+// applyConnectionTo sets the protocol, proxy and timeouts of the given client config
+func (o ClientOptions) applyConnectionTo(config *openapi.Config) {
+	if o.Protocol != "" {
+		config.Protocol = tea.String(o.Protocol)
+	}
+	if o.Proxy != "" {
+		config.HttpProxy = tea.String(o.Proxy)
+		config.HttpsProxy = tea.String(o.Proxy)
+	}
+	if o.ConnectTimeout > 0 {
+		config.ConnectTimeout = tea.Int(int(o.ConnectTimeout.Milliseconds()))
+	}
+	if o.ReadTimeout > 0 {
+		config.ReadTimeout = tea.Int(int(o.ReadTimeout.Milliseconds()))
+	}
+}
+
+// applyToCredential sets the STS endpoint, proxy and timeouts of the given credential config, which are used to assume
+// RAM roles
+func (o ClientOptions) applyToCredential(config *credentials.Config, region string) {
+	if o.EndpointType == EndpointTypeVPC {
+		config.STSEndpoint = tea.String(fmt.Sprintf("sts-vpc.%s.aliyuncs.com", region))
+	}
+	if o.Proxy != "" {
+		config.Proxy = tea.String(o.Proxy)
+	}
+	if o.ConnectTimeout > 0 {
+		config.ConnectTimeout = tea.Int(int(o.ConnectTimeout.Milliseconds()))
+	}
+	if o.ReadTimeout > 0 {
+		config.Timeout = tea.Int(int(o.ReadTimeout.Milliseconds()))
+	}
+}
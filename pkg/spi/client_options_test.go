@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spi
+
+import (
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Client options", func() {
+	var secret *corev1.Secret
+
+	BeforeEach(func() {
+		secret = &corev1.Secret{
+			Data: map[string][]byte{
+				AlicloudAccessKeyID:     []byte("access-key-id"),
+				AlicloudAccessKeySecret: []byte("access-key-secret"),
+			},
+		}
+	})
+
+	It("should resolve the public endpoint by default", func() {
+		client, err := newECSClient(secret, "cn-shanghai", ClientOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tea.StringValue(client.Endpoint)).To(Equal("ecs.cn-shanghai.aliyuncs.com"))
+
+		vpcClient, err := newVPCClient(secret, "cn-shanghai", ClientOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tea.StringValue(vpcClient.Endpoint)).To(Equal("vpc.cn-shanghai.aliyuncs.com"))
+	})
+
+	It("should configure the VPC endpoint, protocol, proxy and timeouts from the flags", func() {
+		options := ClientOptions{}
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		options.AddFlags(fs)
+		Expect(fs.Parse([]string{
+			"--ecs-endpoint-type=vpc",
+			"--ecs-protocol=http",
+			"--ecs-proxy=http://proxy.local:3128",
+			"--ecs-connect-timeout=3s",
+			"--ecs-read-timeout=1m",
+		})).To(Succeed())
+		Expect(options.Validate()).To(Succeed())
+
+		client, err := newECSClient(secret, "cn-hangzhou", options.forSecret(secret))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tea.StringValue(client.Endpoint)).To(Equal("ecs-vpc.cn-hangzhou.aliyuncs.com"))
+		Expect(tea.StringValue(client.Protocol)).To(Equal("http"))
+		Expect(tea.StringValue(client.HttpProxy)).To(Equal("http://proxy.local:3128"))
+		Expect(tea.StringValue(client.HttpsProxy)).To(Equal("http://proxy.local:3128"))
+		Expect(tea.IntValue(client.ConnectTimeout)).To(Equal(3000))
+		Expect(tea.IntValue(client.ReadTimeout)).To(Equal(60000))
+
+		vpcClient, err := newVPCClient(secret, "cn-hangzhou", options.forSecret(secret))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tea.StringValue(vpcClient.Endpoint)).To(Equal("vpc-vpc.cn-hangzhou.aliyuncs.com"))
+		Expect(tea.StringValue(vpcClient.Protocol)).To(Equal("http"))
+		Expect(tea.StringValue(vpcClient.HttpsProxy)).To(Equal("http://proxy.local:3128"))
+		Expect(tea.IntValue(vpcClient.ConnectTimeout)).To(Equal(3000))
+		Expect(tea.IntValue(vpcClient.ReadTimeout)).To(Equal(60000))
+	})
+
+	It("should prefer the endpoint settings of the secret", func() {
+		options := ClientOptions{EndpointType: EndpointTypeVPC, ReadTimeout: time.Minute}
+		secret.Data[AlicloudEndpoint] = []byte("ecs.cn-shanghai-finance-1.aliyuncs.com")
+		secret.Data[AlicloudVPCEndpoint] = []byte("vpc.cn-shanghai-finance-1.aliyuncs.com")
+
+		Expect(options.forSecret(secret)).To(Equal(ClientOptions{
+			Endpoint:     "ecs.cn-shanghai-finance-1.aliyuncs.com",
+			VPCEndpoint:  "vpc.cn-shanghai-finance-1.aliyuncs.com",
+			EndpointType: EndpointTypeVPC,
+			ReadTimeout:  time.Minute,
+		}))
+
+		secret.Data = map[string][]byte{AlicloudEndpointType: []byte(EndpointTypePublic)}
+		Expect(options.forSecret(secret).EndpointType).To(Equal(EndpointTypePublic))
+	})
+
+	It("should reject unsupported endpoint types and protocols", func() {
+		Expect((&ClientOptions{EndpointType: "internal"}).Validate()).To(MatchError(ContainSubstring("unsupported ECS endpoint type")))
+		Expect((&ClientOptions{Protocol: "ftp"}).Validate()).To(MatchError(ContainSubstring("unsupported ECS protocol")))
+	})
+})
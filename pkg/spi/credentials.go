@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/aliyun/credentials-go/credentials"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// AlicloudSecurityToken is a constant for a key name of a secret containing the STS security token belonging to
+	// the access key, i.e. if the access key is a temporary one.
+	AlicloudSecurityToken = "alicloudSecurityToken"
+	// AlicloudRoleARN is a constant for a key name of a secret containing the ARN of a RAM role, which is assumed
+	// with the access key or the OIDC token of the secret.
+	AlicloudRoleARN = "alicloudRoleARN"
+	// AlicloudRoleSessionName is a constant for a key name of a secret containing the session name used to assume
+	// the RAM role, DefaultRoleSessionName is used if it is missing.
+	AlicloudRoleSessionName = "alicloudRoleSessionName"
+	// AlicloudRoleSessionDuration is a constant for a key name of a secret containing the duration of the RAM role
+	// session, e.g. `1h`. The session is refreshed shortly before it expires.
+	AlicloudRoleSessionDuration = "alicloudRoleSessionDuration"
+	// AlicloudOIDCProviderARN is a constant for a key name of a secret containing the ARN of the RAM OIDC identity
+	// provider trusting the service account tokens of the cluster. If it is set, the RAM role is assumed with the
+	// OIDC token instead of an access key.
+	AlicloudOIDCProviderARN = "alicloudOIDCProviderARN"
+	// AlicloudOIDCTokenFile is a constant for a key name of a secret containing the path of the OIDC token file, e.g.
+	// of a projected service account token volume. If it is missing, the path is read from the environment variable
+	// ALIBABA_CLOUD_OIDC_TOKEN_FILE set by the RRSA webhook.
+	AlicloudOIDCTokenFile = "alicloudOIDCTokenFile"
+
+	// DefaultRoleSessionName is the session name used to assume RAM roles if the secret does not contain one
+	DefaultRoleSessionName = "machine-controller-manager"
+
+	credentialTypeAccessKey  = "access_key"
+	credentialTypeSTS        = "sts"
+	credentialTypeRAMRoleARN = "ram_role_arn"
+	credentialTypeOIDCRole   = "oidc_role_arn"
+
+	// MinRoleSessionDuration is the minimum duration of RAM role sessions accepted by STS
+	MinRoleSessionDuration = 15 * time.Minute
+	// MaxRoleSessionDuration is the maximum duration of RAM role sessions accepted by STS
+	MaxRoleSessionDuration = 12 * time.Hour
+)
+
+// newCredential returns the credential for the given secret. It holds either a static access key, a temporary access
+// key with STS security token, or assumes a RAM role with one of them or with an OIDC token and refreshes the role
+// session before it expires. The STS endpoint, proxy and timeouts of the given client options are used to assume roles.
+func newCredential(secret *corev1.Secret, region string, options ClientOptions) (credentials.Credential, error) {
+	if oidcProviderARN := extractCredentialsFromData(secret.Data, AlicloudOIDCProviderARN); oidcProviderARN != "" {
+		config := &credentials.Config{
+			Type:              tea.String(credentialTypeOIDCRole),
+			OIDCProviderArn:   tea.String(oidcProviderARN),
+			OIDCTokenFilePath: tea.String(extractCredentialsFromData(secret.Data, AlicloudOIDCTokenFile)),
+		}
+		if err := setRoleSession(config, secret); err != nil {
+			return nil, err
+		}
+		options.applyToCredential(config, region)
+		return credentials.NewCredential(config)
+	}
+
+	config := &credentials.Config{
+		Type:            tea.String(credentialTypeAccessKey),
+		AccessKeyId:     tea.String(extractCredentialsFromData(secret.Data, AlicloudAccessKeyID, AlicloudAlternativeAccessKeyID)),
+		AccessKeySecret: tea.String(extractCredentialsFromData(secret.Data, AlicloudAccessKeySecret, AlicloudAlternativeAccessKeySecret)),
+	}
+
+	if securityToken := extractCredentialsFromData(secret.Data, AlicloudSecurityToken); securityToken != "" {
+		config.Type = tea.String(credentialTypeSTS)
+		config.SecurityToken = tea.String(securityToken)
+	}
+
+	if extractCredentialsFromData(secret.Data, AlicloudRoleARN) != "" {
+		config.Type = tea.String(credentialTypeRAMRoleARN)
+		if err := setRoleSession(config, secret); err != nil {
+			return nil, err
+		}
+		options.applyToCredential(config, region)
+	}
+
+	return credentials.NewCredential(config)
+}
+
+// setRoleSession sets the RAM role to assume and the name and duration of its session from the given secret
+func setRoleSession(config *credentials.Config, secret *corev1.Secret) error {
+	config.RoleArn = tea.String(extractCredentialsFromData(secret.Data, AlicloudRoleARN))
+	config.RoleSessionName = tea.String(DefaultRoleSessionName)
+	if sessionName := extractCredentialsFromData(secret.Data, AlicloudRoleSessionName); sessionName != "" {
+		config.RoleSessionName = tea.String(sessionName)
+	}
+	if duration := extractCredentialsFromData(secret.Data, AlicloudRoleSessionDuration); duration != "" {
+		sessionDuration, err := time.ParseDuration(duration)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", AlicloudRoleSessionDuration, err)
+		}
+		config.RoleSessionExpiration = tea.Int(int(sessionDuration.Seconds()))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spi
+
+import (
+	"github.com/alibabacloud-go/tea/tea"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Credentials", func() {
+	var secret *corev1.Secret
+
+	BeforeEach(func() {
+		secret = &corev1.Secret{
+			Data: map[string][]byte{
+				AlicloudAlternativeAccessKeyID:     []byte("access-key-id"),
+				AlicloudAlternativeAccessKeySecret: []byte("access-key-secret"),
+			},
+		}
+	})
+
+	It("should use the static access key", func() {
+		credential, err := newCredential(secret, "cn-shanghai", ClientOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		model, err := credential.GetCredential()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tea.StringValue(model.Type)).To(Equal(credentialTypeAccessKey))
+		Expect(tea.StringValue(model.AccessKeyId)).To(Equal("access-key-id"))
+		Expect(tea.StringValue(model.AccessKeySecret)).To(Equal("access-key-secret"))
+	})
+
+	It("should use the STS security token of a temporary access key", func() {
+		secret.Data[AlicloudSecurityToken] = []byte("security-token")
+
+		credential, err := newCredential(secret, "cn-shanghai", ClientOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		model, err := credential.GetCredential()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tea.StringValue(model.Type)).To(Equal(credentialTypeSTS))
+		Expect(tea.StringValue(model.SecurityToken)).To(Equal("security-token"))
+	})
+
+	It("should assume the RAM role", func() {
+		secret.Data[AlicloudRoleARN] = []byte("acs:ram::123456789:role/machine-controller-manager")
+		secret.Data[AlicloudRoleSessionDuration] = []byte("1h")
+
+		credential, err := newCredential(secret, "cn-shanghai", ClientOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tea.StringValue(credential.GetType())).To(Equal(credentialTypeRAMRoleARN))
+	})
+
+	It("should assume the RAM role with the OIDC token", func() {
+		secret.Data = map[string][]byte{
+			AlicloudRoleARN:         []byte("acs:ram::123456789:role/machine-controller-manager"),
+			AlicloudOIDCProviderARN: []byte("acs:ram::123456789:oidc-provider/ack-rrsa-c123"),
+			AlicloudOIDCTokenFile:   []byte("/var/run/secrets/ack.alibabacloud.com/rrsa-tokens/token"),
+		}
+
+		credential, err := newCredential(secret, "cn-shanghai", ClientOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tea.StringValue(credential.GetType())).To(Equal(credentialTypeOIDCRole))
+	})
+
+	It("should reject an invalid role session duration", func() {
+		secret.Data[AlicloudRoleARN] = []byte("acs:ram::123456789:role/machine-controller-manager")
+		secret.Data[AlicloudRoleSessionDuration] = []byte("3600")
+
+		_, err := newCredential(secret, "cn-shanghai", ClientOptions{})
+		Expect(err).To(MatchError(ContainSubstring("invalid alicloudRoleSessionDuration")))
+	})
+})
@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spi
+
+import (
+	"time"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
+
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/metrics"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/vpc"
+)
+
+// instrumentedECSClient is an ECSClient recording the metrics of each call
+type instrumentedECSClient struct {
+	client ECSClient
+	region string
+}
+
+// newInstrumentedECSClient returns an ECSClient which records the count, duration and result of each call of the
+// given client for the region.
+func newInstrumentedECSClient(client ECSClient, region string) ECSClient {
+	return &instrumentedECSClient{
+		client: client,
+		region: region,
+	}
+}
+
+func (c *instrumentedECSClient) RunInstances(request *ecs.RunInstancesRequest) (*ecs.RunInstancesResponse, error) {
+	start := time.Now()
+	response, err := c.client.RunInstances(request)
+	metrics.ObserveECSRequest("RunInstances", c.region, start, err)
+	return response, err
+}
+
+func (c *instrumentedECSClient) DescribeInstances(request *ecs.DescribeInstancesRequest) (*ecs.DescribeInstancesResponse, error) {
+	start := time.Now()
+	response, err := c.client.DescribeInstances(request)
+	metrics.ObserveECSRequest("DescribeInstances", c.region, start, err)
+	return response, err
+}
+
+func (c *instrumentedECSClient) DeleteInstance(request *ecs.DeleteInstanceRequest) (*ecs.DeleteInstanceResponse, error) {
+	start := time.Now()
+	response, err := c.client.DeleteInstance(request)
+	metrics.ObserveECSRequest("DeleteInstance", c.region, start, err)
+	return response, err
+}
+
+func (c *instrumentedECSClient) DescribeDisks(request *ecs.DescribeDisksRequest) (*ecs.DescribeDisksResponse, error) {
+	start := time.Now()
+	response, err := c.client.DescribeDisks(request)
+	metrics.ObserveECSRequest("DescribeDisks", c.region, start, err)
+	return response, err
+}
+
+func (c *instrumentedECSClient) DeleteDisk(request *ecs.DeleteDiskRequest) (*ecs.DeleteDiskResponse, error) {
+	start := time.Now()
+	response, err := c.client.DeleteDisk(request)
+	metrics.ObserveECSRequest("DeleteDisk", c.region, start, err)
+	return response, err
+}
+
+func (c *instrumentedECSClient) DescribeNetworkInterfaces(request *ecs.DescribeNetworkInterfacesRequest) (*ecs.DescribeNetworkInterfacesResponse, error) {
+	start := time.Now()
+	response, err := c.client.DescribeNetworkInterfaces(request)
+	metrics.ObserveECSRequest("DescribeNetworkInterfaces", c.region, start, err)
+	return response, err
+}
+
+func (c *instrumentedECSClient) DeleteNetworkInterface(request *ecs.DeleteNetworkInterfaceRequest) (*ecs.DeleteNetworkInterfaceResponse, error) {
+	start := time.Now()
+	response, err := c.client.DeleteNetworkInterface(request)
+	metrics.ObserveECSRequest("DeleteNetworkInterface", c.region, start, err)
+	return response, err
+}
+
+func (c *instrumentedECSClient) TagResources(request *ecs.TagResourcesRequest) (*ecs.TagResourcesResponse, error) {
+	start := time.Now()
+	response, err := c.client.TagResources(request)
+	metrics.ObserveECSRequest("TagResources", c.region, start, err)
+	return response, err
+}
+
+func (c *instrumentedECSClient) AssignIpv6Addresses(request *ecs.AssignIpv6AddressesRequest) (*ecs.AssignIpv6AddressesResponse, error) {
+	start := time.Now()
+	response, err := c.client.AssignIpv6Addresses(request)
+	metrics.ObserveECSRequest("AssignIpv6Addresses", c.region, start, err)
+	return response, err
+}
+
+// instrumentedVPCClient is a VPCClient recording the metrics of each call
+type instrumentedVPCClient struct {
+	client VPCClient
+	region string
+}
+
+// newInstrumentedVPCClient returns a VPCClient which records the count, duration and result of each call of the
+// given client for the region.
+func newInstrumentedVPCClient(client VPCClient, region string) VPCClient {
+	return &instrumentedVPCClient{
+		client: client,
+		region: region,
+	}
+}
+
+func (c *instrumentedVPCClient) DescribeVSwitches(request *vpc.DescribeVSwitchesRequest) (*vpc.DescribeVSwitchesResponse, error) {
+	start := time.Now()
+	response, err := c.client.DescribeVSwitches(request)
+	metrics.ObserveVPCRequest("DescribeVSwitches", c.region, start, err)
+	return response, err
+}
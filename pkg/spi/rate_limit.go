@@ -0,0 +1,224 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
+	"github.com/spf13/pflag"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+
+	maperror "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/errors"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/vpc"
+)
+
+// DefaultRateLimits are the rate limits of ECS calls per account and region, which keep the driver well below the
+// flow control limits of ECS.
+var DefaultRateLimits = RateLimits{
+	ReadQPS:    20,
+	ReadBurst:  40,
+	WriteQPS:   5,
+	WriteBurst: 10,
+}
+
+// RateLimits are the token bucket limits of ECS calls per account and region. Describe* calls are reads, all other
+// calls are writes. A rate of zero disables limiting the respective calls. VPC calls, which are all reads, are limited
+// by the read limits in a bucket of their own, as the VPC API has flow control limits of its own.
+type RateLimits struct {
+	// ReadQPS is the rate of read calls per second
+	ReadQPS float64
+	// ReadBurst is the number of read calls which can be made at once
+	ReadBurst int
+	// WriteQPS is the rate of write calls per second
+	WriteQPS float64
+	// WriteBurst is the number of write calls which can be made at once
+	WriteBurst int
+}
+
+// AddFlags adds the flags of the rate limits to the given flag set
+func (l *RateLimits) AddFlags(fs *pflag.FlagSet) {
+	fs.Float64Var(&l.ReadQPS, "ecs-read-qps", l.ReadQPS, "Maximum rate of ECS Describe* calls per second and account and region, 0 disables limiting")
+	fs.IntVar(&l.ReadBurst, "ecs-read-burst", l.ReadBurst, "Maximum burst of ECS Describe* calls per account and region")
+	fs.Float64Var(&l.WriteQPS, "ecs-write-qps", l.WriteQPS, "Maximum rate of mutating ECS calls per second and account and region, 0 disables limiting")
+	fs.IntVar(&l.WriteBurst, "ecs-write-burst", l.WriteBurst, "Maximum burst of mutating ECS calls per account and region")
+}
+
+// newLimiter returns a token bucket limiter with the given rate and burst, which does not limit at all if the rate is zero
+func newLimiter(qps float64, burst int) *rate.Limiter {
+	if qps <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(qps), max(burst, 1))
+}
+
+// RateLimiter limits the read and write calls of all ECS clients and the calls of all VPC clients of an account and
+// region
+type RateLimiter struct {
+	read  *rate.Limiter
+	write *rate.Limiter
+	vpc   *rate.Limiter
+}
+
+// RateLimiters hands out the rate limiters per account and region, so that ECS and VPC calls for all machine classes
+// using the same account and region share their limits
+type RateLimiters struct {
+	limits RateLimits
+
+	mu       sync.Mutex
+	limiters map[string]*RateLimiter
+}
+
+// NewRateLimiters returns the rate limiters applying the given limits to each account and region
+func NewRateLimiters(limits RateLimits) *RateLimiters {
+	return &RateLimiters{
+		limits:   limits,
+		limiters: map[string]*RateLimiter{},
+	}
+}
+
+// ForAccount returns the rate limiter of the account the given secret belongs to and the region. The account is
+// identified by the RAM role assumed or, if there is none, by the access key ID of the secret.
+func (r *RateLimiters) ForAccount(secret *corev1.Secret, region string) *RateLimiter {
+	account := extractCredentialsFromData(secret.Data, AlicloudRoleARN)
+	if account == "" {
+		account = extractCredentialsFromData(secret.Data, AlicloudAccessKeyID, AlicloudAlternativeAccessKeyID)
+	}
+	key := account + "/" + region
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	limiter, ok := r.limiters[key]
+	if !ok {
+		limiter = &RateLimiter{
+			read:  newLimiter(r.limits.ReadQPS, r.limits.ReadBurst),
+			write: newLimiter(r.limits.WriteQPS, r.limits.WriteBurst),
+			vpc:   newLimiter(r.limits.ReadQPS, r.limits.ReadBurst),
+		}
+		r.limiters[key] = limiter
+	}
+	return limiter
+}
+
+// rateLimitedECSClient is an ECSClient waiting for the rate limiter before each call
+type rateLimitedECSClient struct {
+	ctx     context.Context
+	client  ECSClient
+	limiter *RateLimiter
+}
+
+// NewRateLimitedECSClient returns an ECSClient which waits for the given rate limiter before each call of the given
+// client. If the given context is done before the call is allowed, the call fails with maperror.ErrClientRateLimited.
+func NewRateLimitedECSClient(ctx context.Context, client ECSClient, limiter *RateLimiter) ECSClient {
+	return &rateLimitedECSClient{
+		ctx:     ctx,
+		client:  client,
+		limiter: limiter,
+	}
+}
+
+func (c *rateLimitedECSClient) RunInstances(request *ecs.RunInstancesRequest) (*ecs.RunInstancesResponse, error) {
+	if err := c.wait(c.limiter.write, "RunInstances"); err != nil {
+		return nil, err
+	}
+	return c.client.RunInstances(request)
+}
+
+func (c *rateLimitedECSClient) DescribeInstances(request *ecs.DescribeInstancesRequest) (*ecs.DescribeInstancesResponse, error) {
+	if err := c.wait(c.limiter.read, "DescribeInstances"); err != nil {
+		return nil, err
+	}
+	return c.client.DescribeInstances(request)
+}
+
+func (c *rateLimitedECSClient) DeleteInstance(request *ecs.DeleteInstanceRequest) (*ecs.DeleteInstanceResponse, error) {
+	if err := c.wait(c.limiter.write, "DeleteInstance"); err != nil {
+		return nil, err
+	}
+	return c.client.DeleteInstance(request)
+}
+
+func (c *rateLimitedECSClient) DescribeDisks(request *ecs.DescribeDisksRequest) (*ecs.DescribeDisksResponse, error) {
+	if err := c.wait(c.limiter.read, "DescribeDisks"); err != nil {
+		return nil, err
+	}
+	return c.client.DescribeDisks(request)
+}
+
+func (c *rateLimitedECSClient) DeleteDisk(request *ecs.DeleteDiskRequest) (*ecs.DeleteDiskResponse, error) {
+	if err := c.wait(c.limiter.write, "DeleteDisk"); err != nil {
+		return nil, err
+	}
+	return c.client.DeleteDisk(request)
+}
+
+func (c *rateLimitedECSClient) DescribeNetworkInterfaces(request *ecs.DescribeNetworkInterfacesRequest) (*ecs.DescribeNetworkInterfacesResponse, error) {
+	if err := c.wait(c.limiter.read, "DescribeNetworkInterfaces"); err != nil {
+		return nil, err
+	}
+	return c.client.DescribeNetworkInterfaces(request)
+}
+
+func (c *rateLimitedECSClient) DeleteNetworkInterface(request *ecs.DeleteNetworkInterfaceRequest) (*ecs.DeleteNetworkInterfaceResponse, error) {
+	if err := c.wait(c.limiter.write, "DeleteNetworkInterface"); err != nil {
+		return nil, err
+	}
+	return c.client.DeleteNetworkInterface(request)
+}
+
+func (c *rateLimitedECSClient) TagResources(request *ecs.TagResourcesRequest) (*ecs.TagResourcesResponse, error) {
+	if err := c.wait(c.limiter.write, "TagResources"); err != nil {
+		return nil, err
+	}
+	return c.client.TagResources(request)
+}
+
+func (c *rateLimitedECSClient) AssignIpv6Addresses(request *ecs.AssignIpv6AddressesRequest) (*ecs.AssignIpv6AddressesResponse, error) {
+	if err := c.wait(c.limiter.write, "AssignIpv6Addresses"); err != nil {
+		return nil, err
+	}
+	return c.client.AssignIpv6Addresses(request)
+}
+
+func (c *rateLimitedECSClient) wait(limiter *rate.Limiter, action string) error {
+	return waitForLimiter(c.ctx, limiter, "ECS "+action)
+}
+
+// rateLimitedVPCClient is a VPCClient waiting for the rate limiter before each call
+type rateLimitedVPCClient struct {
+	ctx     context.Context
+	client  VPCClient
+	limiter *RateLimiter
+}
+
+// NewRateLimitedVPCClient returns a VPCClient which waits for the VPC limiter of the given rate limiter before each
+// call of the given client. If the given context is done before the call is allowed, the call fails with
+// maperror.ErrClientRateLimited.
+func NewRateLimitedVPCClient(ctx context.Context, client VPCClient, limiter *RateLimiter) VPCClient {
+	return &rateLimitedVPCClient{
+		ctx:     ctx,
+		client:  client,
+		limiter: limiter,
+	}
+}
+
+func (c *rateLimitedVPCClient) DescribeVSwitches(request *vpc.DescribeVSwitchesRequest) (*vpc.DescribeVSwitchesResponse, error) {
+	if err := waitForLimiter(c.ctx, c.limiter.vpc, "VPC DescribeVSwitches"); err != nil {
+		return nil, err
+	}
+	return c.client.DescribeVSwitches(request)
+}
+
+// waitForLimiter waits until the given limiter allows the given call or the context is done
+func waitForLimiter(ctx context.Context, limiter *rate.Limiter, call string) error {
+	if err := limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("%w: %s call not allowed in time: %v", maperror.ErrClientRateLimited, call, err)
+	}
+	return nil
+}
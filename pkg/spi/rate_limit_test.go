@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spi
+
+import (
+	"context"
+	"time"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	maperror "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/errors"
+	mockclient "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/mock/client"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/vpc"
+)
+
+var _ = Describe("Rate limited ECS client", func() {
+	var (
+		ctrl          *gomock.Controller
+		mockECSClient *mockclient.MockECSClient
+		secret        *corev1.Secret
+
+		describeInstancesRequest = &ecs.DescribeInstancesRequest{}
+		deleteInstanceRequest    = &ecs.DeleteInstanceRequest{}
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockECSClient = mockclient.NewMockECSClient(ctrl)
+		secret = &corev1.Secret{
+			Data: map[string][]byte{
+				AlicloudAccessKeyID:     []byte("access-key-id"),
+				AlicloudAccessKeySecret: []byte("access-key-secret"),
+			},
+		}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should share the limiters per account and region", func() {
+		limiters := NewRateLimiters(DefaultRateLimits)
+		limiter := limiters.ForAccount(secret, "cn-shanghai")
+
+		otherSecret := secret.DeepCopy()
+		otherSecret.Data[AlicloudAccessKeySecret] = []byte("rotated-access-key-secret")
+		Expect(limiters.ForAccount(otherSecret, "cn-shanghai")).To(BeIdenticalTo(limiter))
+		Expect(limiters.ForAccount(secret, "cn-beijing")).NotTo(BeIdenticalTo(limiter))
+
+		otherSecret.Data[AlicloudRoleARN] = []byte("acs:ram::123456789:role/machine-controller-manager")
+		Expect(limiters.ForAccount(otherSecret, "cn-shanghai")).NotTo(BeIdenticalTo(limiter))
+	})
+
+	It("should limit reads and writes separately", func() {
+		limiter := NewRateLimiters(RateLimits{ReadQPS: 0.001, ReadBurst: 1, WriteQPS: 0.001, WriteBurst: 1}).ForAccount(secret, "cn-shanghai")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		client := NewRateLimitedECSClient(ctx, mockECSClient, limiter)
+
+		mockECSClient.EXPECT().DescribeInstances(describeInstancesRequest).Return(&ecs.DescribeInstancesResponse{}, nil)
+		mockECSClient.EXPECT().DeleteInstance(deleteInstanceRequest).Return(&ecs.DeleteInstanceResponse{}, nil)
+
+		_, err := client.DescribeInstances(describeInstancesRequest)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.DeleteInstance(deleteInstanceRequest)
+		Expect(err).NotTo(HaveOccurred())
+
+		// the burst of both classes is exhausted and no further call is allowed before the context is done
+		_, err = client.DescribeInstances(describeInstancesRequest)
+		Expect(err).To(MatchError(maperror.ErrClientRateLimited))
+		_, err = client.DeleteInstance(deleteInstanceRequest)
+		Expect(err).To(MatchError(maperror.ErrClientRateLimited))
+	})
+
+	It("should limit VPC calls separately from ECS calls", func() {
+		limiter := NewRateLimiters(RateLimits{ReadQPS: 0.001, ReadBurst: 1}).ForAccount(secret, "cn-shanghai")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		client := NewRateLimitedECSClient(ctx, mockECSClient, limiter)
+		mockVPCClient := mockclient.NewMockVPCClient(ctrl)
+		vpcClient := NewRateLimitedVPCClient(ctx, mockVPCClient, limiter)
+		describeVSwitchesRequest := &vpc.DescribeVSwitchesRequest{}
+
+		mockECSClient.EXPECT().DescribeInstances(describeInstancesRequest).Return(&ecs.DescribeInstancesResponse{}, nil)
+		mockVPCClient.EXPECT().DescribeVSwitches(describeVSwitchesRequest).Return(&vpc.DescribeVSwitchesResponse{}, nil)
+
+		_, err := client.DescribeInstances(describeInstancesRequest)
+		Expect(err).NotTo(HaveOccurred())
+		// the VPC call does not count against the exhausted burst of ECS reads
+		_, err = vpcClient.DescribeVSwitches(describeVSwitchesRequest)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = vpcClient.DescribeVSwitches(describeVSwitchesRequest)
+		Expect(err).To(MatchError(maperror.ErrClientRateLimited))
+		Expect(err).To(MatchError(ContainSubstring("VPC DescribeVSwitches call")))
+	})
+
+	It("should not limit calls if the rate is zero", func() {
+		limiter := NewRateLimiters(RateLimits{}).ForAccount(secret, "cn-shanghai")
+		client := NewRateLimitedECSClient(context.Background(), mockECSClient, limiter)
+
+		mockECSClient.EXPECT().DescribeInstances(describeInstancesRequest).Return(&ecs.DescribeInstancesResponse{}, nil).Times(100)
+		for range 100 {
+			_, err := client.DescribeInstances(describeInstancesRequest)
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+})
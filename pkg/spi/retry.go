@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spi
+
+import (
+	"context"
+	"time"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	maperror "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/errors"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/vpc"
+)
+
+// DefaultRetryBackoff is the backoff used to retry ECS and VPC calls failing with throttling or server errors. With its
+// 5 steps a call is attempted at most 5 times, waiting up to 10 seconds between the attempts.
+var DefaultRetryBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.5,
+	Steps:    5,
+	Cap:      10 * time.Second,
+}
+
+// retrier retries the calls of an Alibaba Cloud API failing with transient errors
+type retrier struct {
+	ctx     context.Context
+	backoff wait.Backoff
+	// api is the name of the API, which prefixes the actions in the log
+	api string
+}
+
+// retryingECSClient is an ECSClient retrying idempotent calls failing with transient errors
+type retryingECSClient struct {
+	retrier
+	client ECSClient
+}
+
+// NewRetryingECSClient returns an ECSClient which retries the idempotent calls of the given client, i.e. Describe*,
+// Delete* and RunInstances requests carrying a client token, as long as they fail with throttling, server or network
+// errors. The attempts are delayed according to the given backoff, whose steps are the maximum number of attempts of
+// a single call. Retrying stops as soon as the given context is done.
+func NewRetryingECSClient(ctx context.Context, client ECSClient, backoff wait.Backoff) ECSClient {
+	return &retryingECSClient{
+		retrier: retrier{ctx: ctx, backoff: backoff, api: "ECS"},
+		client:  client,
+	}
+}
+
+// RunInstances is only retried if the request carries a client token, which makes ECS create the instances at most once
+func (c *retryingECSClient) RunInstances(request *ecs.RunInstancesRequest) (*ecs.RunInstancesResponse, error) {
+	if tea.StringValue(request.ClientToken) == "" {
+		return c.client.RunInstances(request)
+	}
+	return retry(&c.retrier, "RunInstances", func() (*ecs.RunInstancesResponse, error) {
+		return c.client.RunInstances(request)
+	})
+}
+
+func (c *retryingECSClient) DescribeInstances(request *ecs.DescribeInstancesRequest) (*ecs.DescribeInstancesResponse, error) {
+	return retry(&c.retrier, "DescribeInstances", func() (*ecs.DescribeInstancesResponse, error) {
+		return c.client.DescribeInstances(request)
+	})
+}
+
+func (c *retryingECSClient) DeleteInstance(request *ecs.DeleteInstanceRequest) (*ecs.DeleteInstanceResponse, error) {
+	return retry(&c.retrier, "DeleteInstance", func() (*ecs.DeleteInstanceResponse, error) {
+		return c.client.DeleteInstance(request)
+	})
+}
+
+func (c *retryingECSClient) DescribeDisks(request *ecs.DescribeDisksRequest) (*ecs.DescribeDisksResponse, error) {
+	return retry(&c.retrier, "DescribeDisks", func() (*ecs.DescribeDisksResponse, error) {
+		return c.client.DescribeDisks(request)
+	})
+}
+
+func (c *retryingECSClient) DeleteDisk(request *ecs.DeleteDiskRequest) (*ecs.DeleteDiskResponse, error) {
+	return retry(&c.retrier, "DeleteDisk", func() (*ecs.DeleteDiskResponse, error) {
+		return c.client.DeleteDisk(request)
+	})
+}
+
+func (c *retryingECSClient) DescribeNetworkInterfaces(request *ecs.DescribeNetworkInterfacesRequest) (*ecs.DescribeNetworkInterfacesResponse, error) {
+	return retry(&c.retrier, "DescribeNetworkInterfaces", func() (*ecs.DescribeNetworkInterfacesResponse, error) {
+		return c.client.DescribeNetworkInterfaces(request)
+	})
+}
+
+func (c *retryingECSClient) DeleteNetworkInterface(request *ecs.DeleteNetworkInterfaceRequest) (*ecs.DeleteNetworkInterfaceResponse, error) {
+	return retry(&c.retrier, "DeleteNetworkInterface", func() (*ecs.DeleteNetworkInterfaceResponse, error) {
+		return c.client.DeleteNetworkInterface(request)
+	})
+}
+
+// TagResources is not retried, the driver tags instances again on the next InitializeMachine call
+func (c *retryingECSClient) TagResources(request *ecs.TagResourcesRequest) (*ecs.TagResourcesResponse, error) {
+	return c.client.TagResources(request)
+}
+
+// AssignIpv6Addresses is not retried, the driver assigns the addresses again on the next InitializeMachine call unless
+// the network interface has IPv6 addresses already
+func (c *retryingECSClient) AssignIpv6Addresses(request *ecs.AssignIpv6AddressesRequest) (*ecs.AssignIpv6AddressesResponse, error) {
+	return c.client.AssignIpv6Addresses(request)
+}
+
+// retryingVPCClient is a VPCClient retrying calls failing with transient errors
+type retryingVPCClient struct {
+	retrier
+	client VPCClient
+}
+
+// NewRetryingVPCClient returns a VPCClient which retries the calls of the given client, which are all idempotent, as
+// long as they fail with throttling, server or network errors. The attempts are delayed like the ones of
+// NewRetryingECSClient.
+func NewRetryingVPCClient(ctx context.Context, client VPCClient, backoff wait.Backoff) VPCClient {
+	return &retryingVPCClient{
+		retrier: retrier{ctx: ctx, backoff: backoff, api: "VPC"},
+		client:  client,
+	}
+}
+
+func (c *retryingVPCClient) DescribeVSwitches(request *vpc.DescribeVSwitchesRequest) (*vpc.DescribeVSwitchesResponse, error) {
+	return retry(&c.retrier, "DescribeVSwitches", func() (*vpc.DescribeVSwitchesResponse, error) {
+		return c.client.DescribeVSwitches(request)
+	})
+}
+
+// retry calls the given function until it succeeds, fails with a non-transient error, the backoff steps are exhausted
+// or the context of the retrier is done. The result and error of the last attempt are returned.
+func retry[T any](r *retrier, action string, call func() (T, error)) (T, error) {
+	backoff := r.backoff
+	for {
+		result, err := call()
+		if err == nil || !maperror.IsTransient(err) || backoff.Steps <= 1 {
+			return result, err
+		}
+
+		delay := backoff.Step()
+		klog.V(3).Infof("%s %s call failed with transient error, retrying in %s: %v", r.api, action, delay, err)
+		select {
+		case <-r.ctx.Done():
+			return result, err
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spi
+
+import (
+	"context"
+	"time"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	mockclient "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/mock/client"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/vpc"
+)
+
+var _ = Describe("Retrying ECS client", func() {
+	var (
+		ctrl          *gomock.Controller
+		mockECSClient *mockclient.MockECSClient
+		client        ECSClient
+		backoff       = wait.Backoff{Duration: time.Millisecond, Factor: 2, Jitter: 0.5, Steps: 3}
+
+		throttlingErr = tea.NewSDKError(map[string]any{
+			"code":    "Throttling.User",
+			"message": "Request was denied due to user flow control.",
+		})
+		forbiddenErr = tea.NewSDKError(map[string]any{
+			"code":    "Forbidden.RAM",
+			"message": "User not authorized to operate on the specified resource.",
+		})
+
+		describeInstancesRequest  = &ecs.DescribeInstancesRequest{RegionId: tea.String("cn-shanghai")}
+		describeInstancesResponse = &ecs.DescribeInstancesResponse{}
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockECSClient = mockclient.NewMockECSClient(ctrl)
+		client = NewRetryingECSClient(context.Background(), mockECSClient, backoff)
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should retry throttled calls until they succeed", func() {
+		gomock.InOrder(
+			mockECSClient.EXPECT().DescribeInstances(describeInstancesRequest).Return(nil, throttlingErr).Times(2),
+			mockECSClient.EXPECT().DescribeInstances(describeInstancesRequest).Return(describeInstancesResponse, nil),
+		)
+
+		Expect(client.DescribeInstances(describeInstancesRequest)).To(Equal(describeInstancesResponse))
+	})
+
+	It("should retry server errors", func() {
+		serverErr := tea.NewSDKError(map[string]any{
+			"code":    "InternalError",
+			"message": "The request processing has failed due to some unknown error.",
+			"data":    map[string]any{"statusCode": 500},
+		})
+		deleteDiskRequest := &ecs.DeleteDiskRequest{DiskId: tea.String("d-mockdiskid")}
+		gomock.InOrder(
+			mockECSClient.EXPECT().DeleteDisk(deleteDiskRequest).Return(nil, serverErr),
+			mockECSClient.EXPECT().DeleteDisk(deleteDiskRequest).Return(&ecs.DeleteDiskResponse{}, nil),
+		)
+
+		_, err := client.DeleteDisk(deleteDiskRequest)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should give up after the backoff steps are exhausted", func() {
+		mockECSClient.EXPECT().DescribeInstances(describeInstancesRequest).Return(nil, throttlingErr).Times(backoff.Steps)
+
+		_, err := client.DescribeInstances(describeInstancesRequest)
+		Expect(err).To(Equal(throttlingErr))
+	})
+
+	It("should not retry non-transient errors", func() {
+		mockECSClient.EXPECT().DescribeInstances(describeInstancesRequest).Return(nil, forbiddenErr)
+
+		_, err := client.DescribeInstances(describeInstancesRequest)
+		Expect(err).To(Equal(forbiddenErr))
+	})
+
+	It("should retry RunInstances requests carrying a client token", func() {
+		runInstancesRequest := &ecs.RunInstancesRequest{ClientToken: tea.String("mock-client-token")}
+		runInstancesResponse := &ecs.RunInstancesResponse{}
+		gomock.InOrder(
+			mockECSClient.EXPECT().RunInstances(runInstancesRequest).Return(nil, throttlingErr),
+			mockECSClient.EXPECT().RunInstances(runInstancesRequest).Return(runInstancesResponse, nil),
+		)
+
+		Expect(client.RunInstances(runInstancesRequest)).To(Equal(runInstancesResponse))
+	})
+
+	It("should not retry RunInstances requests without client token", func() {
+		runInstancesRequest := &ecs.RunInstancesRequest{}
+		mockECSClient.EXPECT().RunInstances(runInstancesRequest).Return(nil, throttlingErr)
+
+		_, err := client.RunInstances(runInstancesRequest)
+		Expect(err).To(Equal(throttlingErr))
+	})
+
+	It("should not retry TagResources", func() {
+		tagResourcesRequest := &ecs.TagResourcesRequest{}
+		mockECSClient.EXPECT().TagResources(tagResourcesRequest).Return(nil, throttlingErr)
+
+		_, err := client.TagResources(tagResourcesRequest)
+		Expect(err).To(Equal(throttlingErr))
+	})
+
+	It("should retry throttled VPC calls", func() {
+		mockVPCClient := mockclient.NewMockVPCClient(ctrl)
+		vpcClient := NewRetryingVPCClient(context.Background(), mockVPCClient, backoff)
+		describeVSwitchesRequest := &vpc.DescribeVSwitchesRequest{VSwitchId: tea.String("vsw-1")}
+		describeVSwitchesResponse := &vpc.DescribeVSwitchesResponse{}
+		gomock.InOrder(
+			mockVPCClient.EXPECT().DescribeVSwitches(describeVSwitchesRequest).Return(nil, throttlingErr),
+			mockVPCClient.EXPECT().DescribeVSwitches(describeVSwitchesRequest).Return(describeVSwitchesResponse, nil),
+		)
+
+		Expect(vpcClient.DescribeVSwitches(describeVSwitchesRequest)).To(Equal(describeVSwitchesResponse))
+	})
+
+	It("should stop retrying once the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		client = NewRetryingECSClient(ctx, mockECSClient, wait.Backoff{Duration: time.Hour, Steps: 3})
+		mockECSClient.EXPECT().DescribeInstances(describeInstancesRequest).DoAndReturn(func(_ *ecs.DescribeInstancesRequest) (*ecs.DescribeInstancesResponse, error) {
+			cancel()
+			return nil, throttlingErr
+		})
+
+		_, err := client.DescribeInstances(describeInstancesRequest)
+		Expect(err).To(Equal(throttlingErr))
+	})
+})
@@ -7,19 +7,21 @@ package spi
 import (
 	"encoding/base64"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 
 	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
 	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
 	"github.com/alibabacloud-go/tea/tea"
-	"github.com/google/uuid"
 
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
 	corev1 "k8s.io/api/core/v1"
 
 	api "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/apis"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/vpc"
 )
 
 const (
@@ -48,6 +50,13 @@ type ECSClient interface {
 	DeleteDisk(request *ecs.DeleteDiskRequest) (*ecs.DeleteDiskResponse, error)
 	DescribeNetworkInterfaces(request *ecs.DescribeNetworkInterfacesRequest) (*ecs.DescribeNetworkInterfacesResponse, error)
 	DeleteNetworkInterface(request *ecs.DeleteNetworkInterfaceRequest) (*ecs.DeleteNetworkInterfaceResponse, error)
+	TagResources(request *ecs.TagResourcesRequest) (*ecs.TagResourcesResponse, error)
+	AssignIpv6Addresses(request *ecs.AssignIpv6AddressesRequest) (*ecs.AssignIpv6AddressesResponse, error)
+}
+
+// VPCClient provides an interface to the VPC API, which describes the vSwitches of network interfaces
+type VPCClient interface {
+	DescribeVSwitches(request *vpc.DescribeVSwitchesRequest) (*vpc.DescribeVSwitchesResponse, error)
 }
 
 // PluginSPI provides an interface to deal with cloud provider session
@@ -55,25 +64,72 @@ type ECSClient interface {
 // You can use it to mock cloud provider calls
 type PluginSPI interface {
 	NewECSClient(secret *corev1.Secret, region string) (ECSClient, error)
-	NewRunInstancesRequest(providerSpec *api.ProviderSpec, machineName string, userData []byte) (*ecs.RunInstancesRequest, error)
+	NewVPCClient(secret *corev1.Secret, region string) (VPCClient, error)
+	NewRunInstancesRequest(providerSpec *api.ProviderSpec, machineName, clientToken string, userData []byte) (*ecs.RunInstancesRequest, error)
 	NewDescribeInstancesRequest(machineName, instanceID, regionID string, tags map[string]string) (*ecs.DescribeInstancesRequest, error)
 	NewDeleteInstanceRequest(instanceID string, force bool) (*ecs.DeleteInstanceRequest, error)
 	NewInstanceDataDisks(disks []api.AlicloudDataDisk, machineName string) []*ecs.RunInstancesRequestDataDisk
+	NewInstanceNetworkInterfaces(networkInterfaces []api.AlicloudNetworkInterface, machineName string) []*ecs.RunInstancesRequestNetworkInterface
 	NewRunInstanceTags(tags map[string]string) ([]*ecs.RunInstancesRequestTag, error)
+	NewTagResourcesRequest(instanceID, regionID string, tags map[string]string) (*ecs.TagResourcesRequest, error)
+	NewTagNetworkInterfacesRequest(networkInterfaceIDs []string, regionID string, tags map[string]string) (*ecs.TagResourcesRequest, error)
+	NewDescribeDisksRequest(diskName, regionID string, tags map[string]string) (*ecs.DescribeDisksRequest, error)
+	NewDeleteDiskRequest(diskID string) (*ecs.DeleteDiskRequest, error)
+	NewDescribeNetworkInterfacesRequest(networkInterfaceIDs []string, regionID string) (*ecs.DescribeNetworkInterfacesRequest, error)
+	NewDescribeNetworkInterfacesByNameRequest(networkInterfaceName, regionID string, tags map[string]string) (*ecs.DescribeNetworkInterfacesRequest, error)
+	NewDeleteNetworkInterfaceRequest(networkInterfaceID, regionID string) (*ecs.DeleteNetworkInterfaceRequest, error)
+	NewAssignIpv6AddressesRequest(networkInterfaceID, regionID string, ipv6AddressCount *int, ipv6Addresses []string) (*ecs.AssignIpv6AddressesRequest, error)
+	NewDescribeVSwitchesRequest(vSwitchID, regionID string) (*vpc.DescribeVSwitchesRequest, error)
 }
 
 // PluginSPIImpl is the real implementation of SPI interface that makes the calls to the provider SDK.
-type PluginSPIImpl struct{}
+type PluginSPIImpl struct {
+	// ClientOptions configure how ECS and VPC clients connect to the APIs, the endpoint settings can be overridden per
+	// secret
+	ClientOptions ClientOptions
 
-// NewECSClient returns a new instance of the ECS client.
+	clients    clientCache[ECSClient]
+	vpcClients clientCache[VPCClient]
+}
+
+// NewECSClient returns the ECS client for the credentials of the given secret and the region, which records metrics of
+// its calls. Clients are cached and reused by subsequent calls as long as the credentials do not change.
 func (pluginSPI *PluginSPIImpl) NewECSClient(secret *corev1.Secret, region string) (ECSClient, error) {
-	accessKeyID := extractCredentialsFromData(secret.Data, AlicloudAccessKeyID, AlicloudAlternativeAccessKeyID)
-	accessKeySecret := extractCredentialsFromData(secret.Data, AlicloudAccessKeySecret, AlicloudAlternativeAccessKeySecret)
-	ecsClient, err := ecs.NewClient(&openapi.Config{
-		RegionId:        &region,
-		AccessKeyId:     &accessKeyID,
-		AccessKeySecret: &accessKeySecret,
+	return pluginSPI.clients.get(secret, region, func() (ECSClient, error) {
+		client, err := newECSClient(secret, region, pluginSPI.ClientOptions.forSecret(secret))
+		if err != nil {
+			return nil, err
+		}
+		return newInstrumentedECSClient(client, region), nil
+	})
+}
+
+// NewVPCClient returns the VPC client for the credentials of the given secret and the region, which records metrics of
+// its calls. Clients are cached like ECS clients.
+func (pluginSPI *PluginSPIImpl) NewVPCClient(secret *corev1.Secret, region string) (VPCClient, error) {
+	return pluginSPI.vpcClients.get(secret, region, func() (VPCClient, error) {
+		client, err := newVPCClient(secret, region, pluginSPI.ClientOptions.forSecret(secret))
+		if err != nil {
+			return nil, err
+		}
+		return newInstrumentedVPCClient(client, region), nil
 	})
+}
+
+// newECSClient returns a new instance of the ECS client.
+func newECSClient(secret *corev1.Secret, region string, options ClientOptions) (*ecs.Client, error) {
+	credential, err := newCredential(secret, region, options)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	config := &openapi.Config{
+		RegionId:   &region,
+		Credential: credential,
+	}
+	options.applyTo(config, region)
+
+	ecsClient, err := ecs.NewClient(config)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -81,8 +137,30 @@ func (pluginSPI *PluginSPIImpl) NewECSClient(secret *corev1.Secret, region strin
 	return ecsClient, err
 }
 
-// NewRunInstancesRequest returns a new request of run instance.
-func (pluginSPI *PluginSPIImpl) NewRunInstancesRequest(providerSpec *api.ProviderSpec, machineName string, userData []byte) (*ecs.RunInstancesRequest, error) {
+// newVPCClient returns a new instance of the VPC client.
+func newVPCClient(secret *corev1.Secret, region string, options ClientOptions) (*vpc.Client, error) {
+	credential, err := newCredential(secret, region, options)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	config := &openapi.Config{
+		RegionId:   &region,
+		Credential: credential,
+	}
+	options.applyToVPC(config, region)
+
+	client, err := vpc.NewClient(config)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return client, nil
+}
+
+// NewRunInstancesRequest returns a new request of run instance. The clientToken makes the request idempotent, i.e.
+// ECS does not launch another instance when a request with the same token is sent again.
+func (pluginSPI *PluginSPIImpl) NewRunInstancesRequest(providerSpec *api.ProviderSpec, machineName, clientToken string, userData []byte) (*ecs.RunInstancesRequest, error) {
 
 	request := ecs.RunInstancesRequest{
 		ImageId:            &providerSpec.ImageID,
@@ -107,11 +185,37 @@ func (pluginSPI *PluginSPIImpl) NewRunInstancesRequest(providerSpec *api.Provide
 		request.InternetMaxBandwidthOut = tea.Int32(int32(*providerSpec.InternetMaxBandwidthOut)) // #nosec  G115 (CWE-190) -- valid values are 0-100. This cannot cause an overflow.
 	}
 
+	if providerSpec.SpotPriceLimit != nil {
+		request.SpotPriceLimit = tea.Float32(float32(*providerSpec.SpotPriceLimit))
+	}
+	if providerSpec.SpotDuration != nil {
+		request.SpotDuration = tea.Int32(int32(*providerSpec.SpotDuration)) // #nosec  G115 (CWE-190) -- valid values are 0-6. This cannot cause an overflow.
+	}
+	if providerSpec.SpotInterruptionBehavior != "" {
+		request.SpotInterruptionBehavior = &providerSpec.SpotInterruptionBehavior
+	}
+
+	if providerSpec.IPv6AddressCount != nil {
+		request.Ipv6AddressCount = tea.Int32(int32(*providerSpec.IPv6AddressCount)) // #nosec  G115 (CWE-190) -- valid values are 1-10. This cannot cause an overflow.
+	}
+	if len(providerSpec.IPv6Addresses) > 0 {
+		request.Ipv6Address = tea.StringSlice(providerSpec.IPv6Addresses)
+	}
+
 	if len(providerSpec.DataDisks) > 0 {
 		dataDisks := pluginSPI.NewInstanceDataDisks(providerSpec.DataDisks, machineName)
 		request.DataDisk = dataDisks
 	}
 
+	if len(providerSpec.NetworkInterfaces) > 0 {
+		request.NetworkInterface = pluginSPI.NewInstanceNetworkInterfaces(providerSpec.NetworkInterfaces, machineName)
+		if providerSpec.NetworkInterfaces[0].Type == api.NetworkInterfaceTypePrimary {
+			// ECS rejects requests configuring the primary network interface twice
+			request.SecurityGroupId, request.VSwitchId, request.PrivateIpAddress = nil, nil, nil
+			request.Ipv6AddressCount, request.Ipv6Address = nil, nil
+		}
+	}
+
 	if providerSpec.SystemDisk != nil {
 		if request.SystemDisk == nil {
 			request.SystemDisk = &ecs.RunInstancesRequestSystemDisk{}
@@ -126,13 +230,15 @@ func (pluginSPI *PluginSPIImpl) NewRunInstancesRequest(providerSpec *api.Provide
 	}
 	request.Tag = tags
 	request.InstanceName = &machineName
-	request.ClientToken = tea.String(uuid.NewString())
+	request.ClientToken = &clientToken
 	request.UserData = tea.String(base64.StdEncoding.EncodeToString(userData))
 
 	return &request, nil
 }
 
-// NewDescribeInstancesRequest returns a new request of describe instance.
+// NewDescribeInstancesRequest returns a new request of describe instance. Instances are looked up by instance ID, by
+// machine name or by the cluster and role tags. Lookups by machine name are filtered by the cluster and role tags as
+// well, so that instances of the same name in other clusters are not found.
 func (pluginSPI *PluginSPIImpl) NewDescribeInstancesRequest(machineName, instanceID, regionID string, tags map[string]string) (*ecs.DescribeInstancesRequest, error) {
 	request := ecs.DescribeInstancesRequest{}
 
@@ -144,29 +250,35 @@ func (pluginSPI *PluginSPIImpl) NewDescribeInstancesRequest(machineName, instanc
 		request.InstanceIds = tea.String("[\"" + instanceID + "\"]")
 	} else if machineName != "" {
 		request.InstanceName = &machineName
+		request.Tag = newDescribeInstancesRequestTags(tags)
 	} else {
-		searchFilters := make(map[string]string)
-		for k, v := range tags {
-			if strings.Contains(k, "kubernetes.io/cluster/") || strings.Contains(k, "kubernetes.io/role/") {
-				searchFilters[k] = v
-			}
-		}
-
-		if len(searchFilters) < 2 {
+		request.Tag = newDescribeInstancesRequestTags(tags)
+		if len(request.Tag) < 2 {
 			return nil, fmt.Errorf("can't find VMs with none of machineID/Tag[kubernetes.io/cluster/*]/Tag[kubernetes.io/role/*]")
 		}
+	}
+
+	return &request, nil
+}
 
-		var tags []*ecs.DescribeInstancesRequestTag
-		for k, v := range searchFilters {
-			tags = append(tags, &ecs.DescribeInstancesRequestTag{
-				Key:   &k,
-				Value: &v,
-			})
+// newDescribeInstancesRequestTags returns the tag filters of describe instance for the cluster and role tags among the
+// given tags.
+func newDescribeInstancesRequestTags(tags map[string]string) []*ecs.DescribeInstancesRequestTag {
+	searchFilters := make(map[string]string)
+	for k, v := range tags {
+		if strings.HasPrefix(k, "kubernetes.io/cluster/") || strings.HasPrefix(k, "kubernetes.io/role/") {
+			searchFilters[k] = v
 		}
-		request.Tag = tags
 	}
 
-	return &request, nil
+	var requestTags []*ecs.DescribeInstancesRequestTag
+	for _, k := range slices.Sorted(maps.Keys(searchFilters)) {
+		requestTags = append(requestTags, &ecs.DescribeInstancesRequestTag{
+			Key:   &k,
+			Value: tea.String(searchFilters[k]),
+		})
+	}
+	return requestTags
 }
 
 // NewDeleteInstanceRequest returns a new request of delete instance.
@@ -188,15 +300,11 @@ func (pluginSPI *PluginSPIImpl) NewInstanceDataDisks(disks []api.AlicloudDataDis
 		instanceDataDisk := ecs.RunInstancesRequestDataDisk{
 			Category:    &disk.Category,
 			Encrypted:   tea.String(strconv.FormatBool(disk.Encrypted)),
-			DiskName:    tea.String(fmt.Sprintf("%s-%s-data-disk", machineName, disk.Name)),
+			DiskName:    tea.String(DataDiskName(machineName, disk.Name)),
 			Description: tea.String(disk.Description),
 			Size:        tea.Int32(int32(disk.Size)), // #nosec  G115 (CWE-190) -- disk size unit is GB and will not exceed MaxInt32
-		}
-
-		if disk.DeleteWithInstance != nil {
-			instanceDataDisk.DeleteWithInstance = disk.DeleteWithInstance
-		} else {
-			instanceDataDisk.DeleteWithInstance = tea.Bool(true)
+			// defaulted to true by the ProviderSpec defaulting functions
+			DeleteWithInstance: disk.DeleteWithInstance,
 		}
 
 		if disk.Category == "DiskEphemeralSSD" {
@@ -209,18 +317,54 @@ func (pluginSPI *PluginSPIImpl) NewInstanceDataDisks(disks []api.AlicloudDataDis
 	return instanceDataDisks
 }
 
+// NewInstanceNetworkInterfaces returns the network interfaces of instances. Secondary network interfaces are released
+// together with the instance. ECS assigns IPv6 addresses at launch to the primary network interface only, those of the
+// secondary network interfaces are assigned once they are attached with NewAssignIpv6AddressesRequest.
+func (pluginSPI *PluginSPIImpl) NewInstanceNetworkInterfaces(networkInterfaces []api.AlicloudNetworkInterface, machineName string) []*ecs.RunInstancesRequestNetworkInterface {
+	var instanceNetworkInterfaces []*ecs.RunInstancesRequestNetworkInterface
+
+	for _, networkInterface := range networkInterfaces {
+		instanceNetworkInterface := ecs.RunInstancesRequestNetworkInterface{
+			InstanceType:         tea.String(networkInterface.Type),
+			NetworkInterfaceName: tea.String(NetworkInterfaceName(machineName, networkInterface.Name)),
+			VSwitchId:            tea.String(networkInterface.VSwitchID),
+			SecurityGroupIds:     tea.StringSlice(networkInterface.SecurityGroupIDs),
+			Description:          tea.String(networkInterface.Description),
+		}
+
+		if networkInterface.QueueNumber != nil {
+			instanceNetworkInterface.QueueNumber = tea.Int32(int32(*networkInterface.QueueNumber)) // #nosec  G115 (CWE-190) -- the queue number is limited by the instance type and will not exceed MaxInt32
+		}
+		if networkInterface.Type != api.NetworkInterfaceTypePrimary {
+			instanceNetworkInterface.DeleteOnRelease = tea.Bool(true)
+		} else {
+			if networkInterface.IPv6AddressCount != nil {
+				instanceNetworkInterface.Ipv6AddressCount = tea.Int64(int64(*networkInterface.IPv6AddressCount))
+			}
+			if len(networkInterface.IPv6Addresses) > 0 {
+				instanceNetworkInterface.Ipv6Address = tea.StringSlice(networkInterface.IPv6Addresses)
+			}
+		}
+
+		instanceNetworkInterfaces = append(instanceNetworkInterfaces, &instanceNetworkInterface)
+	}
+
+	return instanceNetworkInterfaces
+}
+
 // NewRunInstanceTags returns tags of Running Instances.
 func (pluginSPI *PluginSPIImpl) NewRunInstanceTags(tags map[string]string) ([]*ecs.RunInstancesRequestTag, error) {
 	runInstancesTags := make([]*ecs.RunInstancesRequestTag, 0, 2)
 	hasCluster, hasRole := false, false
 
-	for k, v := range tags {
-		if strings.Contains(k, "kubernetes.io/cluster/") {
+	// the tags are sorted by key, so that the same tags always result in the same request
+	for _, k := range slices.Sorted(maps.Keys(tags)) {
+		if strings.HasPrefix(k, "kubernetes.io/cluster/") {
 			hasCluster = true
-		} else if strings.Contains(k, "kubernetes.io/role/") {
+		} else if strings.HasPrefix(k, "kubernetes.io/role/") {
 			hasRole = true
 		}
-		runInstancesTags = append(runInstancesTags, &ecs.RunInstancesRequestTag{Key: &k, Value: &v})
+		runInstancesTags = append(runInstancesTags, &ecs.RunInstancesRequestTag{Key: &k, Value: tea.String(tags[k])})
 	}
 
 	if !hasCluster || !hasRole {
@@ -231,6 +375,179 @@ func (pluginSPI *PluginSPIImpl) NewRunInstanceTags(tags map[string]string) ([]*e
 	return runInstancesTags, nil
 }
 
+// NewTagResourcesRequest returns a new request adding the given tags to an instance.
+func (pluginSPI *PluginSPIImpl) NewTagResourcesRequest(instanceID, regionID string, tags map[string]string) (*ecs.TagResourcesRequest, error) {
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no tags given for instance %q", instanceID)
+	}
+
+	request := ecs.TagResourcesRequest{
+		RegionId:     &regionID,
+		ResourceType: tea.String("instance"),
+		ResourceId:   []*string{&instanceID},
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(tags)) {
+		request.Tag = append(request.Tag, &ecs.TagResourcesRequestTag{Key: &k, Value: tea.String(tags[k])})
+	}
+
+	return &request, nil
+}
+
+// NewTagNetworkInterfacesRequest returns a new request adding the cluster tags of the given tags to network interfaces.
+// ECS adds the tags of RunInstances to the primary network interface only, the secondary ones have to be tagged
+// afterwards to be found by NewDescribeNetworkInterfacesByNameRequest.
+func (pluginSPI *PluginSPIImpl) NewTagNetworkInterfacesRequest(networkInterfaceIDs []string, regionID string, tags map[string]string) (*ecs.TagResourcesRequest, error) {
+	if len(networkInterfaceIDs) == 0 {
+		return nil, fmt.Errorf("no network interface IDs given")
+	}
+	clusterTagKeys := clusterTagKeys(tags)
+	if len(clusterTagKeys) == 0 {
+		return nil, fmt.Errorf("no tag prefixed with kubernetes.io/cluster/ given for network interfaces %v", networkInterfaceIDs)
+	}
+
+	request := ecs.TagResourcesRequest{
+		RegionId:     &regionID,
+		ResourceType: tea.String("eni"),
+		ResourceId:   tea.StringSlice(networkInterfaceIDs),
+	}
+
+	for _, k := range clusterTagKeys {
+		request.Tag = append(request.Tag, &ecs.TagResourcesRequestTag{Key: &k, Value: tea.String(tags[k])})
+	}
+
+	return &request, nil
+}
+
+// clusterTagKeys returns the sorted keys of the given tags identifying the cluster, disks and network interfaces are
+// filtered by them, so that resources of other clusters with the same name are never touched.
+func clusterTagKeys(tags map[string]string) []string {
+	var keys []string
+	for _, k := range slices.Sorted(maps.Keys(tags)) {
+		if strings.HasPrefix(k, "kubernetes.io/cluster/") {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// NewDescribeDisksRequest returns a new request of describe disks filtered by disk name and the cluster tags.
+func (pluginSPI *PluginSPIImpl) NewDescribeDisksRequest(diskName, regionID string, tags map[string]string) (*ecs.DescribeDisksRequest, error) {
+	if diskName == "" {
+		return nil, fmt.Errorf("no disk name given")
+	}
+
+	request := ecs.DescribeDisksRequest{
+		RegionId: &regionID,
+		DiskName: &diskName,
+	}
+
+	for _, k := range clusterTagKeys(tags) {
+		request.Tag = append(request.Tag, &ecs.DescribeDisksRequestTag{Key: &k, Value: tea.String(tags[k])})
+	}
+
+	return &request, nil
+}
+
+// NewDeleteDiskRequest returns a new request of delete disk.
+func (pluginSPI *PluginSPIImpl) NewDeleteDiskRequest(diskID string) (*ecs.DeleteDiskRequest, error) {
+	request := ecs.DeleteDiskRequest{
+		DiskId: &diskID,
+	}
+
+	return &request, nil
+}
+
+// NewDescribeNetworkInterfacesRequest returns a new request of describe network interfaces filtered by their IDs.
+func (pluginSPI *PluginSPIImpl) NewDescribeNetworkInterfacesRequest(networkInterfaceIDs []string, regionID string) (*ecs.DescribeNetworkInterfacesRequest, error) {
+	if len(networkInterfaceIDs) == 0 {
+		return nil, fmt.Errorf("no network interface IDs given")
+	}
+
+	request := ecs.DescribeNetworkInterfacesRequest{
+		RegionId:           &regionID,
+		NetworkInterfaceId: tea.StringSlice(networkInterfaceIDs),
+	}
+
+	return &request, nil
+}
+
+// NewDescribeNetworkInterfacesByNameRequest returns a new request of describe network interfaces filtered by name and
+// the cluster tags.
+func (pluginSPI *PluginSPIImpl) NewDescribeNetworkInterfacesByNameRequest(networkInterfaceName, regionID string, tags map[string]string) (*ecs.DescribeNetworkInterfacesRequest, error) {
+	if networkInterfaceName == "" {
+		return nil, fmt.Errorf("no network interface name given")
+	}
+
+	request := ecs.DescribeNetworkInterfacesRequest{
+		RegionId:             &regionID,
+		NetworkInterfaceName: &networkInterfaceName,
+	}
+
+	for _, k := range clusterTagKeys(tags) {
+		request.Tag = append(request.Tag, &ecs.DescribeNetworkInterfacesRequestTag{Key: &k, Value: tea.String(tags[k])})
+	}
+
+	return &request, nil
+}
+
+// NewDeleteNetworkInterfaceRequest returns a new request of delete network interface.
+func (pluginSPI *PluginSPIImpl) NewDeleteNetworkInterfaceRequest(networkInterfaceID, regionID string) (*ecs.DeleteNetworkInterfaceRequest, error) {
+	request := ecs.DeleteNetworkInterfaceRequest{
+		RegionId:           &regionID,
+		NetworkInterfaceId: &networkInterfaceID,
+	}
+
+	return &request, nil
+}
+
+// NewAssignIpv6AddressesRequest returns a new request assigning the given number of IPv6 addresses or the given IPv6
+// addresses to a network interface.
+func (pluginSPI *PluginSPIImpl) NewAssignIpv6AddressesRequest(networkInterfaceID, regionID string, ipv6AddressCount *int, ipv6Addresses []string) (*ecs.AssignIpv6AddressesRequest, error) {
+	if ipv6AddressCount == nil && len(ipv6Addresses) == 0 {
+		return nil, fmt.Errorf("no IPv6 addresses given for network interface %q", networkInterfaceID)
+	}
+
+	request := ecs.AssignIpv6AddressesRequest{
+		RegionId:           &regionID,
+		NetworkInterfaceId: &networkInterfaceID,
+	}
+
+	if ipv6AddressCount != nil {
+		request.Ipv6AddressCount = tea.Int32(int32(*ipv6AddressCount)) // #nosec  G115 (CWE-190) -- valid values are 1-10. This cannot cause an overflow.
+	}
+	if len(ipv6Addresses) > 0 {
+		request.Ipv6Address = tea.StringSlice(ipv6Addresses)
+	}
+
+	return &request, nil
+}
+
+// NewDescribeVSwitchesRequest returns a new request of describe vSwitches filtered by vSwitch ID.
+func (pluginSPI *PluginSPIImpl) NewDescribeVSwitchesRequest(vSwitchID, regionID string) (*vpc.DescribeVSwitchesRequest, error) {
+	if vSwitchID == "" {
+		return nil, fmt.Errorf("no vSwitch ID given")
+	}
+
+	request := vpc.DescribeVSwitchesRequest{
+		RegionId:  &regionID,
+		VSwitchId: &vSwitchID,
+	}
+
+	return &request, nil
+}
+
+// DataDiskName returns the name of the ECS disk created for the data disk with the given name of a machine.
+func DataDiskName(machineName, diskName string) string {
+	return fmt.Sprintf("%s-%s-data-disk", machineName, diskName)
+}
+
+// NetworkInterfaceName returns the name of the ECS network interface created for the network interface with the given
+// name of a machine.
+func NetworkInterfaceName(machineName, networkInterfaceName string) string {
+	return fmt.Sprintf("%s-%s-eni", machineName, networkInterfaceName)
+}
+
 // extractCredentialsFromData extracts and trims a value from the given data map. The first key that exists is being
 // returned, otherwise, the next key is tried, etc. If no key exists then an empty string is returned.
 func extractCredentialsFromData(data map[string][]byte, keys ...string) string {
@@ -72,8 +72,9 @@ var _ = Describe("Plugin SPI", func() {
 	)
 
 	It("should generate request of running instance", func() {
-		request, err := pluginSPI.NewRunInstancesRequest(providerSpec, machineName, userData)
+		request, err := pluginSPI.NewRunInstancesRequest(providerSpec, machineName, "plugin-test-client-token", userData)
 		Expect(err).To(BeNil())
+		Expect(*request.ClientToken).To(Equal("plugin-test-client-token"))
 		Expect(*request.SystemDisk.Category).To(Equal("cloud_efficiency"))
 		Expect(*request.SystemDisk.Size).To(Equal("50"))
 		Expect(request.DataDisk).To(BeNil())
@@ -89,6 +90,64 @@ var _ = Describe("Plugin SPI", func() {
 		))
 	})
 
+	It("should generate request of running instance with network interfaces", func() {
+		spec := *providerSpec
+		spec.NetworkInterfaces = []api.AlicloudNetworkInterface{
+			{Name: "storage", Type: api.NetworkInterfaceTypeSecondary, VSwitchID: "vsw-storage", SecurityGroupIDs: []string{"sg-storage"}},
+		}
+		request, err := pluginSPI.NewRunInstancesRequest(&spec, machineName, "plugin-test-client-token", userData)
+		Expect(err).To(BeNil())
+		Expect(*request.VSwitchId).To(Equal("vsw-uf6s1fjxxks65rk1tkrpm"))
+		Expect(*request.SecurityGroupId).To(Equal("sg-uf69t4txlz6r18ybzxbx"))
+		Expect(request.NetworkInterface).To(HaveLen(1))
+		Expect(request.Ipv6AddressCount).To(BeNil())
+		Expect(request.Ipv6Address).To(BeNil())
+
+		spec.IPv6AddressCount = pointer.Int(2)
+		request, err = pluginSPI.NewRunInstancesRequest(&spec, machineName, "plugin-test-client-token", userData)
+		Expect(err).To(BeNil())
+		Expect(request.Ipv6AddressCount).To(Equal(tea.Int32(2)))
+
+		spec.IPv6AddressCount = nil
+		spec.VSwitchID, spec.SecurityGroupID = "", ""
+		spec.NetworkInterfaces = append([]api.AlicloudNetworkInterface{
+			{Name: "primary", Type: api.NetworkInterfaceTypePrimary, VSwitchID: "vsw-primary", SecurityGroupIDs: []string{"sg-primary"}},
+		}, spec.NetworkInterfaces...)
+		request, err = pluginSPI.NewRunInstancesRequest(&spec, machineName, "plugin-test-client-token", userData)
+		Expect(err).To(BeNil())
+		Expect(request.VSwitchId).To(BeNil())
+		Expect(request.SecurityGroupId).To(BeNil())
+		Expect(request.PrivateIpAddress).To(BeNil())
+		Expect(request.NetworkInterface).To(HaveLen(2))
+		Expect(*request.NetworkInterface[0].InstanceType).To(Equal("Primary"))
+		Expect(*request.NetworkInterface[1].InstanceType).To(Equal("Secondary"))
+	})
+
+	It("should generate request of running spot instance", func() {
+		spec := *providerSpec
+		request, err := pluginSPI.NewRunInstancesRequest(&spec, machineName, "plugin-test-client-token", userData)
+		Expect(err).To(BeNil())
+		Expect(request.SpotPriceLimit).To(BeNil())
+		Expect(request.SpotDuration).To(BeNil())
+		Expect(request.SpotInterruptionBehavior).To(BeNil())
+
+		spec.SpotStrategy = api.SpotStrategyWithPriceLimit
+		spec.SpotPriceLimit = pointer.Float64(0.5)
+		spec.SpotDuration = pointer.Int(0)
+		spec.SpotInterruptionBehavior = "Stop"
+		request, err = pluginSPI.NewRunInstancesRequest(&spec, machineName, "plugin-test-client-token", userData)
+		Expect(err).To(BeNil())
+		Expect(*request.SpotStrategy).To(Equal("SpotWithPriceLimit"))
+		Expect(request.SpotPriceLimit).To(Equal(tea.Float32(0.5)))
+		Expect(request.SpotDuration).To(Equal(tea.Int32(0)))
+		Expect(request.SpotInterruptionBehavior).To(Equal(tea.String("Stop")))
+	})
+
+	It("should reject run instance tags not prefixed by the cluster and role prefixes", func() {
+		_, err := pluginSPI.NewRunInstanceTags(map[string]string{"foo/kubernetes.io/cluster/shoot--mcm": "1", "foo/kubernetes.io/role/node": "1"})
+		Expect(err).To(HaveOccurred())
+	})
+
 	It("should generate request of describing instance by machine Name", func() {
 		request, err := pluginSPI.NewDescribeInstancesRequest(machineName, "", "", nil)
 		Expect(err).To(BeNil())
@@ -97,6 +156,23 @@ var _ = Describe("Plugin SPI", func() {
 		Expect(request.Tag).To(BeNil())
 	})
 
+	It("should generate request of describing instance by machine name filtered by the cluster and role tags", func() {
+		request, err := pluginSPI.NewDescribeInstancesRequest(machineName, "", "", providerSpec.Tags)
+		Expect(err).To(BeNil())
+		Expect(*request.InstanceName).To(Equal("plugin-test-machine"))
+		Expect(request.InstanceIds).To(BeNil())
+		Expect(request.Tag).To(ConsistOf(
+			&ecs.DescribeInstancesRequestTag{
+				Key:   tea.String("kubernetes.io/cluster/shoot--mcm"),
+				Value: tea.String("1"),
+			},
+			&ecs.DescribeInstancesRequestTag{
+				Key:   tea.String("kubernetes.io/role/worker/shoot--mcm"),
+				Value: tea.String("1"),
+			},
+		))
+	})
+
 	It("should generate request of describing instance by provider ID", func() {
 		request, err := pluginSPI.NewDescribeInstancesRequest("", instanceID, "", nil)
 		Expect(err).To(BeNil())
@@ -129,6 +205,84 @@ var _ = Describe("Plugin SPI", func() {
 		Expect(*request.Force).To(Equal(true))
 	})
 
+	It("should generate request of tagging an instance", func() {
+		request, err := pluginSPI.NewTagResourcesRequest(instanceID, "cn-shanghai", map[string]string{"foo": "bar"})
+		Expect(err).To(BeNil())
+		Expect(*request.RegionId).To(Equal("cn-shanghai"))
+		Expect(*request.ResourceType).To(Equal("instance"))
+		Expect(request.ResourceId).To(ConsistOf(tea.String(instanceID)))
+		Expect(request.Tag).To(ConsistOf(&ecs.TagResourcesRequestTag{
+			Key:   tea.String("foo"),
+			Value: tea.String("bar"),
+		}))
+
+		_, err = pluginSPI.NewTagResourcesRequest(instanceID, "cn-shanghai", nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should generate request of tagging network interfaces with the cluster tags", func() {
+		request, err := pluginSPI.NewTagNetworkInterfacesRequest([]string{"eni-1", "eni-2"}, "cn-shanghai", providerSpec.Tags)
+		Expect(err).To(BeNil())
+		Expect(*request.ResourceType).To(Equal("eni"))
+		Expect(request.ResourceId).To(Equal(tea.StringSlice([]string{"eni-1", "eni-2"})))
+		Expect(request.Tag).To(ConsistOf(&ecs.TagResourcesRequestTag{Key: tea.String("kubernetes.io/cluster/shoot--mcm"), Value: tea.String("1")}))
+
+		_, err = pluginSPI.NewTagNetworkInterfacesRequest(nil, "cn-shanghai", providerSpec.Tags)
+		Expect(err).To(HaveOccurred())
+		_, err = pluginSPI.NewTagNetworkInterfacesRequest([]string{"eni-1"}, "cn-shanghai", map[string]string{"foo": "bar"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should generate requests of describing and deleting data disks", func() {
+		describeRequest, err := pluginSPI.NewDescribeDisksRequest(DataDiskName(machineName, "disk-1"), "cn-shanghai", providerSpec.Tags)
+		Expect(err).To(BeNil())
+		Expect(*describeRequest.RegionId).To(Equal("cn-shanghai"))
+		Expect(*describeRequest.DiskName).To(Equal("plugin-test-machine-disk-1-data-disk"))
+		Expect(describeRequest.Tag).To(ConsistOf(&ecs.DescribeDisksRequestTag{Key: tea.String("kubernetes.io/cluster/shoot--mcm"), Value: tea.String("1")}))
+
+		_, err = pluginSPI.NewDescribeDisksRequest("", "cn-shanghai", providerSpec.Tags)
+		Expect(err).To(HaveOccurred())
+
+		deleteRequest, err := pluginSPI.NewDeleteDiskRequest("d-disk1")
+		Expect(err).To(BeNil())
+		Expect(*deleteRequest.DiskId).To(Equal("d-disk1"))
+	})
+
+	It("should generate requests of describing and deleting network interfaces", func() {
+		describeRequest, err := pluginSPI.NewDescribeNetworkInterfacesRequest([]string{"eni-1", "eni-2"}, "cn-shanghai")
+		Expect(err).To(BeNil())
+		Expect(*describeRequest.RegionId).To(Equal("cn-shanghai"))
+		Expect(describeRequest.NetworkInterfaceId).To(ConsistOf(tea.String("eni-1"), tea.String("eni-2")))
+
+		_, err = pluginSPI.NewDescribeNetworkInterfacesRequest(nil, "cn-shanghai")
+		Expect(err).To(HaveOccurred())
+
+		describeRequest, err = pluginSPI.NewDescribeNetworkInterfacesByNameRequest(NetworkInterfaceName(machineName, "storage"), "cn-shanghai", providerSpec.Tags)
+		Expect(err).To(BeNil())
+		Expect(*describeRequest.RegionId).To(Equal("cn-shanghai"))
+		Expect(*describeRequest.NetworkInterfaceName).To(Equal("plugin-test-machine-storage-eni"))
+		Expect(describeRequest.NetworkInterfaceId).To(BeNil())
+		Expect(describeRequest.Tag).To(ConsistOf(&ecs.DescribeNetworkInterfacesRequestTag{Key: tea.String("kubernetes.io/cluster/shoot--mcm"), Value: tea.String("1")}))
+
+		_, err = pluginSPI.NewDescribeNetworkInterfacesByNameRequest("", "cn-shanghai", providerSpec.Tags)
+		Expect(err).To(HaveOccurred())
+
+		deleteRequest, err := pluginSPI.NewDeleteNetworkInterfaceRequest("eni-1", "cn-shanghai")
+		Expect(err).To(BeNil())
+		Expect(*deleteRequest.RegionId).To(Equal("cn-shanghai"))
+		Expect(*deleteRequest.NetworkInterfaceId).To(Equal("eni-1"))
+	})
+
+	It("should generate requests of describing vSwitches", func() {
+		describeRequest, err := pluginSPI.NewDescribeVSwitchesRequest("vsw-1", "cn-shanghai")
+		Expect(err).To(BeNil())
+		Expect(*describeRequest.RegionId).To(Equal("cn-shanghai"))
+		Expect(*describeRequest.VSwitchId).To(Equal("vsw-1"))
+
+		_, err = pluginSPI.NewDescribeVSwitchesRequest("", "cn-shanghai")
+		Expect(err).To(HaveOccurred())
+	})
+
 	It("should generate instance data disks", func() {
 		dataDisks := pluginSPI.NewInstanceDataDisks(alicloudDataDisks, machineName)
 		Expect(dataDisks).NotTo(BeEmpty())
@@ -154,9 +308,62 @@ var _ = Describe("Plugin SPI", func() {
 				Encrypted:          tea.String("false"),
 				DiskName:           tea.String("plugin-test-machine-disk-3-data-disk"),
 				Size:               tea.Int32(20),
-				DeleteWithInstance: tea.Bool(true),
+				DeleteWithInstance: nil,
 				Description:        tea.String(""),
 			},
 		))
 	})
+
+	It("should generate instance network interfaces", func() {
+		networkInterfaces := pluginSPI.NewInstanceNetworkInterfaces([]api.AlicloudNetworkInterface{
+			{Name: "primary", Type: "Primary", VSwitchID: "vsw-primary", SecurityGroupIDs: []string{"sg-1", "sg-2"}, IPv6AddressCount: pointer.Int(1)},
+			{Name: "storage", Type: "Secondary", VSwitchID: "vsw-storage", SecurityGroupIDs: []string{"sg-storage"}, Description: "storage network", QueueNumber: pointer.Int(4)},
+			{Name: "multus", Type: "Secondary", VSwitchID: "vsw-multus", SecurityGroupIDs: []string{"sg-multus"}, IPv6Addresses: []string{"2408:4005:39c:8300::10"}},
+		}, machineName)
+		Expect(networkInterfaces).To(Equal([]*ecs.RunInstancesRequestNetworkInterface{
+			{
+				InstanceType:         tea.String("Primary"),
+				NetworkInterfaceName: tea.String("plugin-test-machine-primary-eni"),
+				VSwitchId:            tea.String("vsw-primary"),
+				SecurityGroupIds:     tea.StringSlice([]string{"sg-1", "sg-2"}),
+				Description:          tea.String(""),
+				Ipv6AddressCount:     tea.Int64(1),
+			},
+			{
+				InstanceType:         tea.String("Secondary"),
+				NetworkInterfaceName: tea.String("plugin-test-machine-storage-eni"),
+				VSwitchId:            tea.String("vsw-storage"),
+				SecurityGroupIds:     tea.StringSlice([]string{"sg-storage"}),
+				Description:          tea.String("storage network"),
+				QueueNumber:          tea.Int32(4),
+				DeleteOnRelease:      tea.Bool(true),
+			},
+			{
+				InstanceType:         tea.String("Secondary"),
+				NetworkInterfaceName: tea.String("plugin-test-machine-multus-eni"),
+				VSwitchId:            tea.String("vsw-multus"),
+				SecurityGroupIds:     tea.StringSlice([]string{"sg-multus"}),
+				Description:          tea.String(""),
+				DeleteOnRelease:      tea.Bool(true),
+			},
+		}))
+	})
+
+	It("should generate assign IPv6 addresses requests", func() {
+		request, err := pluginSPI.NewAssignIpv6AddressesRequest("eni-1", "cn-shanghai", pointer.Int(2), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(request).To(Equal(&ecs.AssignIpv6AddressesRequest{
+			RegionId:           tea.String("cn-shanghai"),
+			NetworkInterfaceId: tea.String("eni-1"),
+			Ipv6AddressCount:   tea.Int32(2),
+		}))
+
+		request, err = pluginSPI.NewAssignIpv6AddressesRequest("eni-1", "cn-shanghai", nil, []string{"2408:4005:39c:8300::10"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(request.Ipv6AddressCount).To(BeNil())
+		Expect(request.Ipv6Address).To(Equal(tea.StringSlice([]string{"2408:4005:39c:8300::10"})))
+
+		_, err = pluginSPI.NewAssignIpv6AddressesRequest("eni-1", "cn-shanghai", nil, nil)
+		Expect(err).To(HaveOccurred())
+	})
 })
@@ -0,0 +1,212 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spi
+
+import (
+	"context"
+	"encoding/json"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	maperror "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/errors"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/tracing"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/vpc"
+)
+
+// tracer starts the spans of the calls of an Alibaba Cloud API
+type tracer struct {
+	ctx    context.Context
+	region string
+	// api is the name of the API, which prefixes the span names
+	api string
+	// actionKey is the span attribute holding the action of the API
+	actionKey attribute.Key
+}
+
+// tracingECSClient is an ECSClient recording a span for each call
+type tracingECSClient struct {
+	tracer
+	client ECSClient
+}
+
+// NewTracingECSClient returns an ECSClient which records a span for each call of the given client as child of the
+// span in the given context. The spans carry the ECS action, the region, the IDs and names of the instances, disks,
+// network interfaces the call concerns and the ID of the request.
+func NewTracingECSClient(ctx context.Context, client ECSClient, region string) ECSClient {
+	return &tracingECSClient{
+		tracer: tracer{ctx: ctx, region: region, api: "ECS", actionKey: tracing.ECSActionKey},
+		client: client,
+	}
+}
+
+func (c *tracingECSClient) RunInstances(request *ecs.RunInstancesRequest) (*ecs.RunInstancesResponse, error) {
+	span := c.start("RunInstances", request.RegionId,
+		tracing.MachineNameKey.String(tea.StringValue(request.InstanceName)),
+		tracing.InstanceNameKey.String(tea.StringValue(request.InstanceName)),
+	)
+	return traced(span, request, func(request *ecs.RunInstancesRequest) (*ecs.RunInstancesResponse, error) {
+		response, err := c.client.RunInstances(request)
+		if response != nil && response.Body != nil && response.Body.InstanceIdSets != nil {
+			span.SetAttributes(instanceIDAttributes(tea.StringSliceValue(response.Body.InstanceIdSets.InstanceIdSet))...)
+		}
+		return response, err
+	})
+}
+
+func (c *tracingECSClient) DescribeInstances(request *ecs.DescribeInstancesRequest) (*ecs.DescribeInstancesResponse, error) {
+	attributes := instanceIDAttributes(decodeIDs(request.InstanceIds))
+	attributes = appendIfSet(attributes, tracing.InstanceNameKey, request.InstanceName)
+	span := c.start("DescribeInstances", request.RegionId, attributes...)
+	return traced(span, request, c.client.DescribeInstances)
+}
+
+func (c *tracingECSClient) DeleteInstance(request *ecs.DeleteInstanceRequest) (*ecs.DeleteInstanceResponse, error) {
+	span := c.start("DeleteInstance", nil, tracing.InstanceIDKey.String(tea.StringValue(request.InstanceId)))
+	return traced(span, request, c.client.DeleteInstance)
+}
+
+func (c *tracingECSClient) DescribeDisks(request *ecs.DescribeDisksRequest) (*ecs.DescribeDisksResponse, error) {
+	attributes := appendIfSet(nil, tracing.InstanceIDKey, request.InstanceId)
+	if diskIDs := decodeIDs(request.DiskIds); len(diskIDs) == 1 {
+		attributes = append(attributes, tracing.DiskIDKey.String(diskIDs[0]))
+	}
+	span := c.start("DescribeDisks", request.RegionId, attributes...)
+	return traced(span, request, c.client.DescribeDisks)
+}
+
+func (c *tracingECSClient) DeleteDisk(request *ecs.DeleteDiskRequest) (*ecs.DeleteDiskResponse, error) {
+	span := c.start("DeleteDisk", nil, tracing.DiskIDKey.String(tea.StringValue(request.DiskId)))
+	return traced(span, request, c.client.DeleteDisk)
+}
+
+func (c *tracingECSClient) DescribeNetworkInterfaces(request *ecs.DescribeNetworkInterfacesRequest) (*ecs.DescribeNetworkInterfacesResponse, error) {
+	attributes := appendIfSet(nil, tracing.InstanceIDKey, request.InstanceId)
+	attributes = appendIfSet(attributes, tracing.NetworkInterfaceNameKey, request.NetworkInterfaceName)
+	if len(request.NetworkInterfaceId) == 1 {
+		attributes = append(attributes, tracing.NetworkInterfaceIDKey.String(tea.StringValue(request.NetworkInterfaceId[0])))
+	}
+	span := c.start("DescribeNetworkInterfaces", request.RegionId, attributes...)
+	return traced(span, request, c.client.DescribeNetworkInterfaces)
+}
+
+func (c *tracingECSClient) DeleteNetworkInterface(request *ecs.DeleteNetworkInterfaceRequest) (*ecs.DeleteNetworkInterfaceResponse, error) {
+	span := c.start("DeleteNetworkInterface", request.RegionId, tracing.NetworkInterfaceIDKey.String(tea.StringValue(request.NetworkInterfaceId)))
+	return traced(span, request, c.client.DeleteNetworkInterface)
+}
+
+func (c *tracingECSClient) TagResources(request *ecs.TagResourcesRequest) (*ecs.TagResourcesResponse, error) {
+	span := c.start("TagResources", request.RegionId, instanceIDAttributes(tea.StringSliceValue(request.ResourceId))...)
+	return traced(span, request, c.client.TagResources)
+}
+
+func (c *tracingECSClient) AssignIpv6Addresses(request *ecs.AssignIpv6AddressesRequest) (*ecs.AssignIpv6AddressesResponse, error) {
+	span := c.start("AssignIpv6Addresses", request.RegionId, tracing.NetworkInterfaceIDKey.String(tea.StringValue(request.NetworkInterfaceId)))
+	return traced(span, request, c.client.AssignIpv6Addresses)
+}
+
+// tracingVPCClient is a VPCClient recording a span for each call
+type tracingVPCClient struct {
+	tracer
+	client VPCClient
+}
+
+// NewTracingVPCClient returns a VPCClient which records a span for each call of the given client as child of the
+// span in the given context. The spans carry the VPC action, the region, the ID of the vSwitch the call concerns and
+// the ID of the request.
+func NewTracingVPCClient(ctx context.Context, client VPCClient, region string) VPCClient {
+	return &tracingVPCClient{
+		tracer: tracer{ctx: ctx, region: region, api: "VPC", actionKey: tracing.VPCActionKey},
+		client: client,
+	}
+}
+
+func (c *tracingVPCClient) DescribeVSwitches(request *vpc.DescribeVSwitchesRequest) (*vpc.DescribeVSwitchesResponse, error) {
+	span := c.start("DescribeVSwitches", request.RegionId, appendIfSet(nil, tracing.VSwitchIDKey, request.VSwitchId)...)
+	return traced(span, request, c.client.DescribeVSwitches)
+}
+
+// start starts the span of a call, the region of the request takes precedence over the one of the client
+func (t *tracer) start(action string, regionID *string, attributes ...attribute.KeyValue) trace.Span {
+	region := t.region
+	if tea.StringValue(regionID) != "" {
+		region = *regionID
+	}
+	attributes = append(attributes, t.actionKey.String(action), tracing.RegionKey.String(region))
+	_, span := tracing.Start(t.ctx, t.api+" "+action, attributes...)
+	return span
+}
+
+// responseBody is the body of an ECS or VPC response
+type responseBody interface {
+	comparable
+	GetRequestId() *string
+}
+
+// response is an ECS or VPC response
+type response[Body responseBody] interface {
+	comparable
+	GetBody() Body
+}
+
+// traced calls the given function and ends the span of the call with the request ID of the response
+func traced[Request any, Body responseBody, Response response[Body]](span trace.Span, request Request, call func(Request) (Response, error)) (Response, error) {
+	var (
+		requestID *string
+		noResp    Response
+		noBody    Body
+	)
+	resp, err := call(request)
+	if resp != noResp {
+		if body := resp.GetBody(); body != noBody {
+			requestID = body.GetRequestId()
+		}
+	}
+	end(span, requestID, err)
+	return resp, err
+}
+
+// end ends the span of a call, the request ID is taken from the error if the call failed
+func end(span trace.Span, requestID *string, err error) {
+	if err != nil {
+		requestID = tea.String(maperror.GetRequestID(err))
+	}
+	if tea.StringValue(requestID) != "" {
+		span.SetAttributes(tracing.RequestIDKey.String(*requestID))
+	}
+	tracing.EndSpan(span, err)
+}
+
+// instanceIDAttributes returns the attribute of the instance ID if a call concerns a single instance, otherwise the
+// one of all instance IDs
+func instanceIDAttributes(instanceIDs []string) []attribute.KeyValue {
+	switch len(instanceIDs) {
+	case 0:
+		return nil
+	case 1:
+		return []attribute.KeyValue{tracing.InstanceIDKey.String(instanceIDs[0])}
+	default:
+		return []attribute.KeyValue{tracing.InstanceIDsKey.StringSlice(instanceIDs)}
+	}
+}
+
+// appendIfSet appends the attribute with the given key and value unless the value is empty
+func appendIfSet(attributes []attribute.KeyValue, key attribute.Key, value *string) []attribute.KeyValue {
+	if tea.StringValue(value) == "" {
+		return attributes
+	}
+	return append(attributes, key.String(*value))
+}
+
+// decodeIDs decodes the JSON array of IDs of a describe request, malformed arrays are ignored
+func decodeIDs(ids *string) []string {
+	var decoded []string
+	if tea.StringValue(ids) == "" || json.Unmarshal([]byte(*ids), &decoded) != nil {
+		return nil
+	}
+	return decoded
+}
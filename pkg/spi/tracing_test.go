@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spi
+
+import (
+	"context"
+
+	ecs "github.com/alibabacloud-go/ecs-20140526/v7/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/alicloud/tracing"
+	mockclient "github.com/gardener/machine-controller-manager-provider-alicloud/pkg/mock/client"
+	"github.com/gardener/machine-controller-manager-provider-alicloud/pkg/vpc"
+)
+
+var _ = Describe("Tracing ECS client", func() {
+	var (
+		ctrl           *gomock.Controller
+		mockECSClient  *mockclient.MockECSClient
+		recorder       *tracetest.SpanRecorder
+		tracerProvider trace.TracerProvider
+		client         ECSClient
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockECSClient = mockclient.NewMockECSClient(ctrl)
+
+		recorder = tracetest.NewSpanRecorder()
+		tracerProvider = otel.GetTracerProvider()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+		client = NewTracingECSClient(context.Background(), mockECSClient, "cn-shanghai")
+	})
+
+	AfterEach(func() {
+		otel.SetTracerProvider(tracerProvider)
+		ctrl.Finish()
+	})
+
+	It("should record the action, region, instance ID and request ID", func() {
+		request := &ecs.DeleteInstanceRequest{InstanceId: tea.String("i-123")}
+		mockECSClient.EXPECT().DeleteInstance(request).Return(&ecs.DeleteInstanceResponse{
+			Body: &ecs.DeleteInstanceResponseBody{RequestId: tea.String("request-id")},
+		}, nil)
+
+		_, err := client.DeleteInstance(request)
+		Expect(err).NotTo(HaveOccurred())
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name()).To(Equal("ECS DeleteInstance"))
+		Expect(spans[0].Attributes()).To(ContainElements(
+			tracing.ECSActionKey.String("DeleteInstance"),
+			tracing.RegionKey.String("cn-shanghai"),
+			tracing.InstanceIDKey.String("i-123"),
+			tracing.RequestIDKey.String("request-id"),
+		))
+		Expect(spans[0].Status().Code).To(Equal(otelcodes.Unset))
+	})
+
+	It("should record the request ID of a failed call and mark the span as failed", func() {
+		request := &ecs.DescribeInstancesRequest{}
+		mockECSClient.EXPECT().DescribeInstances(request).Return(nil, &tea.SDKError{
+			Code:    tea.String("Throttling"),
+			Message: tea.String("Request was denied due to request throttling."),
+			Data:    tea.String(`{"RequestId":"failed-request-id"}`),
+		})
+
+		_, err := client.DescribeInstances(request)
+		Expect(err).To(HaveOccurred())
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name()).To(Equal("ECS DescribeInstances"))
+		Expect(spans[0].Attributes()).To(ContainElement(tracing.RequestIDKey.String("failed-request-id")))
+		Expect(spans[0].Status().Code).To(Equal(otelcodes.Error))
+	})
+
+	It("should record the instances, disks and network interfaces a call concerns and the region of the request", func() {
+		describeInstancesRequest := &ecs.DescribeInstancesRequest{RegionId: tea.String("cn-beijing"), InstanceIds: tea.String(`["i-123","i-456"]`), InstanceName: tea.String("machine-0")}
+		mockECSClient.EXPECT().DescribeInstances(describeInstancesRequest).Return(&ecs.DescribeInstancesResponse{
+			Body: &ecs.DescribeInstancesResponseBody{RequestId: tea.String("request-id")},
+		}, nil)
+		describeDisksRequest := &ecs.DescribeDisksRequest{RegionId: tea.String("cn-shanghai"), InstanceId: tea.String("i-123"), DiskIds: tea.String(`["d-123"]`)}
+		mockECSClient.EXPECT().DescribeDisks(describeDisksRequest).Return(nil, &tea.SDKError{
+			Code: tea.String("Throttling"),
+			Data: tea.String(`{"RequestId":"failed-request-id"}`),
+		})
+		describeNetworkInterfacesRequest := &ecs.DescribeNetworkInterfacesRequest{RegionId: tea.String("cn-shanghai"), NetworkInterfaceName: tea.String("machine-0-storage-eni")}
+		mockECSClient.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfacesRequest).Return(&ecs.DescribeNetworkInterfacesResponse{}, nil)
+
+		_, err := client.DescribeInstances(describeInstancesRequest)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.DescribeDisks(describeDisksRequest)
+		Expect(err).To(HaveOccurred())
+		_, err = client.DescribeNetworkInterfaces(describeNetworkInterfacesRequest)
+		Expect(err).NotTo(HaveOccurred())
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(3))
+		Expect(spans[0].Attributes()).To(ContainElements(
+			tracing.RegionKey.String("cn-beijing"),
+			tracing.InstanceIDsKey.StringSlice([]string{"i-123", "i-456"}),
+			tracing.InstanceNameKey.String("machine-0"),
+			tracing.RequestIDKey.String("request-id"),
+		))
+		Expect(spans[1].Attributes()).To(ContainElements(
+			tracing.InstanceIDKey.String("i-123"),
+			tracing.DiskIDKey.String("d-123"),
+			tracing.RequestIDKey.String("failed-request-id"),
+		))
+		Expect(spans[2].Attributes()).To(ContainElement(tracing.NetworkInterfaceNameKey.String("machine-0-storage-eni")))
+	})
+
+	It("should record the VPC calls with the VPC action and the vSwitch ID", func() {
+		mockVPCClient := mockclient.NewMockVPCClient(ctrl)
+		vpcClient := NewTracingVPCClient(context.Background(), mockVPCClient, "cn-shanghai")
+		request := &vpc.DescribeVSwitchesRequest{VSwitchId: tea.String("vsw-1")}
+		mockVPCClient.EXPECT().DescribeVSwitches(request).Return(&vpc.DescribeVSwitchesResponse{
+			Body: &vpc.DescribeVSwitchesResponseBody{RequestId: tea.String("request-id")},
+		}, nil)
+
+		_, err := vpcClient.DescribeVSwitches(request)
+		Expect(err).NotTo(HaveOccurred())
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name()).To(Equal("VPC DescribeVSwitches"))
+		Expect(spans[0].Attributes()).To(ContainElements(
+			tracing.VPCActionKey.String("DescribeVSwitches"),
+			tracing.RegionKey.String("cn-shanghai"),
+			tracing.VSwitchIDKey.String("vsw-1"),
+			tracing.RequestIDKey.String("request-id"),
+		))
+		Expect(spans[0].Attributes()).NotTo(ContainElement(HaveField("Key", tracing.ECSActionKey)))
+	})
+})
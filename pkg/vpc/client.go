@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package vpc contains a client of the VPC API for the actions the driver calls, i.e. DescribeVSwitches. The official
+// VPC SDK github.com/alibabacloud-go/vpc-20160428 is not a dependency of the driver yet, hence the client is built on
+// the generic OpenAPI client the ECS SDK is built on. Its methods and models follow the ones of the SDK, so that it can
+// be replaced by the SDK by changing the import.
+package vpc
+
+import (
+	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
+	openapiutil "github.com/alibabacloud-go/darabonba-openapi/v2/utils"
+	"github.com/alibabacloud-go/tea/dara"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+// apiVersion is the version of the VPC API, whose DescribeVSwitches reports the IPv6 CIDR blocks of vSwitches unlike
+// the deprecated DescribeVSwitches of the ECS API
+const apiVersion = "2016-04-28"
+
+// DescribeVSwitchesRequest is the request of the DescribeVSwitches action
+type DescribeVSwitchesRequest struct {
+	RegionId  *string `json:"RegionId,omitempty" xml:"RegionId,omitempty"`
+	VSwitchId *string `json:"VSwitchId,omitempty" xml:"VSwitchId,omitempty"`
+}
+
+// DescribeVSwitchesResponse is the response of the DescribeVSwitches action
+type DescribeVSwitchesResponse struct {
+	Headers    map[string]*string             `json:"headers,omitempty" xml:"headers,omitempty"`
+	StatusCode *int32                         `json:"statusCode,omitempty" xml:"statusCode,omitempty"`
+	Body       *DescribeVSwitchesResponseBody `json:"body,omitempty" xml:"body,omitempty"`
+}
+
+// GetBody returns the body of the response
+func (s *DescribeVSwitchesResponse) GetBody() *DescribeVSwitchesResponseBody {
+	return s.Body
+}
+
+// DescribeVSwitchesResponseBody is the body of the response of the DescribeVSwitches action
+type DescribeVSwitchesResponseBody struct {
+	RequestId  *string                                 `json:"RequestId,omitempty" xml:"RequestId,omitempty"`
+	TotalCount *int32                                  `json:"TotalCount,omitempty" xml:"TotalCount,omitempty"`
+	VSwitches  *DescribeVSwitchesResponseBodyVSwitches `json:"VSwitches,omitempty" xml:"VSwitches,omitempty" type:"Struct"`
+}
+
+// GetRequestId returns the ID of the request
+func (s *DescribeVSwitchesResponseBody) GetRequestId() *string {
+	return s.RequestId
+}
+
+// DescribeVSwitchesResponseBodyVSwitches are the vSwitches of the response of the DescribeVSwitches action
+type DescribeVSwitchesResponseBodyVSwitches struct {
+	VSwitch []*DescribeVSwitchesResponseBodyVSwitchesVSwitch `json:"VSwitch,omitempty" xml:"VSwitch,omitempty" type:"Repeated"`
+}
+
+// DescribeVSwitchesResponseBodyVSwitchesVSwitch is a vSwitch of the response of the DescribeVSwitches action
+type DescribeVSwitchesResponseBodyVSwitchesVSwitch struct {
+	VSwitchId     *string `json:"VSwitchId,omitempty" xml:"VSwitchId,omitempty"`
+	VpcId         *string `json:"VpcId,omitempty" xml:"VpcId,omitempty"`
+	ZoneId        *string `json:"ZoneId,omitempty" xml:"ZoneId,omitempty"`
+	Status        *string `json:"Status,omitempty" xml:"Status,omitempty"`
+	CidrBlock     *string `json:"CidrBlock,omitempty" xml:"CidrBlock,omitempty"`
+	Ipv6CidrBlock *string `json:"Ipv6CidrBlock,omitempty" xml:"Ipv6CidrBlock,omitempty"`
+}
+
+// Client is a client of the VPC API
+type Client struct {
+	openapi.Client
+}
+
+// NewClient returns a new client of the VPC API. The generic OpenAPI client does not resolve endpoints, hence the
+// config has to set the endpoint.
+func NewClient(config *openapi.Config) (*Client, error) {
+	client := new(Client)
+	err := client.Init(config)
+	return client, err
+}
+
+// DescribeVSwitchesWithOptions describes the vSwitches matching the request with the given runtime options
+func (client *Client) DescribeVSwitchesWithOptions(request *DescribeVSwitchesRequest, runtime *dara.RuntimeOptions) (*DescribeVSwitchesResponse, error) {
+	query := map[string]any{}
+	if request.RegionId != nil {
+		query["RegionId"] = request.RegionId
+	}
+	if request.VSwitchId != nil {
+		query["VSwitchId"] = request.VSwitchId
+	}
+	params := &openapiutil.Params{
+		Action:      tea.String("DescribeVSwitches"),
+		Version:     tea.String(apiVersion),
+		Protocol:    tea.String(client.protocol()),
+		Pathname:    tea.String("/"),
+		Method:      tea.String("POST"),
+		AuthType:    tea.String("AK"),
+		Style:       tea.String("RPC"),
+		ReqBodyType: tea.String("formData"),
+		BodyType:    tea.String("json"),
+	}
+
+	body, err := client.CallApi(params, &openapiutil.OpenApiRequest{Query: openapiutil.Query(query)}, runtime)
+	if err != nil {
+		return nil, err
+	}
+	response := &DescribeVSwitchesResponse{}
+	if err := dara.Convert(body, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// DescribeVSwitches describes the vSwitches matching the request with the timeouts and proxies of the client config
+func (client *Client) DescribeVSwitches(request *DescribeVSwitchesRequest) (*DescribeVSwitchesResponse, error) {
+	return client.DescribeVSwitchesWithOptions(request, client.runtimeOptions())
+}
+
+// protocol returns the protocol of the client config, HTTPS if there is none
+func (client *Client) protocol() string {
+	if protocol := tea.StringValue(client.Protocol); protocol != "" {
+		return protocol
+	}
+	return "HTTPS"
+}
+
+// runtimeOptions returns the runtime options with the timeouts and proxies of the client config
+func (client *Client) runtimeOptions() *dara.RuntimeOptions {
+	return &dara.RuntimeOptions{
+		ReadTimeout:    client.ReadTimeout,
+		ConnectTimeout: client.ConnectTimeout,
+		HttpProxy:      client.HttpProxy,
+		HttpsProxy:     client.HttpsProxy,
+		NoProxy:        client.NoProxy,
+	}
+}